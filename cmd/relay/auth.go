@@ -0,0 +1,167 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/config"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+)
+
+// tokenCommandTimeout bounds how long a credential helper command may run.
+const tokenCommandTimeout = 30 * time.Second
+
+// newCredentials determines how API requests are authenticated. A token
+// passed with --token takes precedence, then the token pool configured under
+// github.tokens, then the single token source chosen by newTokenSource.
+// owner is the owner of the fetched repository; the App installation on it
+// is used when no installation ID is configured.
+func newCredentials(ctx context.Context, flagToken string, cfg *config.Config, owner string, requestTimeout time.Duration) ([]github.PooledToken, error) {
+	if flagToken != "" || len(cfg.GitHub.Tokens) == 0 {
+		source, err := newTokenSource(ctx, flagToken, cfg, owner, requestTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return []github.PooledToken{{Source: source}}, nil
+	}
+
+	pool := make([]github.PooledToken, 0, len(cfg.GitHub.Tokens))
+	for i, entry := range cfg.GitHub.Tokens {
+		name := entry.Name
+		if name == "" {
+			name = fmt.Sprintf("token-%d", i+1)
+		}
+
+		var source github.TokenSource
+		if entry.App.Enabled() {
+			var err error
+			if source, err = newAppTokenSource(entry.App, cfg, owner, requestTimeout); err != nil {
+				return nil, fmt.Errorf("token pool entry %s: %w", name, err)
+			}
+		} else {
+			token := os.Getenv(entry.Env)
+			if token == "" {
+				return nil, fmt.Errorf("token pool entry %s: environment variable %s is not set", name, entry.Env)
+			}
+			source = github.StaticToken(token)
+		}
+		pool = append(pool, github.PooledToken{Name: name, Source: source})
+	}
+	return pool, nil
+}
+
+// newTokenSource returns the single token source used without a token pool:
+// the --token flag, then a GitHub App configured under github.app, then the
+// credential helper in github.token_command or the file in github.token_file,
+// then the token in the environment variable named by github.token_env.
+// Helper and file tokens are resolved right away so a broken helper fails
+// the fetch before it starts, and again whenever GitHub rejects the token.
+func newTokenSource(ctx context.Context, flagToken string, cfg *config.Config, owner string, requestTimeout time.Duration) (github.TokenSource, error) {
+	if flagToken == "" {
+		var resolve func(ctx context.Context) (string, error)
+		switch {
+		case cfg.GitHub.App.Enabled():
+			return newAppTokenSource(cfg.GitHub.App, cfg, owner, requestTimeout)
+		case cfg.GitHub.TokenCommand != "":
+			resolve = commandToken(cfg.GitHub.TokenCommand)
+		case cfg.GitHub.TokenFile != "":
+			resolve = fileToken(cfg.GitHub.TokenFile)
+		}
+		if resolve != nil {
+			source := github.NewResolvedToken(resolve)
+			if _, err := source.Token(ctx); err != nil {
+				return nil, err
+			}
+			return source, nil
+		}
+	}
+
+	token := getToken(flagToken, cfg.GitHub.TokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("GitHub token not found. Set %s, configure token_command, token_file or a GitHub App, or use --token flag", cfg.GitHub.TokenEnv)
+	}
+	return github.StaticToken(token), nil
+}
+
+// commandToken returns a resolver that runs a credential helper command,
+// such as "gh auth token", through the shell and uses its output as the
+// token.
+func commandToken(command string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, tokenCommandTimeout)
+		defer cancel()
+
+		shell, flag := "sh", "-c"
+		if runtime.GOOS == "windows" {
+			shell, flag = "cmd", "/C"
+		}
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, shell, flag, command) // #nosec G204 - the command comes from the user's own configuration
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("token command %q failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+		}
+
+		token := strings.TrimSpace(stdout.String())
+		if token == "" {
+			return "", fmt.Errorf("token command %q printed no token", command)
+		}
+		return token, nil
+	}
+}
+
+// fileToken returns a resolver that reads the token from a file, ignoring
+// surrounding whitespace such as a trailing newline.
+func fileToken(path string) func(ctx context.Context) (string, error) {
+	return func(context.Context) (string, error) {
+		data, err := os.ReadFile(path) // #nosec G304 - the path comes from the user's own configuration
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("token file %s is empty", path)
+		}
+		return token, nil
+	}
+}
+
+// newAppTokenSource creates the token source for a GitHub App installation,
+// reading the App's private key from disk.
+func newAppTokenSource(app config.GitHubAppConfig, cfg *config.Config, owner string, requestTimeout time.Duration) (github.TokenSource, error) {
+	key, err := os.ReadFile(app.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	return github.NewAppTokenSource(github.AppConfig{
+		AppID:          app.AppID,
+		PrivateKey:     key,
+		InstallationID: app.InstallationID,
+		Owner:          owner,
+		APIEndpoint:    cfg.GitHub.APIEndpoint,
+		HTTPClient:     &http.Client{Timeout: requestTimeout},
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirseerhq/sirseer-relay/internal/config"
+)
+
+func TestNewTokenSource(t *testing.T) {
+	t.Setenv("TEST_RELAY_TOKEN", "env-token")
+	missingKey := filepath.Join(t.TempDir(), "missing.pem")
+	tokenFile := filepath.Join(t.TempDir(), "github-token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		flagToken string
+		app       config.GitHubAppConfig
+		command   string
+		file      string
+		tokenEnv  string
+		wantToken string
+		wantErr   string
+	}{
+		{
+			name:      "flag token wins over app",
+			flagToken: "flag-token",
+			app:       config.GitHubAppConfig{AppID: 1, PrivateKeyPath: missingKey},
+			tokenEnv:  "TEST_RELAY_TOKEN",
+			wantToken: "flag-token",
+		},
+		{
+			name:     "app wins over environment",
+			app:      config.GitHubAppConfig{AppID: 1, PrivateKeyPath: missingKey},
+			tokenEnv: "TEST_RELAY_TOKEN",
+			wantErr:  "failed to read GitHub App private key",
+		},
+		{
+			name:      "token command wins over environment",
+			command:   "echo command-token",
+			tokenEnv:  "TEST_RELAY_TOKEN",
+			wantToken: "command-token",
+		},
+		{
+			name:     "failing token command",
+			command:  "exit 3",
+			tokenEnv: "TEST_RELAY_TOKEN",
+			wantErr:  "token command \"exit 3\" failed",
+		},
+		{
+			name:      "token file",
+			file:      tokenFile,
+			tokenEnv:  "TEST_RELAY_TOKEN",
+			wantToken: "file-token",
+		},
+		{
+			name:     "missing token file",
+			file:     filepath.Join(t.TempDir(), "missing"),
+			tokenEnv: "TEST_RELAY_TOKEN",
+			wantErr:  "failed to read token file",
+		},
+		{
+			name:      "environment token",
+			tokenEnv:  "TEST_RELAY_TOKEN",
+			wantToken: "env-token",
+		},
+		{
+			name:     "no credentials",
+			tokenEnv: "TEST_RELAY_UNSET_TOKEN",
+			wantErr:  "GitHub token not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.GitHub.App = tt.app
+			cfg.GitHub.TokenCommand = tt.command
+			cfg.GitHub.TokenFile = tt.file
+			cfg.GitHub.TokenEnv = tt.tokenEnv
+
+			source, err := newTokenSource(context.Background(), tt.flagToken, cfg, "owner", 0)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newTokenSource() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newTokenSource() failed: %v", err)
+			}
+			if token, _ := source.Token(context.Background()); token != tt.wantToken {
+				t.Errorf("token = %q, want %q", token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestNewCredentials_TokenPool(t *testing.T) {
+	t.Setenv("TEST_RELAY_TOKEN_A", "token-a")
+	t.Setenv("TEST_RELAY_TOKEN_B", "token-b")
+
+	cfg := config.DefaultConfig()
+	cfg.GitHub.Tokens = []config.TokenConfig{
+		{Name: "backfill-bot", Env: "TEST_RELAY_TOKEN_A"},
+		{Env: "TEST_RELAY_TOKEN_B"},
+	}
+
+	creds, err := newCredentials(context.Background(), "", cfg, "owner", 0)
+	if err != nil {
+		t.Fatalf("newCredentials() failed: %v", err)
+	}
+	if len(creds) != 2 || creds[0].Name != "backfill-bot" || creds[1].Name != "token-2" {
+		t.Fatalf("unexpected pool: %+v", creds)
+	}
+	if token, _ := creds[1].Source.Token(context.Background()); token != "token-b" {
+		t.Errorf("second token = %q, want token-b", token)
+	}
+
+	// An explicit --token replaces the pool
+	creds, err = newCredentials(context.Background(), "flag-token", cfg, "owner", 0)
+	if err != nil || len(creds) != 1 {
+		t.Fatalf("expected a single credential for --token, got %+v, %v", creds, err)
+	}
+
+	cfg.GitHub.Tokens = append(cfg.GitHub.Tokens, config.TokenConfig{Env: "TEST_RELAY_UNSET_TOKEN"})
+	if _, err := newCredentials(context.Background(), "", cfg, "owner", 0); err == nil || !strings.Contains(err.Error(), "TEST_RELAY_UNSET_TOKEN is not set") {
+		t.Errorf("expected an error for the unset pool token, got %v", err)
+	}
+}
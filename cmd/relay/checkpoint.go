@@ -0,0 +1,251 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/config"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/output"
+	"github.com/sirseerhq/sirseer-relay/internal/state"
+)
+
+// flusher is implemented by output writers that buffer records.
+type flusher interface {
+	Flush() error
+}
+
+// checkpointer persists the position of a full fetch after every page so an
+// interrupted run can be continued with --resume. The checkpoint is stored in
+// the repository's state alongside the state of the last completed fetch,
+// which is left untouched until the new fetch finishes.
+type checkpointer struct {
+	store      state.StateStore
+	base       state.FetchState
+	checkpoint state.Checkpoint
+	writer     output.OutputWriter
+}
+
+// newCheckpointer prepares checkpointing for a full fetch of repoPath into
+// its state in store. When resumeFrom is nil a new checkpoint is started;
+// otherwise the given checkpoint is continued. The output file is recorded
+// as an absolute path so --resume finds it from any working directory.
+func newCheckpointer(store state.StateStore, repoPath, outputFile string, writer output.OutputWriter, opts github.FetchOptions, resumeFrom *state.Checkpoint) *checkpointer {
+	// Keep the last completed fetch so incremental runs still work while a
+	// full fetch is in progress
+	base := state.FetchState{Repository: repoPath}
+	if prev, err := store.LoadState(repoPath); err == nil && prev.Repository == repoPath {
+		base = *prev
+		if prev.Checkpoint != nil && resumeFrom == nil {
+			fmt.Fprintf(os.Stderr, "Discarding checkpoint of an interrupted fetch started %s (use --resume to continue it)\n",
+				prev.Checkpoint.StartedAt.Format(time.RFC3339))
+		}
+	}
+	base.Checkpoint = nil
+
+	c := &checkpointer{
+		store:  store,
+		base:   base,
+		writer: writer,
+	}
+
+	if resumeFrom != nil {
+		c.checkpoint = *resumeFrom
+	} else {
+		if outputFile != "" {
+			if abs, err := filepath.Abs(outputFile); err == nil {
+				outputFile = abs
+			}
+		}
+		c.checkpoint = state.Checkpoint{
+			FetchID:    fmt.Sprintf("full-%d", time.Now().Unix()),
+			Since:      opts.Since,
+			Until:      opts.Until,
+			OutputFile: outputFile,
+			StartedAt:  time.Now().UTC(),
+			Filters:    filtersOf(opts),
+		}
+	}
+
+	return c
+}
+
+// save flushes the output and records the progress made so far. It must only
+// be called once every PR up to progress.cursor has been written.
+func (c *checkpointer) save(progress *progressTracker) error {
+	if f, ok := c.writer.(flusher); ok {
+		if err := f.Flush(); err != nil {
+			return fmt.Errorf("failed to flush output: %w", err)
+		}
+	}
+
+	if c.checkpoint.OutputFile != "" {
+		info, err := os.Stat(c.checkpoint.OutputFile)
+		if err != nil {
+			return fmt.Errorf("failed to stat output file: %w", err)
+		}
+		c.checkpoint.OutputOffset = info.Size()
+	}
+
+	c.checkpoint.Cursor = progress.cursor
+	c.checkpoint.PageSize = progress.pageSize
+	c.checkpoint.PageNum = progress.pageNum
+	c.checkpoint.PRsWritten = progress.allPRsProcessed
+	c.checkpoint.LastPRNumber = progress.lastPRNumber
+	c.checkpoint.LastPRDate = progress.lastPRDate
+	c.checkpoint.ExpectedPRs = progress.expectedPRs
+	c.checkpoint.SliceStart, c.checkpoint.SliceEnd, c.checkpoint.SlicingEnd = nil, nil, nil
+	if progress.slice != nil {
+		sliceStart, sliceEnd, slicingEnd := progress.slice.Start, progress.slice.End, progress.slicer.end
+		c.checkpoint.SliceStart = &sliceStart
+		c.checkpoint.SliceEnd = &sliceEnd
+		c.checkpoint.SlicingEnd = &slicingEnd
+	}
+	c.checkpoint.UpdatedAt = time.Now().UTC()
+
+	fetchState := c.base
+	checkpoint := c.checkpoint
+	fetchState.Checkpoint = &checkpoint
+
+	if err := c.store.SaveState(&fetchState); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// clear removes the checkpoint, restoring the state of the last completed
+// fetch. It is used when a full fetch finishes without writing any PRs,
+// including a repository without any, so there is no new completed state to
+// replace the checkpoint with.
+func (c *checkpointer) clear() error {
+	if c.base.LastFetchTime.IsZero() {
+		return c.store.DeleteState(c.base.Repository)
+	}
+	fetchState := c.base
+	return c.store.SaveState(&fetchState)
+}
+
+// apply restores the progress recorded in the checkpoint into progress.
+// A run that was split into slices continues with the slice it was in;
+// slicer supplies the client and filters for the remaining slices.
+func (c *checkpointer) apply(progress *progressTracker, slicer *windowSlicer) {
+	if c.checkpoint.PageSize > 0 {
+		progress.pageSize = c.checkpoint.PageSize
+	}
+	progress.cursor = c.checkpoint.Cursor
+	progress.pageNum = c.checkpoint.PageNum
+	progress.allPRsProcessed = c.checkpoint.PRsWritten
+	progress.lastPRNumber = c.checkpoint.LastPRNumber
+	progress.lastPRDate = c.checkpoint.LastPRDate
+	progress.expectedPRs = c.checkpoint.ExpectedPRs
+
+	cp := c.checkpoint
+	if cp.SliceStart != nil && cp.SliceEnd != nil && cp.SlicingEnd != nil {
+		progress.slice = &github.TimeRange{Start: *cp.SliceStart, End: *cp.SliceEnd}
+		slicer.next = *cp.SliceEnd
+		slicer.end = *cp.SlicingEnd
+		slicer.span = cp.SliceEnd.Sub(*cp.SliceStart)
+		progress.slicer = slicer
+	}
+}
+
+// openStateStore opens the state store selected in cfg in stateDir.
+func openStateStore(cfg *config.Config, stateDir string) (state.StateStore, error) {
+	store, err := state.OpenStore(cfg.Defaults.StateStore, stateDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state store: %w", err)
+	}
+	return store, nil
+}
+
+// lockState takes the lock on the state of repoPath in store for the rest of
+// a run. When another run holds it, lockState waits up to wait for that run
+// to finish and tells the user what it is waiting for.
+func lockState(ctx context.Context, store state.StateStore, repoPath string, wait time.Duration) (*state.Lock, error) {
+	lock, err := store.Lock(ctx, repoPath, 0)
+	if err == nil {
+		return lock, nil
+	}
+	var locked *state.LockedError
+	if !errors.As(err, &locked) || wait <= 0 {
+		return nil, fmt.Errorf("cannot use the state of %s: %w", repoPath, err)
+	}
+
+	if locked.PID > 0 {
+		fmt.Fprintf(os.Stderr, "Waiting up to %s for another fetch of %s (pid %d) to finish...\n", wait, repoPath, locked.PID)
+	} else {
+		fmt.Fprintf(os.Stderr, "Waiting up to %s for another fetch of %s to finish...\n", wait, repoPath)
+	}
+	lock, err = store.Lock(ctx, repoPath, wait)
+	if err != nil {
+		return nil, fmt.Errorf("cannot use the state of %s: %w", repoPath, err)
+	}
+	return lock, nil
+}
+
+// loadCheckpoint returns the checkpoint of the interrupted full fetch of
+// repoPath from its state in store, or an error explaining why there is
+// nothing to resume.
+func loadCheckpoint(store state.StateStore, repoPath string) (*state.Checkpoint, error) {
+	fetchState, err := store.LoadState(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resume fetch of %s: %w", repoPath, err)
+	}
+	if fetchState.Repository != repoPath {
+		return nil, fmt.Errorf("state file is for repository %s but current command is for %s", fetchState.Repository, repoPath)
+	}
+	if fetchState.Checkpoint == nil {
+		return nil, fmt.Errorf("no interrupted fetch found for %s. The last full fetch completed; use --incremental to fetch new PRs", repoPath)
+	}
+	return fetchState.Checkpoint, nil
+}
+
+// openResumeWriter reopens the output file of an interrupted fetch for
+// appending. Records written after the checkpoint are discarded first, so
+// the PRs of the partially written page are not duplicated when it is
+// fetched again.
+func openResumeWriter(checkpoint *state.Checkpoint) (output.OutputWriter, error) {
+	if checkpoint.OutputFile == "" {
+		return output.NewWriter(os.Stdout), nil
+	}
+
+	info, err := os.Stat(checkpoint.OutputFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("cannot resume: output file %s of the interrupted fetch no longer exists", checkpoint.OutputFile)
+		}
+		return nil, fmt.Errorf("failed to stat output file: %w", err)
+	}
+	if info.Size() < checkpoint.OutputOffset {
+		return nil, fmt.Errorf("cannot resume: output file %s is smaller than at the last checkpoint (%d < %d bytes) and may have been modified",
+			checkpoint.OutputFile, info.Size(), checkpoint.OutputOffset)
+	}
+
+	if err := os.Truncate(checkpoint.OutputFile, checkpoint.OutputOffset); err != nil {
+		return nil, fmt.Errorf("failed to discard records written after the checkpoint: %w", err)
+	}
+
+	writer, err := output.NewAppendFileWriter(checkpoint.OutputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	return writer, nil
+}
@@ -0,0 +1,395 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+	"github.com/sirseerhq/sirseer-relay/internal/output"
+	"github.com/sirseerhq/sirseer-relay/internal/state"
+)
+
+// testPullRequests returns n pull requests numbered 1 through n.
+func testPullRequests(n int) []github.PullRequest {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	prs := make([]github.PullRequest, n)
+	for i := range prs {
+		created := base.Add(time.Duration(i) * time.Hour)
+		prs[i] = github.PullRequest{Number: i + 1, Title: "PR", CreatedAt: created, UpdatedAt: created}
+	}
+	return prs
+}
+
+// readPRNumbers returns the PR numbers recorded in an NDJSON output file.
+func readPRNumbers(t *testing.T, path string) []int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	var numbers []int
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var pr github.PullRequest
+		if err := json.Unmarshal([]byte(line), &pr); err != nil {
+			t.Fatalf("invalid output line %q: %v", line, err)
+		}
+		numbers = append(numbers, pr.Number)
+	}
+	return numbers
+}
+
+func TestFetchAll_ResumeAfterFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "prs.ndjson")
+	metadataFile := filepath.Join(tmpDir, "metadata.json")
+	repoPath := "test/repo"
+	opts := github.FetchOptions{PageSize: 5}
+	prs := testPullRequests(12)
+
+	// First run fails while fetching the second page
+	writer, err := output.NewFileWriter(outputFile)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	failing := github.NewMockClientWithOptions(
+		github.WithPullRequests(prs),
+		github.WithPagination(5),
+		github.WithNetworkErrorOnCall(2),
+	)
+	run := newCheckpointer(state.NewFileStore(""), repoPath, outputFile, writer, opts, nil)
+	if err := fetchAllPullRequestsWithOptions(context.Background(), failing, "test", "repo", writer, metadataFile, opts, run); err == nil {
+		t.Fatal("expected the first run to fail")
+	}
+	writer.Close()
+
+	checkpoint, err := loadCheckpoint(state.NewFileStore(""), repoPath)
+	if err != nil {
+		t.Fatalf("expected a checkpoint after failure: %v", err)
+	}
+	if checkpoint.Cursor != "cursor_5" || checkpoint.PRsWritten != 5 || checkpoint.PageSize != 5 {
+		t.Errorf("unexpected checkpoint: %+v", checkpoint)
+	}
+
+	// Simulate a partial record flushed after the checkpoint was written
+	f, err := os.OpenFile(outputFile, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("failed to open output: %v", err)
+	}
+	_, _ = f.WriteString(`{"number":6,"tit`)
+	f.Close()
+
+	// Resumed run continues from the checkpoint and appends
+	resumeWriter, err := openResumeWriter(checkpoint)
+	if err != nil {
+		t.Fatalf("openResumeWriter failed: %v", err)
+	}
+	client := github.NewMockClientWithOptions(github.WithPullRequests(prs), github.WithPagination(5))
+	resumeOpts := github.FetchOptions{Since: checkpoint.Since, Until: checkpoint.Until, PageSize: checkpoint.PageSize}
+	run = newCheckpointer(state.NewFileStore(""), repoPath, outputFile, resumeWriter, resumeOpts, checkpoint)
+	if err := fetchAllPullRequestsWithOptions(context.Background(), client, "test", "repo", resumeWriter, metadataFile, resumeOpts, run); err != nil {
+		t.Fatalf("resumed run failed: %v", err)
+	}
+	resumeWriter.Close()
+
+	if client.LastOpts.After != "cursor_10" || client.CallCount != 2 {
+		t.Errorf("expected resume to fetch 2 pages starting at cursor_5, got %d calls ending at %q", client.CallCount, client.LastOpts.After)
+	}
+
+	numbers := readPRNumbers(t, outputFile)
+	if len(numbers) != len(prs) {
+		t.Fatalf("expected %d records, got %d: %v", len(prs), len(numbers), numbers)
+	}
+	for i, n := range numbers {
+		if n != i+1 {
+			t.Fatalf("expected PRs 1-%d in order without duplicates, got %v", len(prs), numbers)
+		}
+	}
+
+	// The completed state replaces the checkpoint
+	fetchState, err := state.LoadState(state.GetStateFilePath(repoPath))
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if fetchState.Checkpoint != nil {
+		t.Error("expected checkpoint to be cleared after completion")
+	}
+	if fetchState.LastPRNumber != 12 || fetchState.TotalFetched != 12 || fetchState.LastFetchID != checkpoint.FetchID {
+		t.Errorf("unexpected final state: %+v", fetchState)
+	}
+}
+
+func TestLoadCheckpoint_StateDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	stateDir := t.TempDir()
+
+	interrupted := &state.FetchState{Repository: "test/repo", Checkpoint: &state.Checkpoint{Cursor: "cursor_5", PageNum: 1}}
+	if err := state.SaveState(interrupted, state.StateFilePath(stateDir, "test/repo")); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	checkpoint, err := loadCheckpoint(state.NewFileStore(stateDir), "test/repo")
+	if err != nil {
+		t.Fatalf("expected the checkpoint from the state directory: %v", err)
+	}
+	if checkpoint.Cursor != "cursor_5" {
+		t.Errorf("unexpected checkpoint: %+v", checkpoint)
+	}
+	if _, err := loadCheckpoint(state.NewFileStore(""), "test/repo"); err == nil {
+		t.Error("expected no checkpoint in the default state directory")
+	}
+}
+
+func TestLoadCheckpoint_NothingToResume(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if _, err := loadCheckpoint(state.NewFileStore(""), "test/repo"); err == nil {
+		t.Error("expected error without a state file")
+	}
+
+	completed := &state.FetchState{Repository: "test/repo", LastPRNumber: 3, LastFetchTime: time.Now()}
+	if err := state.SaveState(completed, state.GetStateFilePath("test/repo")); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+	_, err := loadCheckpoint(state.NewFileStore(""), "test/repo")
+	if err == nil || !strings.Contains(err.Error(), "no interrupted fetch") {
+		t.Errorf("expected no interrupted fetch error, got %v", err)
+	}
+}
+
+func TestFetchAll_NoPullRequestsClearsCheckpoint(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repoPath := "test/repo"
+	completed := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		prev *state.FetchState
+	}{
+		{"after a completed fetch", &state.FetchState{Repository: repoPath, LastPRNumber: 3, LastFetchTime: completed}},
+		{"without a completed fetch", &state.FetchState{Repository: repoPath}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interrupted := *tt.prev
+			interrupted.Checkpoint = &state.Checkpoint{Cursor: "cursor_5", PageNum: 1}
+			if err := state.SaveState(&interrupted, state.GetStateFilePath(repoPath)); err != nil {
+				t.Fatalf("failed to save state: %v", err)
+			}
+
+			client := github.NewMockClientWithOptions(github.WithPullRequests(nil))
+			writer := output.NewWriter(io.Discard)
+			run := newCheckpointer(state.NewFileStore(""), repoPath, "", writer, github.FetchOptions{}, nil)
+			if err := fetchAllPullRequestsWithOptions(context.Background(), client, "test", "repo", writer, "", github.FetchOptions{}, run); err != nil {
+				t.Fatalf("fetch failed: %v", err)
+			}
+
+			fetchState, err := state.LoadState(state.GetStateFilePath(repoPath))
+			if tt.prev.LastFetchTime.IsZero() {
+				if !errors.Is(err, state.ErrNoState) {
+					t.Errorf("expected the state to be removed, got %+v, %v", fetchState, err)
+				}
+				return
+			}
+			if err != nil || fetchState.Checkpoint != nil || fetchState.LastPRNumber != tt.prev.LastPRNumber {
+				t.Errorf("expected the completed state without a checkpoint, got %+v, %v", fetchState, err)
+			}
+		})
+	}
+}
+
+func TestNewCheckpointer_AbsoluteOutputFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	run := newCheckpointer(state.NewFileStore(""), "test/repo", "prs.ndjson", output.NewWriter(io.Discard), github.FetchOptions{}, nil)
+	want, err := filepath.Abs("prs.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.checkpoint.OutputFile != want {
+		t.Errorf("OutputFile = %q, want %q", run.checkpoint.OutputFile, want)
+	}
+	if !sameFile("prs.ndjson", run.checkpoint.OutputFile) {
+		t.Error("expected the relative --output to match the recorded output file")
+	}
+}
+
+func TestOpenResumeWriter_ModifiedOutput(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "prs.ndjson")
+	if err := os.WriteFile(outputFile, []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("failed to write output: %v", err)
+	}
+
+	_, err := openResumeWriter(&state.Checkpoint{OutputFile: outputFile, OutputOffset: 100})
+	if err == nil || !strings.Contains(err.Error(), "smaller than at the last checkpoint") {
+		t.Errorf("expected truncated output to be rejected, got %v", err)
+	}
+}
+
+// cancellingWriter cancels a fetch once a given number of records is written.
+type cancellingWriter struct {
+	*output.Writer
+	cancel func()
+	after  int
+}
+
+func (w *cancellingWriter) Write(record interface{}) error {
+	err := w.Writer.Write(record)
+	if w.Count() == w.after {
+		w.cancel()
+	}
+	return err
+}
+
+func TestFetchAll_CancelledSavesCheckpoint(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "prs.ndjson")
+	metadataFile := filepath.Join(tmpDir, "metadata.json")
+	repoPath := "test/repo"
+	opts := github.FetchOptions{PageSize: 5}
+
+	fileWriter, err := output.NewFileWriter(outputFile)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancelling in the middle of the second page still writes all of it
+	writer := &cancellingWriter{Writer: fileWriter, cancel: cancel, after: 7}
+	client := github.NewMockClientWithOptions(github.WithPullRequests(testPullRequests(12)), github.WithPagination(5))
+	run := newCheckpointer(state.NewFileStore(""), repoPath, outputFile, writer, opts, nil)
+	err = fetchAllPullRequestsWithOptions(ctx, client, "test", "repo", writer, metadataFile, opts, run)
+	if !errors.Is(err, relaierrors.ErrPartialFetch) {
+		t.Fatalf("expected ErrPartialFetch, got %v", err)
+	}
+	fileWriter.Close()
+
+	if numbers := readPRNumbers(t, outputFile); len(numbers) != 10 {
+		t.Errorf("expected the two fetched pages to be written, got %v", numbers)
+	}
+
+	checkpoint, err := loadCheckpoint(state.NewFileStore(""), repoPath)
+	if err != nil {
+		t.Fatalf("expected a checkpoint after cancellation: %v", err)
+	}
+	if checkpoint.Cursor != "cursor_10" || checkpoint.PRsWritten != 10 || checkpoint.PageNum != 2 {
+		t.Errorf("unexpected checkpoint: %+v", checkpoint)
+	}
+
+	data, err := os.ReadFile(metadataFile)
+	if err != nil {
+		t.Fatalf("expected metadata for the interrupted fetch: %v", err)
+	}
+	var fetchMetadata metadata.FetchMetadata
+	if err := json.Unmarshal(data, &fetchMetadata); err != nil {
+		t.Fatalf("invalid metadata: %v", err)
+	}
+	if !fetchMetadata.Interrupted || fetchMetadata.Results.TotalPRs != 10 {
+		t.Errorf("expected interrupted metadata for 10 PRs, got interrupted=%v total=%d", fetchMetadata.Interrupted, fetchMetadata.Results.TotalPRs)
+	}
+}
+
+func TestFetchAll_CancelledBeforeFirstCallSavesInterruptedMetadata(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	metadataFile := filepath.Join(t.TempDir(), "metadata.json")
+	opts := github.FetchOptions{PageSize: 5}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	writer := output.NewWriter(io.Discard)
+	client := github.NewMockClientWithOptions(github.WithPullRequests(testPullRequests(12)), github.WithPagination(5))
+	run := newCheckpointer(state.NewFileStore(""), "test/repo", "", writer, opts, nil)
+	if err := fetchAllPullRequestsWithOptions(ctx, client, "test", "repo", writer, metadataFile, opts, run); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation error, got %v", err)
+	}
+
+	data, err := os.ReadFile(metadataFile)
+	if err != nil {
+		t.Fatalf("expected metadata for the interrupted fetch: %v", err)
+	}
+	var fetchMetadata metadata.FetchMetadata
+	if err := json.Unmarshal(data, &fetchMetadata); err != nil {
+		t.Fatalf("invalid metadata: %v", err)
+	}
+	if !fetchMetadata.Interrupted || fetchMetadata.Results.TotalPRs != 0 || !fetchMetadata.Parameters.FetchAll {
+		t.Errorf("expected interrupted --all metadata without PRs, got %+v", fetchMetadata)
+	}
+	if stored, err := run.store.LoadLatestMetadata("test/repo"); err != nil || stored == nil || !stored.Interrupted {
+		t.Errorf("expected interrupted metadata in the state store, got %+v, %v", stored, err)
+	}
+}
+
+func TestFetchRepository_StateLocked(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	stateDir := t.TempDir()
+	outputFile := filepath.Join(t.TempDir(), "prs.ndjson")
+	if err := os.WriteFile(outputFile, []byte("{\"number\":1}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	held, err := state.AcquireLock(context.Background(), state.StateFilePath(stateDir, "test/repo"), 0)
+	if err != nil {
+		t.Fatalf("failed to take the lock: %v", err)
+	}
+
+	client := github.NewMockClientWithOptions(github.WithPullRequests(testPullRequests(3)))
+	runOpts := fetchRunOptions{outputFile: outputFile, fetchAll: true, stateDir: stateDir}
+	err = fetchRepository(context.Background(), client, "test", "repo", runOpts)
+	if !errors.Is(err, state.ErrLocked) {
+		t.Errorf("expected ErrLocked, got %v", err)
+	} else if !strings.Contains(err.Error(), fmt.Sprintf("pid %d", os.Getpid())) {
+		t.Errorf("expected the error to name the lock holder, got %v", err)
+	}
+	if client.CallCount != 0 {
+		t.Errorf("expected no API calls while locked, got %d", client.CallCount)
+	}
+	if data, _ := os.ReadFile(outputFile); string(data) != "{\"number\":1}\n" {
+		t.Errorf("expected the output file to be untouched, got %q", data)
+	}
+
+	// Waiting succeeds once the other run releases the lock
+	released := make(chan struct{})
+	go func() {
+		defer close(released)
+		time.Sleep(100 * time.Millisecond)
+		_ = held.Unlock()
+	}()
+	runOpts.lockWait = 5 * time.Second
+	err = fetchRepository(context.Background(), client, "test", "repo", runOpts)
+	<-released
+	if err != nil {
+		t.Fatalf("expected the fetch to run after waiting, got %v", err)
+	}
+	if numbers := readPRNumbers(t, outputFile); len(numbers) != 3 {
+		t.Errorf("expected 3 PRs after waiting, got %v", numbers)
+	}
+}
@@ -25,10 +25,15 @@
 //   - Customizable output destinations (stdout or file)
 //   - GitHub token authentication via flag or environment variable
 //   - Graceful error handling with appropriate exit codes
+//   - Syncing every repository listed in the config file with the sync command
+//   - Search filters on state, branch, labels, author and raw qualifiers
+//   - Inspecting, editing, exporting and resetting fetch state with the state command
 //
 // Usage:
 //
 //	sirseer-relay fetch <org>/<repo> [flags]
+//	sirseer-relay sync [flags]
+//	sirseer-relay state list|show|set|reset|export|import [flags]
 //
 // Example:
 //
@@ -39,6 +44,8 @@
 // Exit codes:
 //   - 0: Success
 //   - 1: General error
-//   - 2: Authentication/authorization error
+//   - 2: Authentication/authorization error, or repository or pull request not found
 //   - 3: Network error
+//   - 4: Partial fetch, stopped by --max-duration and resumable, or a sync
+//     where only some repositories succeeded
 package main
@@ -38,15 +38,16 @@ import (
 // This command fetches pull request data from a specified GitHub repository
 // and outputs it in NDJSON format. By default, it fetches only the first page
 // of pull requests (up to 50). Use the --all flag to fetch all pull requests.
-func newFetchCommand(configFile string) *cobra.Command {
+// configFile points at the root command's --config flag value, which is only
+// populated once flags have been parsed.
+func newFetchCommand(configFile *string) *cobra.Command {
 	var (
-		token          string
-		outputFile     string
-		outputDir      string
-		metadataFile   string
-		fetchAll       bool
+		opts           fetchRunOptions
 		requestTimeout int
-		batchSize      int
+		maxDuration    time.Duration
+		repoFilter     config.RepositoryFilterConfig
+		searchFilter   searchFilterFlags
+		prNumbers      prNumberFlags
 	)
 
 	cmd := &cobra.Command{
@@ -57,6 +58,17 @@ func newFetchCommand(configFile string) *cobra.Command {
 The repository must be specified in the format: <org>/<repo>
 For example: golang/go, kubernetes/kubernetes
 
+Use <org>/* to fetch every repository owned by an organization or user, one
+after another, each into its own file under --output-dir/<org>/<repo>/.
+Narrow the list with --exclude-archived, --exclude-forks, --visibility,
+--topic and --pushed-since. Quote the argument so the shell does not expand it.
+
+Narrow the pull requests fetched with --state, --base, --label, --author,
+--draft and --exclude-bots, or pass any GitHub search qualifiers with
+--query. --date-field selects the date --since and --until apply to.
+
+Use --pr or --pr-file to fetch only specific pull requests by number.
+
 Authentication is required via GitHub token:
   - Use --token flag to provide token directly
   - Or set GITHUB_TOKEN environment variable
@@ -71,15 +83,27 @@ Examples:
   # Fetch PRs created in 2024
   sirseer-relay fetch golang/go --since 2024-01-01 --until 2024-12-31
 
+  # Fetch PRs merged into main in 2024, leaving out bot PRs
+  sirseer-relay fetch golang/go --all --state merged --base main --date-field merged --since 2024-01-01 --until 2024-12-31 --exclude-bots
+
   # Resume from previous fetch (incremental update)
   sirseer-relay fetch golang/go --incremental
 
+  # Continue an --all fetch that was interrupted
+  sirseer-relay fetch kubernetes/kubernetes --resume
+
+  # Re-fetch a few pull requests by number
+  sirseer-relay fetch golang/go --pr 123,456,1000-1050
+
   # Save output to a file
-  sirseer-relay fetch golang/go --all --output prs.ndjson`,
+  sirseer-relay fetch golang/go --all --output prs.ndjson
+
+  # Fetch all PRs of every active, non-fork repository of an organization
+  sirseer-relay fetch 'myorg/*' --all --exclude-archived --exclude-forks`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Load configuration
-			cfg, err := config.LoadConfigForRepo(configFile, args[0])
+			cfg, err := config.LoadConfigForRepo(*configFile, args[0])
 			if err != nil {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
@@ -91,126 +115,397 @@ Examples:
 
 			// Apply config defaults and CLI overrides
 			// CLI flags take precedence over config file
-			if batchSize == 0 {
-				batchSize = cfg.GetBatchSize(args[0])
+			if opts.batchSize == 0 {
+				opts.batchSize = cfg.GetBatchSize(args[0])
+			}
+			if opts.stateDir == "" {
+				opts.stateDir = cfg.Defaults.StateDir
 			}
 
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(cmd.Context(), time.Duration(requestTimeout)*time.Second)
-			defer cancel()
+			// The request timeout applies to each API call; only --max-duration
+			// bounds the run as a whole
+			opts.requestTimeout = time.Duration(requestTimeout) * time.Second
+			ctx := cmd.Context()
+			if maxDuration > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, maxDuration)
+				defer cancel()
+			}
 
 			// Get date flags
-			since, err := cmd.Flags().GetString("since")
-			if err != nil {
+			if opts.since, err = cmd.Flags().GetString("since"); err != nil {
 				return fmt.Errorf("failed to get since flag: %w", err)
 			}
-			until, err := cmd.Flags().GetString("until")
-			if err != nil {
+			if opts.until, err = cmd.Flags().GetString("until"); err != nil {
 				return fmt.Errorf("failed to get until flag: %w", err)
 			}
-			incremental, err := cmd.Flags().GetBool("incremental")
-			if err != nil {
+			if opts.incremental, err = cmd.Flags().GetBool("incremental"); err != nil {
 				return fmt.Errorf("failed to get incremental flag: %w", err)
 			}
+			if opts.filters, err = searchFilter.build(cmd.Flags().Changed("draft")); err != nil {
+				return err
+			}
+			if opts.prNumbers, err = prNumbers.numbers(); err != nil {
+				return err
+			}
+			if usesState(opts) {
+				if opts.store, err = openStateStore(cfg, opts.stateDir); err != nil {
+					return err
+				}
+				defer opts.store.Close()
+			}
 
-			return runFetch(ctx, args[0], token, outputFile, outputDir, metadataFile, fetchAll, batchSize, since, until, incremental, cfg)
+			if isOrganizationArg(args[0]) {
+				var filter github.RepositoryFilter
+				if filter, err = newRepositoryFilter(repoFilter); err != nil {
+					return err
+				}
+				err = runOrganizationFetch(ctx, args[0], opts, cfg, filter)
+			} else {
+				for _, name := range repositoryFilterFlags {
+					if cmd.Flags().Changed(name) {
+						return fmt.Errorf("--%s can only be used with <org>/*", name)
+					}
+				}
+				err = runFetch(ctx, args[0], opts, cfg)
+			}
+			if err != nil && maxDuration > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return maxDurationError(maxDuration, opts, err)
+			}
+			return err
 		},
 	}
 
 	// Define flags
-	cmd.Flags().StringVar(&token, "token", "", "GitHub personal access token (overrides GITHUB_TOKEN env var)")
-	cmd.Flags().StringVar(&outputFile, "output", "", "Output file path (default: stdout)")
-	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Output directory for generated files (default: ./output)")
-	cmd.Flags().IntVar(&requestTimeout, "request-timeout", 180, "Request timeout in seconds (default: 3 minutes)")
+	cmd.Flags().StringVar(&opts.token, "token", "", "GitHub personal access token (overrides GITHUB_TOKEN env var)")
+	cmd.Flags().StringVar(&opts.outputFile, "output", "", "Output file path (default: stdout)")
+	cmd.Flags().StringVar(&opts.outputDir, "output-dir", "", "Output directory for generated files (default: ./output)")
+	cmd.Flags().StringVar(&opts.outputMode, "output-mode", "", "How to treat an existing --output file: overwrite, append or upsert (default: append with --incremental, otherwise overwrite)")
+	cmd.Flags().IntVar(&requestTimeout, "request-timeout", 180, "Timeout for each API request in seconds (default: 3 minutes)")
+	cmd.Flags().DurationVar(&maxDuration, "max-duration", 0, "Stop the whole fetch after this long, e.g. 30m or 2h (default: no limit)")
 
 	// Pagination flag
-	cmd.Flags().BoolVar(&fetchAll, "all", false, "Fetch all pull requests from the repository")
+	cmd.Flags().BoolVar(&opts.fetchAll, "all", false, "Fetch all pull requests from the repository")
+	cmd.Flags().BoolVar(&opts.resume, "resume", false, "Continue an interrupted --all fetch from its last checkpoint, appending to the same output file")
 
 	// Time window filtering
-	cmd.Flags().String("since", "", "Fetch PRs created on or after this date (format: YYYY-MM-DD, RFC3339, or relative like 7d)")
-	cmd.Flags().String("until", "", "Fetch PRs created on or before this date (format: YYYY-MM-DD, RFC3339, or relative like 7d)")
+	cmd.Flags().String("since", "", "Fetch PRs created at or after this time (format: YYYY-MM-DD, RFC3339, or relative like 7d)")
+	cmd.Flags().String("until", "", "Fetch PRs created before this time, or on or before a YYYY-MM-DD date (format: YYYY-MM-DD, RFC3339, or relative like 7d)")
 
 	// Incremental fetch
-	cmd.Flags().Bool("incremental", false, "Continue from the last successful fetch (requires previous state file)")
+	cmd.Flags().Bool("incremental", false, "Fetch PRs created or updated since the last successful fetch (requires previous state file)")
 
 	// Configuration
-	cmd.Flags().IntVar(&batchSize, "batch-size", 0, "Number of PRs to fetch per API call (default from config or 50)")
+	cmd.Flags().IntVar(&opts.batchSize, "batch-size", 0, "Number of PRs to fetch per API call (default from config or 50)")
 
 	// Metadata
-	cmd.Flags().StringVar(&metadataFile, "metadata-file", "", "Path to save fetch metadata (default: fetch-metadata.json)")
+	cmd.Flags().StringVar(&opts.metadataFile, "metadata-file", "", "Path to save fetch metadata (default: fetch-metadata.json)")
+
+	// State
+	cmd.Flags().StringVar(&opts.stateDir, "state-dir", "", "Directory for the state files or database used by --incremental and --resume (default from config or ~/.sirseer/state)")
+	cmd.Flags().DurationVar(&opts.lockWait, "wait-for-lock", 0, "Wait up to this long for another fetch of the same repository to finish, e.g. 10m (default: fail at once)")
+
+	// Pull requests by number
+	cmd.Flags().StringSliceVar(&prNumbers.specs, "pr", nil, "Fetch only these pull requests, e.g. 123,456,1000-1050 (repeatable)")
+	cmd.Flags().StringVar(&prNumbers.file, "pr-file", "", "Fetch only the pull requests listed in this file, one number or range per line")
+
+	// Search filters
+	addSearchFilterFlags(cmd, &searchFilter)
+
+	// Organization filters
+	addRepositoryFilterFlags(cmd, &repoFilter)
 
 	return cmd
 }
 
+// fetchRunOptions holds the command line options of a fetch.
+type fetchRunOptions struct {
+	token        string
+	outputFile   string
+	outputDir    string
+	outputMode   string
+	metadataFile string
+	fetchAll     bool
+	batchSize    int
+	since        string
+	until        string
+	incremental  bool
+	resume       bool
+
+	// filters narrow which PRs are selected; nil selects every PR
+	filters *state.SearchFilters
+
+	// prNumbers selects PRs by number instead of by search
+	prNumbers []int
+
+	// stateDir holds the state files and the metadata of previous fetches;
+	// empty means state.DefaultStateDir
+	stateDir string
+
+	// store persists state and metadata; nil keeps them as files in stateDir
+	store state.StateStore
+
+	// lockWait is how long to wait for another run holding the state lock;
+	// zero fails at once
+	lockWait time.Duration
+
+	// requestTimeout limits each API request; zero means no limit
+	requestTimeout time.Duration
+}
+
+// stateStore returns the store that holds the state of the fetched
+// repositories.
+func (o fetchRunOptions) stateStore() state.StateStore {
+	if o.store != nil {
+		return o.store
+	}
+	return state.NewFileStore(o.stateDir)
+}
+
+// maxDurationError reports a fetch cut short by --max-duration. Checkpointed
+// --all fetches can be continued with --resume, so they end with
+// ErrPartialFetch and its distinct exit code; other fetches keep err.
+func maxDurationError(maxDuration time.Duration, opts fetchRunOptions, err error) error {
+	if (opts.fetchAll && !opts.incremental) || opts.resume {
+		return fmt.Errorf("stopped after reaching --max-duration of %s; run again with --resume to continue: %w", maxDuration, relaierrors.ErrPartialFetch)
+	}
+	return fmt.Errorf("stopped after reaching --max-duration of %s: %w", maxDuration, err)
+}
+
 // runFetch executes the main fetch logic. It parses the repository argument,
 // validates the GitHub token, creates the output writer, and delegates to either
 // fetchFirstPageWithOptions (default) or fetchAllPullRequestsWithOptions (with --all flag).
 // Returns an error if any step fails, which will be mapped to an appropriate exit code.
-func runFetch(ctx context.Context, repoArg, tokenFlag, outputFile, outputDir, metadataFile string, fetchAll bool, batchSize int, since, until string, incremental bool, cfg *config.Config) error {
+func runFetch(ctx context.Context, repoArg string, runOpts fetchRunOptions, cfg *config.Config) (err error) {
 	// Parse repository argument
 	owner, repo, err := parseRepository(repoArg)
 	if err != nil {
 		return err
 	}
 
-	// Get GitHub token
-	token := getToken(tokenFlag, cfg.GitHub.TokenEnv)
-	if token == "" {
-		return fmt.Errorf("GitHub token not found. Set %s or use --token flag", cfg.GitHub.TokenEnv)
+	// Resolve how API requests are authenticated
+	creds, err := newCredentials(ctx, runOpts.token, cfg, owner, runOpts.requestTimeout)
+	if err != nil {
+		return err
+	}
+
+	// Create GitHub client with config endpoints
+	client := newGitHubClient(creds, cfg, runOpts.requestTimeout)
+
+	return fetchRepository(ctx, client, owner, repo, runOpts)
+}
+
+// fetchRepository fetches the pull requests of one repository with client,
+// in the mode selected by runOpts. It is shared by the fetch and sync
+// commands.
+func fetchRepository(ctx context.Context, client github.Client, owner, repo string, runOpts fetchRunOptions) error {
+	// Runs that read or write the saved state hold its lock until they finish
+	if usesState(runOpts) {
+		lock, err := lockState(ctx, runOpts.stateStore(), fmt.Sprintf("%s/%s", owner, repo), runOpts.lockWait)
+		if err != nil {
+			return err
+		}
+		defer lock.Unlock()
+	}
+	return fetchRepositoryLocked(ctx, client, owner, repo, runOpts)
+}
+
+// usesState reports whether a run in the mode selected by runOpts reads or
+// writes the repository's saved state.
+func usesState(runOpts fetchRunOptions) bool {
+	return runOpts.fetchAll || runOpts.incremental || runOpts.resume
+}
+
+// fetchRepositoryLocked is fetchRepository for callers that already hold
+// the repository's state lock.
+func fetchRepositoryLocked(ctx context.Context, client github.Client, owner, repo string, runOpts fetchRunOptions) (err error) {
+	if len(runOpts.prNumbers) > 0 {
+		if err := validatePRNumberOptions(runOpts); err != nil {
+			return err
+		}
+	}
+
+	// Resuming continues the interrupted run with its own window and output
+	if runOpts.resume {
+		return resumeFetch(ctx, client, owner, repo, runOpts)
+	}
+
+	// Determine how an existing output file is treated
+	mode, err := resolveOutputMode(runOpts)
+	if err != nil {
+		return err
 	}
 
 	// Create output writer
 	// If no output flags specified, use default output directory
+	outputFile, outputDir := runOpts.outputFile, runOpts.outputDir
 	if outputFile == "" && outputDir == "" {
 		outputDir = "output"
 	}
-	writer, generatedOutputFile, err := createOutputWriter(outputFile, outputDir, owner, repo)
+	writer, generatedOutputFile, err := createOutputWriter(outputFile, outputDir, owner, repo, mode)
 	if err != nil {
 		return err
 	}
-	defer writer.Close()
-
-	// Create GitHub client with config endpoints
-	// TODO: Update github package to accept custom endpoints
-	client := github.NewGraphQLClient(token)
+	// Upserted records only reach the output file on Close, so its error matters
+	defer func() {
+		if closeErr := writer.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close output: %w", closeErr)
+		}
+	}()
 
 	// Parse and validate date flags
-	sinceTime, untilTime, err := parseDateFlags(since, until)
+	sinceTime, untilTime, err := parseDateFlags(runOpts.since, runOpts.until)
 	if err != nil {
 		return err
 	}
 
+	// Metadata is saved next to a generated output file, so repositories
+	// fetched by sync or <org>/* each get their own
+	metadataFile := runOpts.metadataFile
+	if metadataFile == "" && generatedOutputFile != "" {
+		metadataFile = defaultMetadataFile(generatedOutputFile)
+	}
+
 	// Handle incremental fetch
-	if incremental {
-		return fetchIncremental(ctx, client, owner, repo, writer, metadataFile, sinceTime, untilTime, fetchAll)
+	if runOpts.incremental {
+		return fetchIncremental(ctx, client, owner, repo, writer, metadataFile, runOpts.stateStore(), sinceTime, untilTime, runOpts.filters, runOpts.fetchAll)
 	}
 
 	// Build fetch options with batch size
-	opts := github.FetchOptions{
+	opts := withFilters(github.FetchOptions{
 		Since:    sinceTime,
 		Until:    untilTime,
-		PageSize: batchSize,
-	}
+		PageSize: runOpts.batchSize,
+	}, runOpts.filters)
 
-	// Handle metadata file path
-	if metadataFile == "" && generatedOutputFile != "" {
-		// Auto-generate metadata filename based on output file
-		metadataFile = strings.TrimSuffix(generatedOutputFile, ".ndjson") + "-metadata.json"
+	// Fetch only the PRs selected by number
+	if len(runOpts.prNumbers) > 0 {
+		return fetchPullRequestsByNumber(ctx, client, owner, repo, writer, metadataFile, runOpts.prNumbers, runOpts.batchSize)
 	}
 
 	// Fetch all PRs if --all flag is set
-	if fetchAll {
-		return fetchAllPullRequestsWithOptions(ctx, client, owner, repo, writer, metadataFile, opts)
+	if runOpts.fetchAll {
+		run := newCheckpointer(runOpts.stateStore(), fmt.Sprintf("%s/%s", owner, repo), generatedOutputFile, writer, opts, nil)
+		return fetchAllPullRequestsWithOptions(ctx, client, owner, repo, writer, metadataFile, opts, run)
 	}
 
 	// Default behavior: fetch first page only
 	return fetchFirstPageWithOptions(ctx, client, owner, repo, writer, metadataFile, opts)
 }
 
+// resumeFetch continues an interrupted --all fetch from its checkpoint. The
+// original time window and output file are reused; output flags may only
+// repeat the original file.
+func resumeFetch(ctx context.Context, client github.Client, owner, repo string, runOpts fetchRunOptions) error {
+	if runOpts.incremental {
+		return fmt.Errorf("--resume cannot be combined with --incremental")
+	}
+	if runOpts.since != "" || runOpts.until != "" {
+		return fmt.Errorf("--since and --until cannot be used with --resume; the interrupted fetch's time window is reused")
+	}
+	if runOpts.filters != nil {
+		return fmt.Errorf("search filters cannot be used with --resume; the interrupted fetch's filters are reused")
+	}
+
+	repoPath := fmt.Sprintf("%s/%s", owner, repo)
+	checkpoint, err := loadCheckpoint(runOpts.stateStore(), repoPath)
+	if err != nil {
+		return err
+	}
+	if runOpts.outputFile != "" && !sameFile(runOpts.outputFile, checkpoint.OutputFile) {
+		return fmt.Errorf("--output %s does not match the output file of the interrupted fetch (%s)", runOpts.outputFile, checkpoint.OutputFile)
+	}
+
+	writer, err := openResumeWriter(checkpoint)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	metadataFile := runOpts.metadataFile
+	if metadataFile == "" && checkpoint.OutputFile != "" {
+		metadataFile = defaultMetadataFile(checkpoint.OutputFile)
+	}
+
+	fmt.Fprintf(os.Stderr, "Resuming interrupted fetch of %s after %d PRs (page %d)\n", repoPath, checkpoint.PRsWritten, checkpoint.PageNum)
+
+	opts := withFilters(github.FetchOptions{
+		Since:    checkpoint.Since,
+		Until:    checkpoint.Until,
+		PageSize: checkpoint.PageSize,
+	}, checkpoint.Filters)
+	run := newCheckpointer(runOpts.stateStore(), repoPath, checkpoint.OutputFile, writer, opts, checkpoint)
+	return fetchAllPullRequestsWithOptions(ctx, client, owner, repo, writer, metadataFile, opts, run)
+}
+
+// sameFile reports whether the paths a and b name the same file once made
+// absolute. Checkpoints record an absolute output path while --output is
+// usually given relative to the working directory.
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return absA == absB
+}
+
+// defaultMetadataFile returns the metadata file path that accompanies an
+// output file.
+func defaultMetadataFile(outputFile string) string {
+	return strings.TrimSuffix(outputFile, ".ndjson") + "-metadata.json"
+}
+
+// newGitHubClient creates the GitHub API client for a fetch. The GraphQL
+// endpoint comes from the configuration, so GitHub Enterprise Server hosts
+// set via github.graphql_endpoint or GITHUB_GRAPHQL_ENDPOINT are honored by
+// every fetch mode. Transient failures are retried as configured under
+// retry, and with rate_limit.auto_wait the client sleeps until the rate limit
+// resets instead of failing. Each HTTP request is limited to requestTimeout
+// and authenticated with one of creds, chosen by remaining rate limit budget.
+func newGitHubClient(creds []github.PooledToken, cfg *config.Config, requestTimeout time.Duration) *github.GraphQLClient {
+	opts := []github.ClientOption{
+		github.WithTokenPool(creds...),
+		github.WithEndpoint(cfg.GitHub.GraphQLEndpoint),
+		github.WithRetryPolicy(newRetryPolicy(os.Stderr, cfg.Retry)),
+		github.WithRequestTimeout(requestTimeout),
+	}
+	if cfg.RateLimit.AutoWait {
+		opts = append(opts, github.WithRateLimitWaiter(newRateLimitWaiter(os.Stderr, cfg.RateLimit.ShowProgress)))
+	}
+	return github.NewGraphQLClient("", opts...)
+}
+
+// resolveOutputMode determines how an existing --output file is treated.
+// Incremental fetches append by default so they never wipe the dataset
+// written by earlier runs. Append and upsert need an explicit output file,
+// and upsert is not available for checkpointed --all fetches because their
+// records only reach the file when the writer is closed.
+func resolveOutputMode(runOpts fetchRunOptions) (output.Mode, error) {
+	name := runOpts.outputMode
+	hasFile := runOpts.outputFile != "" && runOpts.outputFile != "-"
+	if name == "" && runOpts.incremental && hasFile {
+		name = string(output.ModeAppend)
+	}
+
+	mode, err := output.ParseMode(name)
+	if err != nil {
+		return "", err
+	}
+	if mode != output.ModeOverwrite && !hasFile {
+		return "", fmt.Errorf("--output-mode %s requires --output <file>", mode)
+	}
+	if mode == output.ModeUpsert && runOpts.fetchAll && !runOpts.incremental {
+		return "", fmt.Errorf("--output-mode upsert cannot be used with --all; use --output-mode append instead")
+	}
+	return mode, nil
+}
+
 // createOutputWriter creates an output writer based on the output file parameter.
 // If outputFile is empty, it generates a timestamped filename in the output directory.
+// mode controls how an explicit output file that already exists is treated.
 // Returns the writer and the actual output file path used.
-func createOutputWriter(outputFile, outputDir, owner, repo string) (output.OutputWriter, string, error) {
+func createOutputWriter(outputFile, outputDir, owner, repo string, mode output.Mode) (output.OutputWriter, string, error) {
 	// If explicit output file is specified
 	if outputFile != "" {
 		// Special case: "-" means stdout
@@ -218,7 +513,7 @@ func createOutputWriter(outputFile, outputDir, owner, repo string) (output.Outpu
 			return output.NewWriter(os.Stdout), "", nil
 		}
 		// Otherwise use the specified file
-		fileWriter, err := output.NewFileWriter(outputFile)
+		fileWriter, err := output.NewFileWriterWithMode(outputFile, mode)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to create output file: %w", err)
 		}
@@ -260,6 +555,8 @@ func createOutputWriter(outputFile, outputDir, owner, repo string) (output.Outpu
 
 // parseDateFlags parses and validates the since and until date flags.
 // It returns parsed time pointers and ensures that since is before until if both are provided.
+// The returned since is inclusive and until exclusive; a date alone in --until
+// is turned into the start of the following day, so that day is included.
 func parseDateFlags(since, until string) (sinceTime *time.Time, untilTime *time.Time, err error) {
 	if since != "" {
 		parsed, parseErr := parseDate(since)
@@ -274,11 +571,15 @@ func parseDateFlags(since, until string) (sinceTime *time.Time, untilTime *time.
 		if parseErr != nil {
 			return nil, nil, fmt.Errorf("invalid --until date format: %w", parseErr)
 		}
+		// Until is exclusive, so a date alone ends with the following day
+		if isDateOnly(until) {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
 		untilTime = &parsed
 	}
 
 	// Validate date range
-	if sinceTime != nil && untilTime != nil && sinceTime.After(*untilTime) {
+	if sinceTime != nil && untilTime != nil && !sinceTime.Before(*untilTime) {
 		return nil, nil, fmt.Errorf("--since date must be before --until date")
 	}
 
@@ -313,6 +614,12 @@ func parseDate(dateStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unsupported date format. Use YYYY-MM-DD, RFC3339, or relative (7d, 1w)")
 }
 
+// isDateOnly reports whether dateStr is a date without a time of day.
+func isDateOnly(dateStr string) bool {
+	_, err := time.Parse("2006-01-02", dateStr)
+	return err == nil
+}
+
 // parseRepository parses a repository argument in the format "owner/repo"
 // into separate owner and repository name components. It validates that
 // both components are present and non-empty.
@@ -405,6 +712,19 @@ func fetchFirstPageWithOptions(ctx context.Context, client github.Client, owner,
 
 	// Initialize metadata tracker
 	tracker := metadata.New()
+	usage := startAPIUsage(client)
+
+	params := metadata.FetchParams{
+		Organization: owner,
+		Repository:   repo,
+		Since:        opts.Since,
+		Until:        opts.Until,
+		FetchAll:     false,
+		BatchSize:    opts.PageSize,
+		DateField:    opts.DateField,
+		Qualifiers:   opts.Qualifiers,
+		ExcludeBots:  opts.ExcludeBots,
+	}
 
 	// Show progress
 	fmt.Fprintf(os.Stderr, "Fetching pull requests from %s/%s...", owner, repo)
@@ -414,11 +734,14 @@ func fetchFirstPageWithOptions(ctx context.Context, client github.Client, owner,
 	if err != nil {
 		// Clear progress line
 		fmt.Fprintf(os.Stderr, "\r\033[K")
+		if ctx.Err() != nil {
+			saveInterruptedMetadata(tracker.GenerateMetadata(version.Version, params, false, nil), metadataFile, nil)
+		}
 		return err
 	}
 
-	// Track API call
-	tracker.IncrementAPICall()
+	// Track API calls
+	recordPageAPICalls(tracker, usage, page)
 
 	// Write PRs to output
 	prCount := 0
@@ -442,15 +765,6 @@ func fetchFirstPageWithOptions(ctx context.Context, client github.Client, owner,
 		fmt.Fprintf(os.Stderr, "Successfully fetched %d pull requests\n", prCount)
 
 		// Generate and save metadata for single page fetch
-		params := metadata.FetchParams{
-			Organization: owner,
-			Repository:   repo,
-			Since:        opts.Since,
-			Until:        opts.Until,
-			FetchAll:     false,
-			BatchSize:    opts.PageSize,
-		}
-
 		fetchMetadata := tracker.GenerateMetadata(version.Version, params, false, nil)
 
 		// Save metadata
@@ -466,16 +780,26 @@ func fetchFirstPageWithOptions(ctx context.Context, client github.Client, owner,
 }
 
 // fetchAllPullRequestsWithOptions fetches all pull requests with custom options.
-func fetchAllPullRequestsWithOptions(ctx context.Context, client github.Client, owner, repo string, writer output.OutputWriter, metadataFile string, opts github.FetchOptions) error {
+// Progress is checkpointed through run after every page, and a run built from
+// a previous checkpoint continues where that fetch stopped.
+func fetchAllPullRequestsWithOptions(ctx context.Context, client github.Client, owner, repo string, writer output.OutputWriter, metadataFile string, opts github.FetchOptions, run *checkpointer) error {
+	usage := startAPIUsage(client)
+
 	// First, get repository info for total PR count
 	repoInfo, err := client.GetRepositoryInfo(ctx, owner, repo)
 	if err != nil {
+		if ctx.Err() != nil {
+			saveInterruptedMetadata(metadata.New().GenerateMetadata(version.Version, fullFetchParams(owner, repo, opts, opts.PageSize), false, nil), metadataFile, run.store)
+		}
 		return fmt.Errorf("failed to get repository info: %w", err)
 	}
 
 	totalPRs := repoInfo.TotalPullRequests
 	if totalPRs == 0 {
 		fmt.Fprintf(os.Stderr, "No pull requests found in %s/%s\n", owner, repo)
+		if err := run.clear(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to clear fetch checkpoint: %v\n", err)
+		}
 		return nil
 	}
 
@@ -484,25 +808,59 @@ func fetchAllPullRequestsWithOptions(ctx context.Context, client github.Client,
 
 	// Initialize progress tracking
 	progress := initializeProgress(totalPRs, owner, repo)
+	if opts.PageSize > 0 {
+		progress.pageSize = opts.PageSize
+	}
+	if opts.Since == nil && opts.Until == nil {
+		progress.expectedPRs = totalPRs
+	}
+	run.apply(progress, newWindowSlicer(client, owner, repo, opts, time.Time{}, time.Time{}))
+
+	// Record the starting point so even a failure on the first page can be resumed
+	if err := run.save(progress); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 
 	for progress.hasMore {
 		progress.pageNum++
-		pageOpts := github.FetchOptions{
-			PageSize: progress.pageSize,
-			After:    progress.cursor,
-			Since:    opts.Since,
-			Until:    opts.Until,
-		}
+		pageOpts := opts
+		pageOpts.PageSize = progress.pageSize
+		pageOpts.After = progress.cursor
+		pageOpts.DateRange = progress.slice
 
 		// Fetch page with retry on complexity errors
 		page, err := fetchWithComplexityRetry(ctx, client, owner, repo, pageOpts, &progress.pageSize)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "\r\033[K") // Clear progress line
-			return err
+			progress.pageNum--
+			return stopFetch(ctx, err, owner, repo, progress, tracker, metadataFile, opts, run)
 		}
 
-		// Track API call
-		tracker.IncrementAPICall()
+		// Track API calls
+		recordPageAPICalls(tracker, usage, page)
+
+		// The first page tells how many PRs the window holds. Search only
+		// returns the first SearchResultCap of them, so larger windows are
+		// split by date and fetched slice by slice instead.
+		if progress.slice == nil && progress.cursor == "" {
+			if progress.expectedPRs == 0 {
+				progress.expectedPRs = page.TotalCount
+			}
+			if page.TotalCount >= searchResultCap {
+				start, end := slicingRange(opts, sliceOrigin(opts, page.PullRequests), run.checkpoint.StartedAt)
+				progress.slicer = newWindowSlicer(client, owner, repo, opts, start, end)
+				fmt.Fprintf(os.Stderr, "\r\033[K")
+				fmt.Fprintf(os.Stderr, "%d pull requests match, more than search returns at once; fetching in %s-date slices\n", page.TotalCount, dateFieldName(opts))
+				if err := nextSlice(ctx, progress, tracker); err != nil {
+					return stopFetch(ctx, err, owner, repo, progress, tracker, metadataFile, opts, run)
+				}
+				continue
+			}
+		}
+
+		// Bot PRs left out by --exclude-bots are part of the search count
+		if progress.expectedPRs > 0 {
+			progress.expectedPRs -= page.Excluded
+		}
 
 		// Process batch of PRs
 		if err := processFetchBatch(page.PullRequests, writer, tracker, progress); err != nil {
@@ -511,10 +869,99 @@ func fetchAllPullRequestsWithOptions(ctx context.Context, client github.Client,
 
 		progress.cursor = page.EndCursor
 		progress.hasMore = page.HasNextPage
+
+		// Move on to the next slice once this one is exhausted
+		if !progress.hasMore && progress.slicer != nil && !progress.slicer.done() {
+			if err := nextSlice(ctx, progress, tracker); err != nil {
+				return stopFetch(ctx, err, owner, repo, progress, tracker, metadataFile, opts, run)
+			}
+		}
+
+		// Checkpoint only after the whole page is written
+		if progress.hasMore {
+			if err := run.save(progress); err != nil {
+				fmt.Fprintf(os.Stderr, "\r\033[K")
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	}
+
+	// Verify nothing was lost to the search result cap
+	if progress.expectedPRs > 0 && progress.allPRsProcessed < progress.expectedPRs {
+		fmt.Fprintf(os.Stderr, "\r\033[K")
+		fmt.Fprintf(os.Stderr, "Warning: fetched %d pull requests but %d were expected; PRs deleted or created during the fetch can account for small differences\n",
+			progress.allPRsProcessed, progress.expectedPRs)
 	}
 
 	// Finalize results and save state/metadata
-	return finalizeFetchResults(owner, repo, progress, tracker, metadataFile, opts)
+	return finalizeFetchResults(owner, repo, progress, tracker, metadataFile, opts, run)
+}
+
+// stopFetch ends a full fetch that could not finish. The checkpoint saved
+// after the last complete page lets it be continued with --resume. When ctx
+// was cancelled, by a signal or --max-duration, the output is flushed, the
+// checkpoint brought up to date and the metadata of the partial fetch saved
+// marked as interrupted, and ErrPartialFetch is returned instead of err.
+func stopFetch(ctx context.Context, err error, owner, repo string, progress *progressTracker, tracker *metadata.Tracker, metadataFile string, opts github.FetchOptions, run *checkpointer) error {
+	fmt.Fprintf(os.Stderr, "\r\033[K") // Clear progress line
+	fmt.Fprintf(os.Stderr, "Fetch interrupted after %d PRs. Run again with --resume to continue.\n", progress.allPRsProcessed)
+
+	if ctx.Err() == nil {
+		return err
+	}
+
+	if saveErr := run.save(progress); saveErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", saveErr)
+	}
+
+	saveInterruptedMetadata(tracker.GenerateMetadata(version.Version, fullFetchParams(owner, repo, opts, progress.pageSize), false, nil), metadataFile, run.store)
+
+	return fmt.Errorf("fetch of %s/%s interrupted after %d PRs: %w", owner, repo, progress.allPRsProcessed, relaierrors.ErrPartialFetch)
+}
+
+// fullFetchParams returns the parameters recorded in the metadata of an
+// --all fetch.
+func fullFetchParams(owner, repo string, opts github.FetchOptions, pageSize int) metadata.FetchParams {
+	return metadata.FetchParams{
+		Organization: owner,
+		Repository:   repo,
+		Since:        opts.Since,
+		Until:        opts.Until,
+		FetchAll:     true,
+		BatchSize:    pageSize,
+		DateField:    opts.DateField,
+		Qualifiers:   opts.Qualifiers,
+		ExcludeBots:  opts.ExcludeBots,
+	}
+}
+
+// saveInterruptedMetadata saves the metadata of a fetch that was stopped by
+// a signal or --max-duration, marked as interrupted, to metadataFile and,
+// when store is not nil, to the state store.
+func saveInterruptedMetadata(fetchMetadata *metadata.FetchMetadata, metadataFile string, store state.StateStore) {
+	fetchMetadata.Interrupted = true
+	if err := saveMetadata(fetchMetadata, metadataFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save fetch metadata: %v\n", err)
+	}
+	if store == nil {
+		return
+	}
+	if err := store.SaveMetadata(fetchMetadata); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record fetch metadata in the state store: %v\n", err)
+	}
+}
+
+// nextSlice moves progress to the start of the next created-date slice.
+func nextSlice(ctx context.Context, progress *progressTracker, tracker *metadata.Tracker) error {
+	slice, queries, err := progress.slicer.nextSlice(ctx)
+	tracker.AddAPICalls(queries)
+	if err != nil {
+		return err
+	}
+	progress.slice = &slice
+	progress.cursor = ""
+	progress.hasMore = true
+	return nil
 }
 
 // progressTracker holds the state for tracking fetch progress.
@@ -528,6 +975,16 @@ type progressTracker struct {
 	lastPRNumber    int
 	lastPRDate      time.Time
 	totalPRs        int
+
+	// expectedPRs is the number of PRs the fetch should produce, checked
+	// once it completes. Zero when unknown.
+	expectedPRs int
+
+	// slice is the created-date range being fetched once the window was
+	// split below the search result cap; slicer hands out the next ones.
+	// Both are nil while the window is fetched as a whole.
+	slice  *github.TimeRange
+	slicer *windowSlicer
 }
 
 // initializeProgress sets up progress tracking for fetching all PRs.
@@ -571,7 +1028,8 @@ func processFetchBatch(prs []github.PullRequest, writer output.OutputWriter, tra
 }
 
 // finalizeFetchResults saves state and metadata after completing the fetch.
-func finalizeFetchResults(owner, repo string, progress *progressTracker, tracker *metadata.Tracker, metadataFile string, opts github.FetchOptions) error {
+// The saved state replaces the run's checkpoint.
+func finalizeFetchResults(owner, repo string, progress *progressTracker, tracker *metadata.Tracker, metadataFile string, opts github.FetchOptions, run *checkpointer) error {
 	// Final message
 	fmt.Fprintf(os.Stderr, "\r\033[K") // Clear progress line
 	elapsed := time.Since(progress.startTime)
@@ -580,39 +1038,36 @@ func finalizeFetchResults(owner, repo string, progress *progressTracker, tracker
 	// Save state if we fetched any PRs
 	if progress.allPRsProcessed > 0 && progress.lastPRNumber > 0 {
 		repoPath := fmt.Sprintf("%s/%s", owner, repo)
-		stateFile := state.GetStateFilePath(repoPath)
 
 		fetchState := &state.FetchState{
-			Repository:    repoPath,
-			LastFetchID:   fmt.Sprintf("full-%d", time.Now().Unix()),
-			LastPRNumber:  progress.lastPRNumber,
-			LastPRDate:    progress.lastPRDate,
-			LastFetchTime: time.Now().UTC(),
-			TotalFetched:  progress.allPRsProcessed,
+			Repository:       repoPath,
+			LastFetchID:      run.checkpoint.FetchID,
+			LastPRNumber:     progress.lastPRNumber,
+			LastPRDate:       progress.lastPRDate,
+			UpdatedWatermark: updatedWatermark(run.checkpoint.StartedAt),
+			LastFetchTime:    time.Now().UTC(),
+			TotalFetched:     progress.allPRsProcessed,
+			Filters:          filtersOf(opts),
 		}
 
-		if err := state.SaveState(fetchState, stateFile); err != nil {
+		if err := run.store.SaveState(fetchState); err != nil {
 			// Don't fail the fetch, just warn
 			fmt.Fprintf(os.Stderr, "Warning: failed to save state for incremental fetch: %v\n", err)
 		}
 
 		// Generate and save metadata
-		params := metadata.FetchParams{
-			Organization: owner,
-			Repository:   repo,
-			Since:        opts.Since,
-			Until:        opts.Until,
-			FetchAll:     true,
-			BatchSize:    progress.pageSize,
-		}
-
-		fetchMetadata := tracker.GenerateMetadata(version.Version, params, false, nil)
+		fetchMetadata := tracker.GenerateMetadata(version.Version, fullFetchParams(owner, repo, opts, progress.pageSize), false, nil)
 
 		// Save metadata
 		if err := saveMetadata(fetchMetadata, metadataFile); err != nil {
 			// Don't fail the fetch, just warn
 			fmt.Fprintf(os.Stderr, "Warning: failed to save fetch metadata: %v\n", err)
 		}
+		if err := run.store.SaveMetadata(fetchMetadata); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record fetch metadata in the state store: %v\n", err)
+		}
+	} else if err := run.clear(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to clear fetch checkpoint: %v\n", err)
 	}
 
 	return nil
@@ -662,19 +1117,102 @@ func fetchWithComplexityRetry(ctx context.Context, client github.Client, owner,
 	return nil, fmt.Errorf("failed after %d attempts to reduce query complexity", maxRetries)
 }
 
+// apiUsage is the rate limit usage and retry count of a client when a fetch
+// started. Pages report both over the client's lifetime, and sync and
+// organization fetches run on a shared client, so a fetch records the
+// difference.
+type apiUsage struct {
+	rateLimit github.RateLimitStats
+
+	// retries is the client's retry count when the fetch started, advanced
+	// as pages are recorded
+	retries int
+}
+
+// usageReporter is implemented by clients that track their rate limit usage
+// and retries.
+type usageReporter interface {
+	RateLimitStats() github.RateLimitStats
+	Retries() int
+}
+
+// startAPIUsage snapshots the usage of client at the start of a fetch.
+// Clients that do not track usage start from zero.
+func startAPIUsage(client github.Client) *apiUsage {
+	usage := &apiUsage{}
+	if reporter, ok := client.(usageReporter); ok {
+		usage.rateLimit = reporter.RateLimitStats()
+		usage.retries = reporter.Retries()
+	}
+	return usage
+}
+
+// since returns the part of the client's usage rl that was consumed after
+// the fetch started. Limits, remaining budgets and reset times are taken
+// from rl as they are.
+func (u *apiUsage) since(rl *github.RateLimitStats) metadata.RateLimitUsage {
+	start := u.rateLimit
+	usage := metadata.RateLimitUsage{
+		PointsUsed: rl.PointsUsed - start.PointsUsed,
+		Limit:      rl.Limit,
+		Remaining:  rl.Remaining,
+		ResetAt:    rl.ResetAt,
+		Waits:      rl.Waits - start.Waits,
+	}
+	if wait := rl.WaitTime - start.WaitTime; wait > 0 {
+		usage.WaitTime = wait.Round(time.Second).String()
+	}
+
+	used := make(map[string]int, len(start.Tokens))
+	for _, token := range start.Tokens {
+		used[token.Name] = token.PointsUsed
+	}
+	for _, token := range rl.Tokens {
+		usage.Tokens = append(usage.Tokens, metadata.TokenUsage{
+			Name:       token.Name,
+			PointsUsed: token.PointsUsed - used[token.Name],
+			Limit:      token.Limit,
+			Remaining:  token.Remaining,
+			ResetAt:    token.ResetAt,
+		})
+	}
+	return usage
+}
+
+// recordPageAPICalls records the API calls used to fetch a page, including
+// follow-up queries for nested connections. Pages that don't report a call
+// count are recorded as a single call. The client's rate limit usage since
+// the fetch started and the retries since the previous page are recorded
+// along with them.
+func recordPageAPICalls(tracker *metadata.Tracker, usage *apiUsage, page *github.PullRequestPage) {
+	if rl := page.RateLimit; rl != nil {
+		tracker.RecordRateLimit(usage.since(rl))
+	}
+	if page.Retries > usage.retries {
+		tracker.RecordRetries(page.Retries - usage.retries)
+		usage.retries = page.Retries
+	}
+
+	if page.APICalls > 0 {
+		tracker.AddAPICalls(page.APICalls)
+		return
+	}
+	tracker.IncrementAPICall()
+}
+
 // loadAndValidateIncrementalState loads the previous fetch state and validates it matches the current repository.
 // Returns the previous state or an error with appropriate user-friendly message.
-func loadAndValidateIncrementalState(stateFile, repoPath string) (*state.FetchState, error) {
-	prevState, err := state.LoadState(stateFile)
+func loadAndValidateIncrementalState(store state.StateStore, repoPath string) (*state.FetchState, error) {
+	prevState, err := store.LoadState(repoPath)
 	if err != nil {
-		if strings.Contains(err.Error(), "no previous fetch state found") {
+		if errors.Is(err, state.ErrNoState) {
 			return nil, fmt.Errorf("no previous fetch state found for %s. To start an incremental fetch, first run a full fetch without --incremental", repoPath)
 		}
 		if strings.Contains(err.Error(), "corrupted") {
-			return nil, fmt.Errorf("state file is corrupted. To recover: Delete '%s' and run again. Your previous data in the output file is safe", stateFile)
+			return nil, fmt.Errorf("state of %s in %s is corrupted. To recover: Run 'sirseer-relay state reset %s' and run again. Your previous data in the output file is safe", repoPath, store.Location(), repoPath)
 		}
 		if strings.Contains(err.Error(), "incompatible") {
-			return nil, fmt.Errorf("state file version is incompatible. This usually means the tool has been updated. To recover: Delete '%s' and run a full fetch", stateFile)
+			return nil, fmt.Errorf("%v: it was written by a newer version of sirseer-relay or cannot be migrated. To recover: Upgrade sirseer-relay, or run 'sirseer-relay state reset %s' and run a full fetch", err, repoPath)
 		}
 		return nil, fmt.Errorf("failed to load state: %w", err)
 	}
@@ -684,15 +1222,20 @@ func loadAndValidateIncrementalState(stateFile, repoPath string) (*state.FetchSt
 		return nil, fmt.Errorf("state file is for repository %s but current command is for %s", prevState.Repository, repoPath)
 	}
 
+	// Only a checkpoint exists when the first full fetch never finished
+	if prevState.Checkpoint != nil && prevState.LastFetchTime.IsZero() {
+		return nil, fmt.Errorf("the initial full fetch of %s was interrupted. Run with --resume to finish it before fetching incrementally", repoPath)
+	}
+
 	return prevState, nil
 }
 
 // prepareIncrementalMetadata loads previous metadata and prepares tracking for the new fetch.
 // Returns the metadata tracker and previous fetch reference.
-func prepareIncrementalMetadata(stateDir, repoPath string) (*metadata.Tracker, *metadata.FetchRef) {
+func prepareIncrementalMetadata(store state.StateStore, repoPath string) (*metadata.Tracker, *metadata.FetchRef) {
 	tracker := metadata.New()
 
-	previousMetadata, err := metadata.LoadLatestMetadata(stateDir, repoPath)
+	previousMetadata, err := store.LoadLatestMetadata(repoPath)
 	if err != nil {
 		// Log warning but continue - metadata is optional
 		fmt.Fprintf(os.Stderr, "Warning: failed to load previous metadata: %v\n", err)
@@ -710,20 +1253,25 @@ func prepareIncrementalMetadata(stateDir, repoPath string) (*metadata.Tracker, *
 }
 
 // processIncrementalPR processes a single PR during incremental fetch.
-// Returns true if the PR was new and written, false if it was skipped.
+// PRs numbered above the previous fetch's highest PR are written as new;
+// all others changed since the previous fetch and are written as updated.
+// Returns true if the PR was new.
 func processIncrementalPR(pr *github.PullRequest, prevState, currentState *state.FetchState, writer output.OutputWriter, tracker *metadata.Tracker) (bool, error) {
-	// Skip PRs we've already seen (based on PR number)
-	if pr.Number <= prevState.LastPRNumber {
-		return false, nil
+	isNew := pr.Number > prevState.LastPRNumber
+
+	record := *pr
+	record.ChangeType = github.ChangeUpdated
+	if isNew {
+		record.ChangeType = github.ChangeNew
 	}
 
-	// Write new PR
-	if err := writer.Write(pr); err != nil {
+	if err := writer.Write(record); err != nil {
 		return false, fmt.Errorf("failed to write PR: %w", err)
 	}
 
 	// Update metadata tracker
 	tracker.UpdatePRStats(pr.Number, pr.CreatedAt, pr.UpdatedAt)
+	tracker.RecordChange(isNew)
 
 	// Update state tracking
 	if pr.Number > currentState.LastPRNumber {
@@ -733,68 +1281,95 @@ func processIncrementalPR(pr *github.PullRequest, prevState, currentState *state
 		currentState.LastPRDate = pr.CreatedAt
 	}
 
-	return true, nil
+	return isNew, nil
+}
+
+// incrementalParams returns the parameters recorded in the metadata of an
+// incremental fetch.
+func incrementalParams(owner, repo string, opts github.FetchOptions, fetchAll bool, pageSize int) metadata.FetchParams {
+	return metadata.FetchParams{
+		Organization: owner,
+		Repository:   repo,
+		Since:        opts.Since,
+		Until:        opts.Until,
+		FetchAll:     fetchAll,
+		BatchSize:    pageSize,
+		DateField:    opts.DateField,
+		Qualifiers:   opts.Qualifiers,
+		ExcludeBots:  opts.ExcludeBots,
+	}
 }
 
 // saveIncrementalResults saves the state and metadata after an incremental fetch.
-func saveIncrementalResults(currentState *state.FetchState, stateFile string, newPRCount int, tracker *metadata.Tracker, metadataFile, owner, repo string, opts github.FetchOptions, fetchAll bool, pageSize int, previousFetch *metadata.FetchRef) error {
+func saveIncrementalResults(currentState *state.FetchState, store state.StateStore, prCount int, tracker *metadata.Tracker, metadataFile, owner, repo string, opts github.FetchOptions, fetchAll bool, pageSize int, previousFetch *metadata.FetchRef) error {
 	// Update final state
-	currentState.TotalFetched = newPRCount
+	currentState.TotalFetched = prCount
 
 	// Save state
-	if err := state.SaveState(currentState, stateFile); err != nil {
+	if err := store.SaveState(currentState); err != nil {
 		return fmt.Errorf("failed to save state: %w", err)
 	}
 
 	// Generate and save metadata if we fetched any PRs
-	if newPRCount > 0 {
-		params := metadata.FetchParams{
-			Organization: owner,
-			Repository:   repo,
-			Since:        opts.Since,
-			Until:        opts.Until,
-			FetchAll:     fetchAll,
-			BatchSize:    pageSize,
-		}
-
-		fetchMetadata := tracker.GenerateMetadata(version.Version, params, true, previousFetch)
+	if prCount > 0 {
+		fetchMetadata := tracker.GenerateMetadata(version.Version, incrementalParams(owner, repo, opts, fetchAll, pageSize), true, previousFetch)
 
 		if err := saveMetadata(fetchMetadata, metadataFile); err != nil {
 			// Don't fail the fetch, just warn
 			fmt.Fprintf(os.Stderr, "Warning: failed to save fetch metadata: %v\n", err)
 		}
+		if err := store.SaveMetadata(fetchMetadata); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record fetch metadata in the state store: %v\n", err)
+		}
 	}
 
 	return nil
 }
 
 // fetchIncremental handles incremental fetching by loading previous state and resuming.
-func fetchIncremental(ctx context.Context, client github.Client, owner, repo string, writer output.OutputWriter, metadataFile string, sinceTime, untilTime *time.Time, fetchAll bool) error {
+func fetchIncremental(ctx context.Context, client github.Client, owner, repo string, writer output.OutputWriter, metadataFile string, store state.StateStore, sinceTime, untilTime *time.Time, filters *state.SearchFilters, fetchAll bool) error {
 	repoPath := fmt.Sprintf("%s/%s", owner, repo)
-	stateFile := state.GetStateFilePath(repoPath)
+
+	if filters != nil && filters.DateField == github.DateFieldUpdated {
+		return fmt.Errorf("--date-field updated cannot be used with --incremental, which already selects PRs by update time")
+	}
 
 	// Load and validate previous state
-	prevState, err := loadAndValidateIncrementalState(stateFile, repoPath)
+	prevState, err := loadAndValidateIncrementalState(store, repoPath)
 	if err != nil {
 		return err
 	}
 
+	// Mixing filters would leave PRs selected by only one of them half updated
+	if !prevState.Filters.Equal(filters) {
+		return fmt.Errorf("the previous fetch of %s used %s but this one uses %s. Incremental fetches must use the same filters; run a full fetch with --all to change them",
+			repoPath, prevState.Filters, filters)
+	}
+
 	// Prepare incremental fetch context
-	fetchCtx, err := prepareIncrementalFetch(prevState, repoPath, sinceTime, untilTime)
+	fetchCtx, err := prepareIncrementalFetch(prevState, store, repoPath, sinceTime, untilTime, filters)
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintf(os.Stderr, "Resuming from PR #%d (created %s)\n", prevState.LastPRNumber, prevState.LastPRDate.Format("2006-01-02"))
+	fmt.Fprintf(os.Stderr, "Fetching PRs updated since %s (last seen PR #%d)\n", fetchCtx.opts.UpdatedSince.Format(time.RFC3339), prevState.LastPRNumber)
 
 	// Perform the incremental fetch
-	newPRCount, err := performIncrementalFetch(ctx, client, owner, repo, writer, prevState, fetchCtx, fetchAll)
+	prCount, err := performIncrementalFetch(ctx, client, owner, repo, writer, prevState, fetchCtx)
 	if err != nil {
+		// The watermark only moves once every changed PR is written, so the
+		// state is left as it was and the next run fetches the changes again
+		if ctx.Err() != nil {
+			fmt.Fprintf(os.Stderr, "\r\033[K")
+			fmt.Fprintf(os.Stderr, "Incremental fetch interrupted after %d PRs. Run it again to fetch every change since the last completed fetch.\n", prCount)
+			fetchMetadata := fetchCtx.tracker.GenerateMetadata(version.Version, incrementalParams(owner, repo, fetchCtx.opts, fetchAll, fetchCtx.pageSize), true, fetchCtx.previousFetch)
+			saveInterruptedMetadata(fetchMetadata, metadataFile, store)
+		}
 		return err
 	}
 
 	// Save state and metadata
-	return saveIncrementalResults(fetchCtx.currentState, stateFile, newPRCount, fetchCtx.tracker, metadataFile, owner, repo, fetchCtx.opts, fetchAll, fetchCtx.pageSize, fetchCtx.previousFetch)
+	return saveIncrementalResults(fetchCtx.currentState, store, prCount, fetchCtx.tracker, metadataFile, owner, repo, fetchCtx.opts, fetchAll, fetchCtx.pageSize, fetchCtx.previousFetch)
 }
 
 // incrementalFetchContext holds the context needed for an incremental fetch.
@@ -807,30 +1382,39 @@ type incrementalFetchContext struct {
 }
 
 // prepareIncrementalFetch sets up the context for an incremental fetch operation.
-func prepareIncrementalFetch(prevState *state.FetchState, repoPath string, sinceTime, untilTime *time.Time) (*incrementalFetchContext, error) {
-	// Use the last PR date as the starting point if not specified
-	if sinceTime == nil {
-		sinceTime = &prevState.LastPRDate
+// The fetch selects every PR updated since the previous fetch's watermark;
+// sinceTime and untilTime further restrict PRs by creation date, or by the
+// date field of filters, which narrow the selection like in a full fetch.
+func prepareIncrementalFetch(prevState *state.FetchState, store state.StateStore, repoPath string, sinceTime, untilTime *time.Time, filters *state.SearchFilters) (*incrementalFetchContext, error) {
+	startedAt := time.Now().UTC()
+
+	// State files written before update tracking have no watermark; the
+	// newest creation date is a safe lower bound for changes not yet seen
+	watermark := prevState.UpdatedWatermark
+	if watermark == nil {
+		watermark = &prevState.LastPRDate
 	}
 
 	// Build fetch options
-	opts := github.FetchOptions{
-		Since: sinceTime,
-		Until: untilTime,
-	}
+	opts := withFilters(github.FetchOptions{
+		Since:        sinceTime,
+		Until:        untilTime,
+		UpdatedSince: watermark,
+	}, filters)
 
 	// Prepare metadata tracking
-	stateDir := filepath.Dir(state.GetStateFilePath(repoPath))
-	tracker, previousFetch := prepareIncrementalMetadata(stateDir, repoPath)
+	tracker, previousFetch := prepareIncrementalMetadata(store, repoPath)
 
 	// Track state for this fetch
 	currentState := &state.FetchState{
-		Repository:    repoPath,
-		LastFetchID:   fmt.Sprintf("inc-%d", time.Now().Unix()),
-		LastPRNumber:  prevState.LastPRNumber,
-		LastPRDate:    prevState.LastPRDate,
-		LastFetchTime: time.Now().UTC(),
-		TotalFetched:  0,
+		Repository:       repoPath,
+		LastFetchID:      fmt.Sprintf("inc-%d", startedAt.Unix()),
+		LastPRNumber:     prevState.LastPRNumber,
+		LastPRDate:       prevState.LastPRDate,
+		UpdatedWatermark: updatedWatermark(startedAt),
+		LastFetchTime:    startedAt,
+		TotalFetched:     0,
+		Filters:          filters,
 	}
 
 	return &incrementalFetchContext{
@@ -843,49 +1427,108 @@ func prepareIncrementalFetch(prevState *state.FetchState, repoPath string, since
 }
 
 // performIncrementalFetch executes the main fetch loop for incremental updates.
-func performIncrementalFetch(ctx context.Context, client github.Client, owner, repo string, writer output.OutputWriter, prevState *state.FetchState, fetchCtx *incrementalFetchContext, fetchAll bool) (int, error) {
+// Every page is fetched, since the new watermark is only valid once all PRs
+// updated after the previous one have been written. Windows holding more
+// PRs than search returns are split by date like in a full fetch, and a
+// fetch that would still miss PRs fails before the watermark moves.
+// Returns the number of PRs written, also when the fetch fails.
+func performIncrementalFetch(ctx context.Context, client github.Client, owner, repo string, writer output.OutputWriter, prevState *state.FetchState, fetchCtx *incrementalFetchContext) (int, error) {
 	var (
-		hasMore    = true
-		cursor     = ""
-		pageNum    = 0
-		newPRCount = 0
+		hasMore      = true
+		cursor       = ""
+		pageNum      = 0
+		newPRCount   = 0
+		updatedCount = 0
+		slice        *github.TimeRange
+		slicer       *windowSlicer
 	)
+	usage := startAPIUsage(client)
+
+	// nextSlice moves on to the start of the next date slice
+	nextSlice := func() error {
+		next, queries, err := slicer.nextSlice(ctx)
+		fetchCtx.tracker.AddAPICalls(queries)
+		if err != nil {
+			return err
+		}
+		slice, cursor, hasMore = &next, "", true
+		return nil
+	}
 
 	for hasMore {
 		pageNum++
-		pageOpts := github.FetchOptions{
-			PageSize: fetchCtx.pageSize,
-			After:    cursor,
-			Since:    fetchCtx.opts.Since,
-			Until:    fetchCtx.opts.Until,
-		}
+		pageOpts := fetchCtx.opts
+		pageOpts.PageSize = fetchCtx.pageSize
+		pageOpts.After = cursor
+		pageOpts.DateRange = slice
 
 		// Fetch page
 		page, err := fetchWithComplexityRetry(ctx, client, owner, repo, pageOpts, &fetchCtx.pageSize)
 		if err != nil {
-			return 0, err
+			return newPRCount + updatedCount, err
 		}
 
-		// Track API call
-		fetchCtx.tracker.IncrementAPICall()
+		// Track API calls
+		recordPageAPICalls(fetchCtx.tracker, usage, page)
 
-		// Process PRs with deduplication
-		for _, pr := range page.PullRequests {
-			isNew, err := processIncrementalPR(&pr, prevState, fetchCtx.currentState, writer, fetchCtx.tracker)
+		// Search only returns the first SearchResultCap PRs of a window. The
+		// PRs are sorted by update time, so the oldest creation date is not
+		// known and slicing starts at --since or the launch of GitHub.
+		if cursor == "" && page.TotalCount >= searchResultCap {
+			if slice != nil {
+				return newPRCount + updatedCount, fmt.Errorf("%d changed pull requests fall in the %s-date slice starting %s, more than search returns; the state was not updated",
+					page.TotalCount, dateFieldName(fetchCtx.opts), slice.Start.Format(time.RFC3339))
+			}
+			start, end := slicingRange(fetchCtx.opts, githubLaunch, fetchCtx.currentState.LastFetchTime)
+			slicer = newWindowSlicer(client, owner, repo, fetchCtx.opts, start, end)
+			fmt.Fprintf(os.Stderr, "%d pull requests changed, more than search returns at once; fetching in %s-date slices\n", page.TotalCount, dateFieldName(fetchCtx.opts))
+			if err := nextSlice(); err != nil {
+				return newPRCount + updatedCount, err
+			}
+			continue
+		}
+
+		// Write PRs marked as new or updated
+		for i := range page.PullRequests {
+			isNew, err := processIncrementalPR(&page.PullRequests[i], prevState, fetchCtx.currentState, writer, fetchCtx.tracker)
 			if err != nil {
-				return 0, err
+				return newPRCount + updatedCount, err
 			}
 			if isNew {
 				newPRCount++
+			} else {
+				updatedCount++
 			}
 		}
 
 		cursor = page.EndCursor
-		hasMore = page.HasNextPage && (fetchAll || newPRCount == 0)
+		hasMore = page.HasNextPage
+
+		// Move on to the next slice once this one is exhausted
+		if !hasMore && slicer != nil && !slicer.done() {
+			if err := nextSlice(); err != nil {
+				return newPRCount + updatedCount, err
+			}
+		}
 	}
 
-	fmt.Fprintf(os.Stderr, "Successfully fetched %d new pull requests\n", newPRCount)
-	return newPRCount, nil
+	fmt.Fprintf(os.Stderr, "Successfully fetched %d new and %d updated pull requests\n", newPRCount, updatedCount)
+	return newPRCount + updatedCount, nil
+}
+
+// updatedWatermarkSkew is subtracted from a fetch's start time when it
+// becomes the next updated-at watermark. It covers clock differences
+// between this machine and GitHub; PRs in the overlap are re-emitted as
+// updated rather than missed.
+const updatedWatermarkSkew = time.Minute
+
+// updatedWatermark returns the updated-at watermark for a fetch that
+// started at startedAt. Every PR updated after that point is picked up by
+// the next incremental fetch, including PRs that changed while the fetch
+// was running.
+func updatedWatermark(startedAt time.Time) *time.Time {
+	watermark := startedAt.UTC().Add(-updatedWatermarkSkew).Truncate(time.Second)
+	return &watermark
 }
 
 // updateProgress displays a real-time progress indicator with percentage completion and ETA.
@@ -927,8 +1570,9 @@ func updateProgress(current, total, pageNum int, startTime time.Time) {
 // This provides meaningful exit codes for scripting and automation:
 //   - 0: Success (no error)
 //   - 1: General error
-//   - 2: Authentication/authorization errors (invalid token, repo not found, rate limit)
+//   - 2: Authentication/authorization errors (invalid token, repo or PR not found, rate limit)
 //   - 3: Network errors
+//   - 4: Partial fetch (stopped by --max-duration; continue with --resume)
 func mapErrorToExitCode(err error) int {
 	if err == nil {
 		return 0
@@ -937,6 +1581,7 @@ func mapErrorToExitCode(err error) int {
 	// Check for specific error types
 	if errors.Is(err, relaierrors.ErrInvalidToken) ||
 		errors.Is(err, relaierrors.ErrRepoNotFound) ||
+		errors.Is(err, relaierrors.ErrPullRequestNotFound) ||
 		errors.Is(err, relaierrors.ErrRateLimit) {
 		return 2 // Authentication/authorization errors
 	}
@@ -945,5 +1590,9 @@ func mapErrorToExitCode(err error) int {
 		return 3 // Network errors
 	}
 
+	if errors.Is(err, relaierrors.ErrPartialFetch) {
+		return 4 // Partial fetch
+	}
+
 	return 1 // General error
 }
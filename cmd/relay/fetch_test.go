@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,7 +23,9 @@ import (
 	"time"
 
 	"github.com/sirseerhq/sirseer-relay/internal/config"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
 	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+	"github.com/sirseerhq/sirseer-relay/internal/output"
 )
 
 func TestParseRepository(t *testing.T) {
@@ -139,6 +142,60 @@ func TestParseDate(t *testing.T) {
 	}
 }
 
+func TestParseDateFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		since     string
+		until     string
+		wantSince *time.Time
+		wantUntil *time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "dates cover whole days",
+			since:     "2024-01-01",
+			until:     "2024-01-31",
+			wantSince: timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			wantUntil: timePtr(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:      "timestamps are exact",
+			since:     "2024-01-01T09:30:00Z",
+			until:     "2024-01-01T17:00:00.5Z",
+			wantSince: timePtr(time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)),
+			wantUntil: timePtr(time.Date(2024, 1, 1, 17, 0, 0, 500*int(time.Millisecond), time.UTC)),
+		},
+		{
+			name:      "same day",
+			since:     "2024-01-01",
+			until:     "2024-01-01",
+			wantSince: timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			wantUntil: timePtr(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:    "empty window",
+			since:   "2024-01-01T12:00:00Z",
+			until:   "2024-01-01T12:00:00Z",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, until, err := parseDateFlags(tt.since, tt.until)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDateFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !since.Equal(*tt.wantSince) || !until.Equal(*tt.wantUntil) {
+				t.Errorf("parseDateFlags() = [%v, %v), want [%v, %v)", since, until, tt.wantSince, tt.wantUntil)
+			}
+		})
+	}
+}
+
 func TestGetToken(t *testing.T) {
 	// Save current env
 	oldToken := os.Getenv("GITHUB_TOKEN")
@@ -212,6 +269,16 @@ func TestMapErrorToExitCode(t *testing.T) {
 			err:      os.ErrClosed,
 			wantCode: 1,
 		},
+		{
+			name:     "partial fetch",
+			err:      maxDurationError(time.Minute, fetchRunOptions{fetchAll: true}, context.DeadlineExceeded),
+			wantCode: 4,
+		},
+		{
+			name:     "max duration without checkpoint",
+			err:      maxDurationError(time.Minute, fetchRunOptions{}, context.DeadlineExceeded),
+			wantCode: 1,
+		},
 		// Add more test cases for specific error types when available
 	}
 
@@ -307,3 +374,76 @@ func TestMetadataIntegration(t *testing.T) {
 		t.Errorf("Loaded FetchID = %s, want %s", loaded.FetchID, meta.FetchID)
 	}
 }
+
+func TestNewGitHubClient_UsesConfiguredEndpoint(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.GitHub.GraphQLEndpoint = "https://github.example.com/api/graphql"
+
+	client := newGitHubClient([]github.PooledToken{{Source: github.StaticToken("test-token")}}, cfg, 0)
+	if client.Endpoint() != cfg.GitHub.GraphQLEndpoint {
+		t.Errorf("Endpoint() = %s, want %s", client.Endpoint(), cfg.GitHub.GraphQLEndpoint)
+	}
+}
+
+func TestResolveOutputMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    fetchRunOptions
+		want    output.Mode
+		wantErr string
+	}{
+		{
+			name: "default overwrites",
+			opts: fetchRunOptions{outputFile: "prs.ndjson"},
+			want: output.ModeOverwrite,
+		},
+		{
+			name: "incremental appends by default",
+			opts: fetchRunOptions{outputFile: "prs.ndjson", incremental: true},
+			want: output.ModeAppend,
+		},
+		{
+			name: "incremental to generated file overwrites",
+			opts: fetchRunOptions{incremental: true},
+			want: output.ModeOverwrite,
+		},
+		{
+			name: "explicit upsert",
+			opts: fetchRunOptions{outputFile: "prs.ndjson", outputMode: "upsert", incremental: true},
+			want: output.ModeUpsert,
+		},
+		{
+			name:    "append requires output file",
+			opts:    fetchRunOptions{outputFile: "-", outputMode: "append"},
+			wantErr: "requires --output",
+		},
+		{
+			name:    "upsert rejected with --all",
+			opts:    fetchRunOptions{outputFile: "prs.ndjson", outputMode: "upsert", fetchAll: true},
+			wantErr: "cannot be used with --all",
+		},
+		{
+			name:    "unknown mode",
+			opts:    fetchRunOptions{outputFile: "prs.ndjson", outputMode: "merge"},
+			wantErr: "invalid output mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveOutputMode(tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("mode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
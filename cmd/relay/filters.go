@@ -0,0 +1,159 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// githubLaunch is the earliest date any pull request can carry. Windows on
+// the merged or closed date start here when no --since is given, since
+// search cannot sort by those dates to find the oldest one.
+var githubLaunch = time.Date(2008, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// searchFilterFlags holds the fetch flags that narrow which pull requests
+// are selected.
+type searchFilterFlags struct {
+	query       string
+	state       string
+	base        string
+	labels      []string
+	author      string
+	excludeBots bool
+	draft       bool
+	dateField   string
+}
+
+// addSearchFilterFlags defines the search filter flags on cmd.
+func addSearchFilterFlags(cmd *cobra.Command, f *searchFilterFlags) {
+	cmd.Flags().StringVar(&f.query, "query", "", "Extra GitHub search qualifiers added to the query, e.g. 'review:approved -label:wontfix'")
+	cmd.Flags().StringVar(&f.state, "state", "", "Only fetch PRs in this state: open, closed (not merged) or merged")
+	cmd.Flags().StringVar(&f.base, "base", "", "Only fetch PRs targeting this base branch")
+	cmd.Flags().StringSliceVar(&f.labels, "label", nil, "Only fetch PRs with this label (repeatable; all must match)")
+	cmd.Flags().StringVar(&f.author, "author", "", "Only fetch PRs opened by this user")
+	cmd.Flags().BoolVar(&f.excludeBots, "exclude-bots", false, "Leave out PRs opened by bots such as dependabot[bot]")
+	cmd.Flags().BoolVar(&f.draft, "draft", false, "Only fetch draft PRs; use --draft=false for ready PRs only")
+	cmd.Flags().StringVar(&f.dateField, "date-field", "", "Date that --since and --until apply to: created, updated, merged or closed (default: created)")
+}
+
+// build validates the filter flags and returns the filters they describe,
+// or nil when none were given. draftSet reports whether --draft was passed.
+func (f searchFilterFlags) build(draftSet bool) (*state.SearchFilters, error) {
+	var qualifiers []string
+
+	switch f.state {
+	case "":
+	case "open":
+		qualifiers = append(qualifiers, "is:open")
+	case "closed":
+		qualifiers = append(qualifiers, "is:closed", "is:unmerged")
+	case "merged":
+		qualifiers = append(qualifiers, "is:merged")
+	default:
+		return nil, fmt.Errorf("invalid --state %q: must be open, closed or merged", f.state)
+	}
+
+	if f.base != "" {
+		qualifiers = append(qualifiers, searchQualifier("base", f.base))
+	}
+	for _, label := range f.labels {
+		qualifiers = append(qualifiers, searchQualifier("label", label))
+	}
+	if f.author != "" {
+		qualifiers = append(qualifiers, searchQualifier("author", f.author))
+	}
+	if draftSet {
+		qualifiers = append(qualifiers, fmt.Sprintf("draft:%t", f.draft))
+	}
+	if query := strings.TrimSpace(f.query); query != "" {
+		qualifiers = append(qualifiers, query)
+	}
+
+	switch f.dateField {
+	case "", github.DateFieldCreated, github.DateFieldUpdated, github.DateFieldMerged, github.DateFieldClosed:
+	default:
+		return nil, fmt.Errorf("invalid --date-field %q: must be created, updated, merged or closed", f.dateField)
+	}
+
+	filters := &state.SearchFilters{
+		Qualifiers:  strings.Join(qualifiers, " "),
+		DateField:   f.dateField,
+		ExcludeBots: f.excludeBots,
+	}
+	if filters.Equal(nil) {
+		return nil, nil
+	}
+	return filters, nil
+}
+
+// searchQualifier formats a qualifier, quoting values that contain spaces.
+func searchQualifier(name, value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return fmt.Sprintf("%s:%q", name, value)
+	}
+	return name + ":" + value
+}
+
+// withFilters returns opts narrowed by filters.
+func withFilters(opts github.FetchOptions, filters *state.SearchFilters) github.FetchOptions {
+	if filters != nil {
+		opts.Qualifiers = filters.Qualifiers
+		opts.DateField = filters.DateField
+		opts.ExcludeBots = filters.ExcludeBots
+	}
+	return opts
+}
+
+// filtersOf returns the filters opts was narrowed by, or nil when it was
+// not, for recording in state files.
+func filtersOf(opts github.FetchOptions) *state.SearchFilters {
+	filters := &state.SearchFilters{
+		Qualifiers:  opts.Qualifiers,
+		DateField:   opts.DateField,
+		ExcludeBots: opts.ExcludeBots,
+	}
+	if filters.Equal(nil) {
+		return nil
+	}
+	return filters
+}
+
+// sliceOrigin returns the earliest date a window that hit the search result
+// cap can start at when no --since is given: the date of the first PR
+// returned, which is the oldest when search sorts by the window's date
+// field, or otherwise the launch of GitHub.
+func sliceOrigin(opts github.FetchOptions, prs []github.PullRequest) time.Time {
+	switch opts.DateField {
+	case "", github.DateFieldCreated, github.DateFieldUpdated:
+		if len(prs) > 0 {
+			return *github.DateOf(&prs[0], opts.DateField)
+		}
+	}
+	return githubLaunch
+}
+
+// dateFieldName returns the name of the date a window of opts applies to.
+func dateFieldName(opts github.FetchOptions) string {
+	if opts.DateField == "" {
+		return github.DateFieldCreated
+	}
+	return opts.DateField
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/output"
+	"github.com/sirseerhq/sirseer-relay/internal/state"
+)
+
+func TestSearchFilterFlags_Build(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    searchFilterFlags
+		draftSet bool
+		want     *state.SearchFilters
+		wantErr  string
+	}{
+		{
+			name: "no filters",
+			want: nil,
+		},
+		{
+			name:  "default date field alone is no filter",
+			flags: searchFilterFlags{dateField: "created"},
+			want:  nil,
+		},
+		{
+			name:  "closed excludes merged",
+			flags: searchFilterFlags{state: "closed"},
+			want:  &state.SearchFilters{Qualifiers: "is:closed is:unmerged"},
+		},
+		{
+			name:     "common filters and raw query",
+			flags:    searchFilterFlags{state: "merged", base: "main", labels: []string{"bug", "needs review"}, author: "octocat", query: "  review:approved ", dateField: "merged"},
+			draftSet: true,
+			want: &state.SearchFilters{
+				Qualifiers: `is:merged base:main label:bug label:"needs review" author:octocat draft:false review:approved`,
+				DateField:  "merged",
+			},
+		},
+		{
+			name:  "exclude bots",
+			flags: searchFilterFlags{excludeBots: true},
+			want:  &state.SearchFilters{ExcludeBots: true},
+		},
+		{
+			name:    "unknown state",
+			flags:   searchFilterFlags{state: "draft"},
+			wantErr: "invalid --state",
+		},
+		{
+			name:    "unknown date field",
+			flags:   searchFilterFlags{dateField: "pushed"},
+			wantErr: "invalid --date-field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.flags.build(tt.draftSet)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filters = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSliceOrigin(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	merged := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	prs := []github.PullRequest{{Number: 1, CreatedAt: created, UpdatedAt: merged, MergedAt: &merged}}
+
+	tests := []struct {
+		name      string
+		dateField string
+		want      time.Time
+	}{
+		{name: "created date of the first PR", dateField: "", want: created},
+		{name: "updated date of the first PR", dateField: github.DateFieldUpdated, want: merged},
+		{name: "merged windows start at the launch of GitHub", dateField: github.DateFieldMerged, want: githubLaunch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sliceOrigin(github.FetchOptions{DateField: tt.dateField}, prs)
+			if !got.Equal(tt.want) {
+				t.Errorf("sliceOrigin = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchIncremental_RequiresSameFilters(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repoPath := "test/repo"
+
+	prevState := &state.FetchState{
+		Repository:    repoPath,
+		LastFetchID:   "full-1",
+		LastPRNumber:  3,
+		LastPRDate:    time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC),
+		LastFetchTime: time.Date(2024, 2, 1, 12, 1, 0, 0, time.UTC),
+		Filters:       &state.SearchFilters{Qualifiers: "base:main"},
+	}
+	if err := state.SaveState(prevState, state.GetStateFilePath(repoPath)); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		filters *state.SearchFilters
+		wantErr string
+	}{
+		{name: "same filters", filters: &state.SearchFilters{Qualifiers: "base:main"}},
+		{name: "filters dropped", filters: nil, wantErr: "must use the same filters"},
+		{name: "filters changed", filters: &state.SearchFilters{Qualifiers: "base:release"}, wantErr: "must use the same filters"},
+		{name: "updated date field", filters: &state.SearchFilters{DateField: github.DateFieldUpdated}, wantErr: "--date-field updated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := github.NewMockClientWithOptions(github.WithPullRequests(testPullRequests(3)))
+
+			var buf bytes.Buffer
+			metadataFile := filepath.Join(t.TempDir(), "metadata.json")
+			err := fetchIncremental(context.Background(), client, "test", "repo", output.NewWriter(&buf), metadataFile, state.NewFileStore(""), nil, nil, tt.filters, false)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetchIncremental failed: %v", err)
+			}
+			if client.LastOpts.Qualifiers != "base:main" {
+				t.Errorf("Qualifiers = %q, want %q", client.LastOpts.Qualifiers, "base:main")
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+	"github.com/sirseerhq/sirseer-relay/internal/output"
+	"github.com/sirseerhq/sirseer-relay/internal/state"
+)
+
+func TestFetchIncremental_EmitsNewAndUpdatedPRs(t *testing.T) {
+	tests := []struct {
+		name          string
+		watermark     *time.Time
+		wantUpdatedAt time.Time
+	}{
+		{
+			name:          "uses saved watermark",
+			watermark:     timePtr(time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)),
+			wantUpdatedAt: time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "falls back to last PR date for older state files",
+			watermark:     nil,
+			wantUpdatedAt: time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			repoPath := "test/repo"
+			stateFile := state.GetStateFilePath(repoPath)
+
+			prevState := &state.FetchState{
+				Repository:       repoPath,
+				LastFetchID:      "full-1",
+				LastPRNumber:     3,
+				LastPRDate:       time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC),
+				UpdatedWatermark: tt.watermark,
+				LastFetchTime:    time.Date(2024, 2, 1, 12, 1, 0, 0, time.UTC),
+			}
+			if err := state.SaveState(prevState, stateFile); err != nil {
+				t.Fatalf("failed to save state: %v", err)
+			}
+
+			// PRs 2 and 3 changed since the last fetch, 4 and 5 are new
+			client := github.NewMockClientWithOptions(github.WithPullRequests(testPullRequests(5)[1:]))
+
+			var buf bytes.Buffer
+			metadataFile := filepath.Join(t.TempDir(), "metadata.json")
+			started := time.Now().UTC()
+			if err := fetchIncremental(context.Background(), client, "test", "repo", output.NewWriter(&buf), metadataFile, state.NewFileStore(""), nil, nil, nil, false); err != nil {
+				t.Fatalf("fetchIncremental failed: %v", err)
+			}
+
+			if client.LastOpts.UpdatedSince == nil || !client.LastOpts.UpdatedSince.Equal(tt.wantUpdatedAt) {
+				t.Errorf("UpdatedSince = %v, want %v", client.LastOpts.UpdatedSince, tt.wantUpdatedAt)
+			}
+
+			want := map[int]string{2: github.ChangeUpdated, 3: github.ChangeUpdated, 4: github.ChangeNew, 5: github.ChangeNew}
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != len(want) {
+				t.Fatalf("expected %d records, got %d", len(want), len(lines))
+			}
+			for _, line := range lines {
+				var pr github.PullRequest
+				if err := json.Unmarshal([]byte(line), &pr); err != nil {
+					t.Fatalf("invalid record: %v", err)
+				}
+				if pr.ChangeType != want[pr.Number] {
+					t.Errorf("PR #%d change_type = %q, want %q", pr.Number, pr.ChangeType, want[pr.Number])
+				}
+			}
+
+			saved, err := state.LoadState(stateFile)
+			if err != nil {
+				t.Fatalf("failed to load state: %v", err)
+			}
+			if saved.LastPRNumber != 5 {
+				t.Errorf("LastPRNumber = %d, want 5", saved.LastPRNumber)
+			}
+			if saved.UpdatedWatermark == nil || saved.UpdatedWatermark.Before(started.Add(-updatedWatermarkSkew-time.Second)) || saved.UpdatedWatermark.After(started) {
+				t.Errorf("UpdatedWatermark = %v, want shortly before %v", saved.UpdatedWatermark, started)
+			}
+		})
+	}
+}
+
+func TestFetchIncremental_SQLiteStore(t *testing.T) {
+	store, err := state.OpenStore(state.BackendSQLite, t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+
+	fetched := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	prevState := &state.FetchState{
+		Repository:       "test/repo",
+		LastFetchID:      "full-1",
+		LastPRNumber:     3,
+		UpdatedWatermark: &fetched,
+		LastFetchTime:    fetched,
+	}
+	if err := store.SaveState(prevState); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+	fullFetch := &metadata.FetchMetadata{
+		FetchID:    "full-1",
+		Parameters: metadata.FetchParams{Organization: "test", Repository: "repo", FetchAll: true},
+		Results:    metadata.FetchResults{StartedAt: fetched.Add(-time.Minute), CompletedAt: fetched},
+	}
+	if err := store.SaveMetadata(fullFetch); err != nil {
+		t.Fatalf("SaveMetadata() error = %v", err)
+	}
+
+	client := github.NewMockClientWithOptions(github.WithPullRequests(testPullRequests(5)[1:]))
+	var buf bytes.Buffer
+	metadataFile := filepath.Join(t.TempDir(), "metadata.json")
+	if err := fetchIncremental(context.Background(), client, "test", "repo", output.NewWriter(&buf), metadataFile, store, nil, nil, nil, false); err != nil {
+		t.Fatalf("fetchIncremental failed: %v", err)
+	}
+
+	saved, err := store.LoadState("test/repo")
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if saved.LastPRNumber != 5 {
+		t.Errorf("LastPRNumber = %d, want 5", saved.LastPRNumber)
+	}
+
+	latest, err := store.LoadLatestMetadata("test/repo")
+	if err != nil || latest == nil {
+		t.Fatalf("LoadLatestMetadata() = %v, %v", latest, err)
+	}
+	if !latest.Incremental || latest.PreviousFetch == nil || latest.PreviousFetch.FetchID != "full-1" {
+		t.Errorf("latest metadata = %+v, want the incremental fetch linked to full-1", latest)
+	}
+}
+
+func TestFetchIncremental_SlicesPastResultCap(t *testing.T) {
+	originalCap := searchResultCap
+	searchResultCap = 10
+	defer func() { searchResultCap = originalCap }()
+
+	sameSecond := testPullRequests(12)
+	for i := range sameSecond {
+		sameSecond[i].CreatedAt = sameSecond[0].CreatedAt
+	}
+
+	tests := []struct {
+		name    string
+		prs     []github.PullRequest
+		wantErr string
+	}{
+		{name: "every changed PR is fetched", prs: testPullRequests(25)},
+		{name: "fails when a slice cannot be narrowed", prs: sameSecond, wantErr: "more than search returns"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := state.NewFileStore(t.TempDir())
+			prevState := &state.FetchState{
+				Repository:       "test/repo",
+				LastFetchID:      "full-1",
+				UpdatedWatermark: timePtr(time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)),
+				LastFetchTime:    time.Date(2023, 12, 1, 0, 1, 0, 0, time.UTC),
+			}
+			if err := store.SaveState(prevState); err != nil {
+				t.Fatalf("SaveState() error = %v", err)
+			}
+
+			client := github.NewMockClientWithOptions(
+				github.WithPullRequests(tt.prs),
+				github.WithPagination(5),
+				github.WithResultCap(10),
+			)
+			outputFile := filepath.Join(t.TempDir(), "prs.ndjson")
+			writer, err := output.NewFileWriter(outputFile)
+			if err != nil {
+				t.Fatalf("failed to create writer: %v", err)
+			}
+			metadataFile := filepath.Join(t.TempDir(), "metadata.json")
+			err = fetchIncremental(context.Background(), client, "test", "repo", writer, metadataFile, store, nil, nil, nil, false)
+			writer.Close()
+
+			saved, loadErr := store.LoadState("test/repo")
+			if loadErr != nil {
+				t.Fatalf("LoadState() error = %v", loadErr)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				if saved.LastFetchID != "full-1" {
+					t.Errorf("expected the state to be left alone, got fetch %s", saved.LastFetchID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetchIncremental failed: %v", err)
+			}
+
+			numbers := readPRNumbers(t, outputFile)
+			if len(numbers) != len(tt.prs) {
+				t.Fatalf("expected %d records, got %d: %v", len(tt.prs), len(numbers), numbers)
+			}
+			for i, n := range numbers {
+				if n != i+1 {
+					t.Fatalf("expected PRs 1-%d without duplicates, got %v", len(tt.prs), numbers)
+				}
+			}
+			if saved.LastPRNumber != len(tt.prs) {
+				t.Errorf("LastPRNumber = %d, want %d", saved.LastPRNumber, len(tt.prs))
+			}
+		})
+	}
+}
+
+func TestFetchIncremental_CancelledSavesInterruptedMetadata(t *testing.T) {
+	store := state.NewFileStore(t.TempDir())
+	prevState := &state.FetchState{
+		Repository:       "test/repo",
+		LastFetchID:      "full-1",
+		UpdatedWatermark: timePtr(time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)),
+		LastFetchTime:    time.Date(2023, 12, 1, 0, 1, 0, 0, time.UTC),
+	}
+	if err := store.SaveState(prevState); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var buf bytes.Buffer
+	writer := &cancellingWriter{Writer: output.NewWriter(&buf), cancel: cancel, after: 7}
+	// Incremental fetches ask for 50 PRs a page, so cancelling during the
+	// first page stops the fetch after it
+	client := github.NewMockClientWithOptions(github.WithPullRequests(testPullRequests(60)), github.WithPagination(50))
+	metadataFile := filepath.Join(t.TempDir(), "metadata.json")
+	if err := fetchIncremental(ctx, client, "test", "repo", writer, metadataFile, store, nil, nil, nil, false); err == nil {
+		t.Fatal("expected the cancelled fetch to fail")
+	}
+
+	saved, err := store.LoadState("test/repo")
+	if err != nil || saved.LastFetchID != "full-1" {
+		t.Errorf("expected the state of the last completed fetch to be kept, got %+v, %v", saved, err)
+	}
+
+	data, err := os.ReadFile(metadataFile) // #nosec G304 - test file under TempDir
+	if err != nil {
+		t.Fatalf("expected metadata for the interrupted fetch: %v", err)
+	}
+	var fetchMetadata metadata.FetchMetadata
+	if err := json.Unmarshal(data, &fetchMetadata); err != nil {
+		t.Fatalf("invalid metadata: %v", err)
+	}
+	if !fetchMetadata.Interrupted || !fetchMetadata.Incremental || fetchMetadata.Results.TotalPRs != 50 {
+		t.Errorf("expected interrupted incremental metadata for 50 PRs, got interrupted=%v incremental=%v total=%d",
+			fetchMetadata.Interrupted, fetchMetadata.Incremental, fetchMetadata.Results.TotalPRs)
+	}
+}
+
+// timePtr returns a pointer to t.
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
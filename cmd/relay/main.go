@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,7 +26,7 @@ import (
 // main is the entry point for the sirseer-relay CLI application.
 // It sets up the root command with version information and executes
 // the command tree. Exit codes are determined by the type of error
-// encountered during execution.
+// encountered during execution. SIGINT and SIGTERM stop a fetch cleanly.
 func main() {
 	var configFile string
 
@@ -50,9 +51,15 @@ Source available at: github.com/sirseerhq/sirseer-relay
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default is $HOME/.sirseer/config.yaml)")
 
-	rootCmd.AddCommand(newFetchCommand(configFile))
+	rootCmd.AddCommand(newFetchCommand(&configFile))
+	rootCmd.AddCommand(newSyncCommand(&configFile))
+	rootCmd.AddCommand(newStateCommand(&configFile))
 
-	if err := rootCmd.Execute(); err != nil {
+	// SIGINT and SIGTERM cancel the command's context so fetches stop cleanly
+	ctx, stop := withSignalCancel(context.Background(), os.Stderr)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(mapErrorToExitCode(err))
 	}
@@ -0,0 +1,126 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirseerhq/sirseer-relay/internal/config"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/spf13/cobra"
+)
+
+// isOrganizationArg reports whether a repository argument has the form
+// <org>/*, standing for every repository of an organization or user.
+func isOrganizationArg(repoArg string) bool {
+	owner, name, ok := strings.Cut(repoArg, "/")
+	return ok && owner != "" && name == "*"
+}
+
+// addRepositoryFilterFlags defines the flags that select the repositories
+// of an <org>/* argument.
+func addRepositoryFilterFlags(cmd *cobra.Command, filter *config.RepositoryFilterConfig) {
+	cmd.Flags().BoolVar(&filter.ExcludeArchived, "exclude-archived", false, "With <org>/*, skip archived repositories")
+	cmd.Flags().BoolVar(&filter.ExcludeForks, "exclude-forks", false, "With <org>/*, skip forked repositories")
+	cmd.Flags().StringVar(&filter.Visibility, "visibility", "", "With <org>/*, only fetch repositories with this visibility: public, private or internal")
+	cmd.Flags().StringSliceVar(&filter.Topics, "topic", nil, "With <org>/*, only fetch repositories with one of these topics (repeatable)")
+	cmd.Flags().StringVar(&filter.PushedSince, "pushed-since", "", "With <org>/*, only fetch repositories pushed to on or after this date (same formats as --since)")
+}
+
+// repositoryFilterFlags lists the flags defined by addRepositoryFilterFlags.
+var repositoryFilterFlags = []string{"exclude-archived", "exclude-forks", "visibility", "topic", "pushed-since"}
+
+// newRepositoryFilter converts filter settings from the config file or the
+// command line into a github.RepositoryFilter.
+func newRepositoryFilter(cfg config.RepositoryFilterConfig) (github.RepositoryFilter, error) {
+	filter := github.RepositoryFilter{
+		ExcludeArchived: cfg.ExcludeArchived,
+		ExcludeForks:    cfg.ExcludeForks,
+		Topics:          cfg.Topics,
+	}
+
+	switch visibility := strings.ToLower(cfg.Visibility); visibility {
+	case "", "public", "private", "internal":
+		filter.Visibility = visibility
+	default:
+		return filter, fmt.Errorf("invalid visibility %q: must be public, private or internal", cfg.Visibility)
+	}
+
+	if cfg.PushedSince != "" {
+		pushedSince, err := parseDate(cfg.PushedSince)
+		if err != nil {
+			return filter, fmt.Errorf("invalid pushed-since date format: %w", err)
+		}
+		filter.PushedSince = &pushedSince
+	}
+	return filter, nil
+}
+
+// runOrganizationFetch fetches every repository of the owner named by an
+// <org>/* argument that matches filter, one after another, with the options
+// of a single-repository fetch. Each repository is written to its own file
+// under <output-dir>/<org>/<repo>/. A failing repository does not stop the
+// others; a summary is printed at the end.
+func runOrganizationFetch(ctx context.Context, orgArg string, runOpts fetchRunOptions, cfg *config.Config, filter github.RepositoryFilter) error {
+	if runOpts.outputFile != "" {
+		return fmt.Errorf("--output cannot be used with %s; each repository is written to its own file under --output-dir", orgArg)
+	}
+	if runOpts.metadataFile != "" {
+		return fmt.Errorf("--metadata-file cannot be used with %s; each repository's metadata is saved next to its output", orgArg)
+	}
+	if runOpts.resume {
+		return fmt.Errorf("--resume cannot be used with %s; use the sync command to continue interrupted fetches", orgArg)
+	}
+	if len(runOpts.prNumbers) > 0 {
+		return fmt.Errorf("--pr cannot be used with %s; pull request numbers belong to a single repository", orgArg)
+	}
+
+	owner, _, _ := strings.Cut(orgArg, "/")
+	creds, err := newCredentials(ctx, runOpts.token, cfg, owner, runOpts.requestTimeout)
+	if err != nil {
+		return err
+	}
+	return fetchOrganization(ctx, newGitHubClient(creds, cfg, runOpts.requestTimeout), orgArg, runOpts, filter)
+}
+
+// fetchOrganization fetches the repositories of the owner named by orgArg
+// that match filter with client.
+func fetchOrganization(ctx context.Context, client github.Client, orgArg string, runOpts fetchRunOptions, filter github.RepositoryFilter) error {
+	mode := ""
+	switch {
+	case runOpts.incremental:
+		mode = syncModeIncremental
+	case runOpts.fetchAll:
+		mode = syncModeFull
+	}
+
+	summary := runSync(ctx, sharedClient(client), []string{orgArg}, filter, 1, func(ctx context.Context, repoPath string) (string, error) {
+		repoOwner, repo, err := parseRepository(repoPath)
+		if err != nil {
+			return mode, err
+		}
+		return mode, fetchRepository(ctx, client, repoOwner, repo, runOpts)
+	})
+
+	if len(summary.Repositories) == 0 {
+		owner, _, _ := strings.Cut(orgArg, "/")
+		return fmt.Errorf("no repositories of %s match the given filters", owner)
+	}
+	summary.print(os.Stderr)
+	return summary.err
+}
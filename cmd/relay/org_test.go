@@ -0,0 +1,149 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/config"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+)
+
+func TestNewRepositoryFilter(t *testing.T) {
+	pushedSince := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		cfg     config.RepositoryFilterConfig
+		want    github.RepositoryFilter
+		wantErr string
+	}{
+		{
+			name: "empty filter",
+			want: github.RepositoryFilter{},
+		},
+		{
+			name: "all settings",
+			cfg:  config.RepositoryFilterConfig{ExcludeArchived: true, ExcludeForks: true, Visibility: "Private", Topics: []string{"go"}, PushedSince: "2024-01-15"},
+			want: github.RepositoryFilter{ExcludeArchived: true, ExcludeForks: true, Visibility: "private", Topics: []string{"go"}, PushedSince: &pushedSince},
+		},
+		{
+			name:    "unknown visibility",
+			cfg:     config.RepositoryFilterConfig{Visibility: "secret"},
+			wantErr: "invalid visibility",
+		},
+		{
+			name:    "invalid pushed-since",
+			cfg:     config.RepositoryFilterConfig{PushedSince: "last tuesday"},
+			wantErr: "invalid pushed-since date format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newRepositoryFilter(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunOrganizationFetch_RejectsSingleRepositoryOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    fetchRunOptions
+		wantErr string
+	}{
+		{"output file", fetchRunOptions{outputFile: "prs.ndjson"}, "--output cannot be used with acme/*"},
+		{"metadata file", fetchRunOptions{metadataFile: "meta.json"}, "--metadata-file cannot be used with acme/*"},
+		{"resume", fetchRunOptions{resume: true}, "--resume cannot be used with acme/*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runOrganizationFetch(context.Background(), "acme/*", tt.opts, config.DefaultConfig(), github.RepositoryFilter{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunSync_AppliesRepositoryFilter(t *testing.T) {
+	client := github.NewMockClientWithOptions(github.WithRepositories("acme",
+		github.Repository{Owner: "acme", Name: "api"},
+		github.Repository{Owner: "acme", Name: "old", IsArchived: true},
+		github.Repository{Owner: "acme", Name: "web"},
+	))
+
+	var synced []string
+	filter := github.RepositoryFilter{ExcludeArchived: true}
+	runSync(context.Background(), sharedClient(client), []string{"acme/*"}, filter, 1, func(ctx context.Context, repoPath string) (string, error) {
+		synced = append(synced, repoPath)
+		return syncModeFull, nil
+	})
+
+	if want := []string{"acme/api", "acme/web"}; !reflect.DeepEqual(synced, want) {
+		t.Errorf("synced %v, want %v", synced, want)
+	}
+}
+
+func TestFetchOrganization_MetadataPerRepository(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	client := github.NewMockClientWithOptions(github.WithRepositories("acme",
+		github.Repository{Owner: "acme", Name: "api"},
+		github.Repository{Owner: "acme", Name: "web"},
+	))
+	outputDir, stateDir := t.TempDir(), t.TempDir()
+
+	for _, opts := range []fetchRunOptions{
+		{outputDir: outputDir, stateDir: stateDir, fetchAll: true},
+		{outputDir: outputDir, stateDir: stateDir, incremental: true},
+	} {
+		if err := fetchOrganization(context.Background(), client, "acme/*", opts, github.RepositoryFilter{}); err != nil {
+			t.Fatalf("organization fetch failed: %v", err)
+		}
+	}
+
+	for _, name := range []string{"api", "web"} {
+		matches, err := filepath.Glob(filepath.Join(outputDir, "acme", name, "*-metadata.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) == 0 {
+			t.Errorf("acme/%s: expected a metadata file next to its output", name)
+		}
+	}
+	if _, err := os.Stat("fetch-metadata.json"); !os.IsNotExist(err) {
+		t.Errorf("expected no metadata file in the working directory, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+	"github.com/sirseerhq/sirseer-relay/internal/output"
+	"github.com/sirseerhq/sirseer-relay/pkg/version"
+)
+
+// maxPRNumbers limits how many pull requests --pr and --pr-file can select.
+// Larger selections are better served by a search with --all.
+const maxPRNumbers = 10000
+
+// prNumberFlags holds the flags that select pull requests by number.
+type prNumberFlags struct {
+	specs []string
+	file  string
+}
+
+// numbers returns the pull request numbers selected by --pr and --pr-file,
+// sorted and without duplicates, or nil when neither flag was given.
+func (f prNumberFlags) numbers() ([]int, error) {
+	specs := f.specs
+	if f.file != "" {
+		fileSpecs, err := readPRFile(f.file)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, fileSpecs...)
+		if len(specs) == 0 {
+			return nil, fmt.Errorf("--pr-file %s lists no pull request numbers", f.file)
+		}
+	}
+	return parsePRNumbers(specs)
+}
+
+// readPRFile reads pull request numbers and ranges from path. Entries are
+// separated by whitespace or commas, and text after a # is ignored.
+func readPRFile(path string) ([]string, error) {
+	file, err := os.Open(path) // #nosec G304 - path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to open --pr-file: %w", err)
+	}
+	defer file.Close()
+
+	var specs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		specs = append(specs, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		})...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read --pr-file: %w", err)
+	}
+	return specs, nil
+}
+
+// parsePRNumbers expands numbers such as "123" and ranges such as
+// "1000-1050" into a sorted list of distinct pull request numbers.
+func parsePRNumbers(specs []string) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(spec, "-")
+		start, err := parsePRNumber(first, spec)
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if isRange {
+			if end, err = parsePRNumber(last, spec); err != nil {
+				return nil, err
+			}
+			if end < start {
+				return nil, fmt.Errorf("invalid pull request range %q: end is before start", spec)
+			}
+		}
+		if end-start >= maxPRNumbers {
+			return nil, fmt.Errorf("pull request range %q selects more than %d pull requests; use --all with --since/--until instead", spec, maxPRNumbers)
+		}
+
+		for n := start; n <= end; n++ {
+			seen[n] = true
+		}
+		if len(seen) > maxPRNumbers {
+			return nil, fmt.Errorf("more than %d pull requests selected; use --all with --since/--until instead", maxPRNumbers)
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil, nil
+	}
+	numbers := make([]int, 0, len(seen))
+	for n := range seen {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+// parsePRNumber parses one end of a --pr entry.
+func parsePRNumber(s, spec string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(s), "#"))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid pull request number %q: expected a number such as 123 or a range such as 100-150", spec)
+	}
+	return n, nil
+}
+
+// validatePRNumberOptions rejects options that select pull requests in
+// another way than by number.
+func validatePRNumberOptions(runOpts fetchRunOptions) error {
+	switch {
+	case runOpts.fetchAll:
+		return fmt.Errorf("--pr cannot be combined with --all")
+	case runOpts.incremental:
+		return fmt.Errorf("--pr cannot be combined with --incremental")
+	case runOpts.resume:
+		return fmt.Errorf("--pr cannot be combined with --resume")
+	case runOpts.since != "" || runOpts.until != "":
+		return fmt.Errorf("--pr cannot be combined with --since or --until")
+	case runOpts.filters != nil:
+		return fmt.Errorf("--pr cannot be combined with search filters")
+	}
+	return nil
+}
+
+// fetchPullRequestsByNumber fetches the pull requests with the given numbers
+// and writes them like any other fetch. Up to batchSize numbers are passed
+// to the client at a time. Numbers the repository has no PR for are
+// reported once the others are written, with an error wrapping
+// ErrPullRequestNotFound.
+func fetchPullRequestsByNumber(ctx context.Context, client github.Client, owner, repo string, writer output.OutputWriter, metadataFile string, numbers []int, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	tracker := metadata.New()
+	usage := startAPIUsage(client)
+	params := metadata.FetchParams{
+		Organization: owner,
+		Repository:   repo,
+		BatchSize:    batchSize,
+		PRNumbers:    numbers,
+	}
+	fmt.Fprintf(os.Stderr, "Fetching %d pull requests from %s/%s...", len(numbers), owner, repo)
+
+	prCount := 0
+	var missing []int
+	for start := 0; start < len(numbers); start += batchSize {
+		end := start + batchSize
+		if end > len(numbers) {
+			end = len(numbers)
+		}
+
+		page, err := client.FetchPullRequestsByNumber(ctx, owner, repo, numbers[start:end])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\r\033[K")
+			if ctx.Err() != nil {
+				saveInterruptedMetadata(tracker.GenerateMetadata(version.Version, params, false, nil), metadataFile, nil)
+			}
+			return err
+		}
+		recordPageAPICalls(tracker, usage, page)
+		missing = append(missing, page.Missing...)
+
+		for _, pr := range page.PullRequests {
+			if err := writer.Write(pr); err != nil {
+				return fmt.Errorf("failed to write PR: %w", err)
+			}
+			prCount++
+			tracker.UpdatePRStats(pr.Number, pr.CreatedAt, pr.UpdatedAt)
+		}
+		fmt.Fprintf(os.Stderr, "\rFetching %d pull requests from %s/%s... %d PRs fetched", len(numbers), owner, repo, prCount)
+	}
+
+	fmt.Fprintf(os.Stderr, "\r\033[K")
+	if prCount > 0 {
+		fmt.Fprintf(os.Stderr, "Successfully fetched %d pull requests\n", prCount)
+
+		fetchMetadata := tracker.GenerateMetadata(version.Version, params, false, nil)
+		if err := saveMetadata(fetchMetadata, metadataFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save fetch metadata: %v\n", err)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%d of %d pull requests not found in %s/%s: %s: %w",
+			len(missing), len(numbers), owner, repo, formatPRNumbers(missing), relaierrors.ErrPullRequestNotFound)
+	}
+	return nil
+}
+
+// formatPRNumbers lists pull request numbers as #1, #2, ... for messages.
+func formatPRNumbers(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = "#" + strconv.Itoa(n)
+	}
+	return strings.Join(parts, ", ")
+}
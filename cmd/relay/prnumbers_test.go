@@ -0,0 +1,172 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+	"github.com/sirseerhq/sirseer-relay/internal/output"
+)
+
+func TestParsePRNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    []int
+		wantErr string
+	}{
+		{
+			name: "no numbers",
+			want: nil,
+		},
+		{
+			name:  "numbers and ranges are sorted and deduplicated",
+			specs: []string{"456", "#123", "1000-1003", "1002", " 7 "},
+			want:  []int{7, 123, 456, 1000, 1001, 1002, 1003},
+		},
+		{
+			name:    "not a number",
+			specs:   []string{"abc"},
+			wantErr: "invalid pull request number",
+		},
+		{
+			name:    "zero",
+			specs:   []string{"0"},
+			wantErr: "invalid pull request number",
+		},
+		{
+			name:    "reversed range",
+			specs:   []string{"50-10"},
+			wantErr: "end is before start",
+		},
+		{
+			name:    "range too large",
+			specs:   []string{"1-20000"},
+			wantErr: "more than 10000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePRNumbers(tt.specs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("numbers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPRNumberFlags_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "numbers.txt")
+	content := "# flagged by the nightly job\n12\n15-17, 30\n\n40 # reopened\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	got, err := prNumberFlags{specs: []string{"5"}, file: path}.numbers()
+	if err != nil {
+		t.Fatalf("numbers failed: %v", err)
+	}
+	if want := []int{5, 12, 15, 16, 17, 30, 40}; !reflect.DeepEqual(got, want) {
+		t.Errorf("numbers = %v, want %v", got, want)
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(empty, []byte("# nothing yet\n"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := (prNumberFlags{file: empty}).numbers(); err == nil {
+		t.Error("expected an error for a file without numbers")
+	}
+}
+
+func TestFetchPullRequestsByNumber_WritesFoundAndReportsMissing(t *testing.T) {
+	client := github.NewMockClientWithOptions(github.WithPullRequests(testPullRequests(5)))
+
+	var buf bytes.Buffer
+	metadataFile := filepath.Join(t.TempDir(), "metadata.json")
+	err := fetchPullRequestsByNumber(context.Background(), client, "test", "repo", output.NewWriter(&buf), metadataFile, []int{2, 4, 9}, 2)
+	if !errors.Is(err, relaierrors.ErrPullRequestNotFound) || !strings.Contains(err.Error(), "#9") {
+		t.Fatalf("error = %v, want ErrPullRequestNotFound naming #9", err)
+	}
+	if client.CallCount != 2 {
+		t.Errorf("CallCount = %d, want 2 batches", client.CallCount)
+	}
+
+	var numbers []int
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var pr github.PullRequest
+		if err := json.Unmarshal([]byte(line), &pr); err != nil {
+			t.Fatalf("invalid record: %v", err)
+		}
+		numbers = append(numbers, pr.Number)
+	}
+	if want := []int{2, 4}; !reflect.DeepEqual(numbers, want) {
+		t.Errorf("written PRs = %v, want %v", numbers, want)
+	}
+
+	data, err := os.ReadFile(metadataFile)
+	if err != nil {
+		t.Fatalf("failed to read metadata: %v", err)
+	}
+	var meta metadata.FetchMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatalf("invalid metadata: %v", err)
+	}
+	if !reflect.DeepEqual(meta.Parameters.PRNumbers, []int{2, 4, 9}) || meta.Results.TotalPRs != 2 {
+		t.Errorf("metadata = %+v, want PR numbers [2 4 9] and 2 PRs", meta)
+	}
+}
+
+func TestValidatePRNumberOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    fetchRunOptions
+		wantErr bool
+	}{
+		{name: "numbers alone", opts: fetchRunOptions{}},
+		{name: "with --all", opts: fetchRunOptions{fetchAll: true}, wantErr: true},
+		{name: "with --incremental", opts: fetchRunOptions{incremental: true}, wantErr: true},
+		{name: "with --since", opts: fetchRunOptions{since: "2024-01-01"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validatePRNumberOptions(tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+)
+
+// rateLimitTick is how often the rate limit countdown is redrawn.
+const rateLimitTick = time.Second
+
+// newRateLimitWaiter returns a waiter that sleeps until the rate limit
+// resets. It announces the wait on w and, when showProgress is set, keeps a
+// countdown to the reset on the same line.
+func newRateLimitWaiter(w io.Writer, showProgress bool) github.RateLimitWaiter {
+	return func(ctx context.Context, resetAt time.Time) error {
+		wait := time.Until(resetAt)
+		if wait <= 0 {
+			return nil
+		}
+
+		fmt.Fprintf(w, "\r\033[K")
+		fmt.Fprintf(w, "Rate limit detected. Waiting %s for reset at %s\n",
+			wait.Round(time.Second), resetAt.Local().Format("15:04:05"))
+
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		var tick <-chan time.Time
+		if showProgress {
+			ticker := time.NewTicker(rateLimitTick)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				fmt.Fprintf(w, "\r\033[K")
+				return ctx.Err()
+			case <-timer.C:
+				if showProgress {
+					fmt.Fprintf(w, "\r\033[K")
+				}
+				return nil
+			case <-tick:
+				fmt.Fprintf(w, "\r\033[KWaiting for rate limit reset: %s remaining", time.Until(resetAt).Round(time.Second))
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+)
+
+func TestRateLimitWaiter(t *testing.T) {
+	var out bytes.Buffer
+	wait := newRateLimitWaiter(&out, false)
+
+	start := time.Now()
+	if err := wait(context.Background(), start.Add(50*time.Millisecond)); err != nil {
+		t.Fatalf("wait failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("returned after %v, before the reset", elapsed)
+	}
+	if !strings.Contains(out.String(), "Rate limit detected. Waiting") {
+		t.Errorf("expected the wait to be announced, got %q", out.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := wait(ctx, time.Now().Add(time.Hour)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled wait to return context.Canceled, got %v", err)
+	}
+}
+
+func TestRecordPageAPICalls_RateLimit(t *testing.T) {
+	page := &github.PullRequestPage{
+		APICalls: 3,
+		RateLimit: &github.RateLimitStats{
+			PointsUsed: 12,
+			Limit:      5000,
+			Remaining:  4988,
+			Waits:      1,
+			WaitTime:   90 * time.Second,
+			Tokens: []github.TokenRateLimitStats{
+				{Name: "bot-a", PointsUsed: 10, Limit: 5000, Remaining: 4990},
+				{Name: "bot-b", PointsUsed: 2, Limit: 5000, Remaining: 4998},
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		start github.RateLimitStats
+		want  metadata.RateLimitUsage
+	}{
+		{
+			name: "new client",
+			want: metadata.RateLimitUsage{
+				PointsUsed: 12, Limit: 5000, Remaining: 4988, Waits: 1, WaitTime: "1m30s",
+				Tokens: []metadata.TokenUsage{
+					{Name: "bot-a", PointsUsed: 10, Limit: 5000, Remaining: 4990},
+					{Name: "bot-b", PointsUsed: 2, Limit: 5000, Remaining: 4998},
+				},
+			},
+		},
+		{
+			name: "client shared with an earlier fetch",
+			start: github.RateLimitStats{
+				PointsUsed: 7,
+				Waits:      1,
+				WaitTime:   90 * time.Second,
+				Tokens:     []github.TokenRateLimitStats{{Name: "bot-a", PointsUsed: 7}},
+			},
+			want: metadata.RateLimitUsage{
+				PointsUsed: 5, Limit: 5000, Remaining: 4988,
+				Tokens: []metadata.TokenUsage{
+					{Name: "bot-a", PointsUsed: 3, Limit: 5000, Remaining: 4990},
+					{Name: "bot-b", PointsUsed: 2, Limit: 5000, Remaining: 4998},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := metadata.New()
+			recordPageAPICalls(tracker, &apiUsage{rateLimit: tt.start}, page)
+
+			results := tracker.GenerateMetadata("v1.0.0", metadata.FetchParams{}, false, nil).Results
+			if results.APICallCount != 3 {
+				t.Errorf("expected 3 API calls, got %d", results.APICallCount)
+			}
+			if results.RateLimit == nil || !reflect.DeepEqual(*results.RateLimit, tt.want) {
+				t.Errorf("rate limit usage = %+v, want %+v", results.RateLimit, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordPageAPICalls_Retries(t *testing.T) {
+	tracker := metadata.New()
+	usage := &apiUsage{retries: 4} // retried by an earlier fetch on the same client
+	for _, total := range []int{4, 6, 6, 9} {
+		recordPageAPICalls(tracker, usage, &github.PullRequestPage{Retries: total})
+	}
+
+	if got := tracker.GenerateMetadata("v1.0.0", metadata.FetchParams{}, false, nil).Results.Retries; got != 5 {
+		t.Errorf("Retries = %d, want 5", got)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/config"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+)
+
+// newRetryPolicy builds the client retry policy from the retry settings and
+// reports each retry on w.
+func newRetryPolicy(w io.Writer, cfg config.RetryConfig) github.RetryPolicy {
+	return github.RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   cfg.BaseDelay,
+		MaxDelay:    cfg.MaxDelay,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			fmt.Fprintf(w, "\r\033[K")
+			fmt.Fprintf(w, "Request failed (attempt %d of %d): %v. Retrying in %s...\n",
+				attempt, cfg.MaxAttempts, err, delay.Round(time.Millisecond))
+		},
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// exitCodeInterrupted is the exit code used when a second signal forces an
+// immediate exit, following the shell convention of 128 + SIGINT.
+const exitCodeInterrupted = 130
+
+// withSignalCancel returns a context that is cancelled when the process
+// receives SIGINT or SIGTERM, so a running fetch can finish the record it is
+// writing, save its checkpoint and close the output before exiting. A second
+// signal exits immediately. The returned stop function releases the signal
+// handler and must be called once the command has finished.
+func withSignalCancel(parent context.Context, w io.Writer) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-signals:
+			fmt.Fprintf(w, "\nReceived %s, stopping after the current page. Send it again to exit immediately.\n", sig)
+			cancel()
+		case <-done:
+			return
+		}
+
+		select {
+		case <-signals:
+			os.Exit(exitCodeInterrupted)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+}
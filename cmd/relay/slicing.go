@@ -0,0 +1,136 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+)
+
+// searchResultCap is the number of results at which a search window is
+// split. It is a variable so tests can lower it.
+var searchResultCap = github.SearchResultCap
+
+// minSliceSpan is the smallest date range a window is split into.
+// Search qualifiers have second precision, so ranges cannot be narrower.
+const minSliceSpan = time.Second
+
+// windowSlicer splits a date range into consecutive slices that each hold
+// fewer results than the search API returns for one query. The range applies
+// to the window's date field, creation date by default. Slices are produced
+// in chronological order, so fetching them one after the other yields a
+// single stream ordered by that date.
+type windowSlicer struct {
+	client github.Client
+	owner  string
+	repo   string
+	opts   github.FetchOptions
+
+	// next is the start of the next slice; end is the exclusive end of the
+	// whole range.
+	next time.Time
+	end  time.Time
+
+	// span is the width of the last slice, used as the starting point for
+	// sizing the next one.
+	span time.Duration
+}
+
+// newWindowSlicer creates a slicer for the range [start, end). opts supplies
+// the filters applied to every slice.
+func newWindowSlicer(client github.Client, owner, repo string, opts github.FetchOptions, start, end time.Time) *windowSlicer {
+	return &windowSlicer{
+		client: client,
+		owner:  owner,
+		repo:   repo,
+		opts:   opts,
+		next:   start,
+		end:    end,
+	}
+}
+
+// done reports whether the whole range has been handed out.
+func (s *windowSlicer) done() bool {
+	return !s.next.Before(s.end)
+}
+
+// nextSlice returns the next slice and the number of count queries used to
+// size it. It tries twice the previous width first and halves the width
+// until the slice holds fewer results than the cap. A slice that is still
+// over the cap at the minimum width is returned with a warning, as nothing
+// narrower can be queried.
+func (s *windowSlicer) nextSlice(ctx context.Context) (github.TimeRange, int, error) {
+	remaining := s.end.Sub(s.next)
+	span := s.span * 2
+	if span <= 0 || span > remaining {
+		span = remaining
+	}
+
+	queries := 0
+	for {
+		slice := github.TimeRange{Start: s.next, End: s.next.Add(span)}
+		opts := s.opts
+		opts.DateRange = &slice
+
+		count, err := s.client.CountPullRequests(ctx, s.owner, s.repo, opts)
+		queries++
+		if err != nil {
+			return github.TimeRange{}, queries, fmt.Errorf("failed to count pull requests: %w", err)
+		}
+
+		if count >= searchResultCap && span > minSliceSpan {
+			span = (span / 2).Truncate(time.Second)
+			if span < minSliceSpan {
+				span = minSliceSpan
+			}
+			continue
+		}
+
+		if count >= searchResultCap {
+			fmt.Fprintf(os.Stderr, "\r\033[K")
+			fmt.Fprintf(os.Stderr, "Warning: %d pull requests were created at %s; only the first %d can be fetched\n",
+				count, slice.Start.Format(time.RFC3339), searchResultCap)
+		}
+
+		s.next = slice.End
+		s.span = span
+		return slice, queries, nil
+	}
+}
+
+// slicingRange returns the date range to slice for a window that hit the
+// search result cap. The range starts at --since, or at oldest, the date of
+// the oldest PR in the window, and ends at --until or just after the current
+// time. Bounds are widened to whole seconds, the precision of search
+// qualifiers; PRs in the widened part are dropped by the client.
+func slicingRange(opts github.FetchOptions, oldest time.Time, now time.Time) (start, end time.Time) {
+	start = oldest.UTC().Truncate(time.Second)
+	if opts.Since != nil {
+		start = opts.Since.UTC().Truncate(time.Second)
+	}
+
+	end = now.UTC().Truncate(time.Second).Add(time.Second)
+	if opts.Until != nil {
+		end = opts.Until.UTC().Truncate(time.Second)
+		if end.Before(*opts.Until) {
+			end = end.Add(time.Second)
+		}
+	}
+	return start, end
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/output"
+	"github.com/sirseerhq/sirseer-relay/internal/state"
+)
+
+func TestFetchAll_SlicesPastResultCap(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	originalCap := searchResultCap
+	searchResultCap = 10
+	defer func() { searchResultCap = originalCap }()
+
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "prs.ndjson")
+	metadataFile := filepath.Join(tmpDir, "metadata.json")
+	repoPath := "test/repo"
+	opts := github.FetchOptions{PageSize: 5}
+	prs := testPullRequests(25)
+
+	client := github.NewMockClientWithOptions(
+		github.WithPullRequests(prs),
+		github.WithPagination(5),
+		github.WithResultCap(10),
+	)
+	writer, err := output.NewFileWriter(outputFile)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	run := newCheckpointer(state.NewFileStore(""), repoPath, outputFile, writer, opts, nil)
+	if err := fetchAllPullRequestsWithOptions(context.Background(), client, "test", "repo", writer, metadataFile, opts, run); err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	writer.Close()
+
+	if client.CountCalls == 0 {
+		t.Error("expected the window to be sized with count queries")
+	}
+
+	numbers := readPRNumbers(t, outputFile)
+	if len(numbers) != len(prs) {
+		t.Fatalf("expected %d records, got %d: %v", len(prs), len(numbers), numbers)
+	}
+	for i, n := range numbers {
+		if n != i+1 {
+			t.Fatalf("expected PRs 1-%d in order without duplicates, got %v", len(prs), numbers)
+		}
+	}
+
+	fetchState, err := state.LoadState(state.GetStateFilePath(repoPath))
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if fetchState.LastPRNumber != 25 || fetchState.TotalFetched != 25 {
+		t.Errorf("unexpected final state: %+v", fetchState)
+	}
+}
+
+func TestSlicingRange(t *testing.T) {
+	oldest := time.Date(2024, 1, 1, 10, 30, 15, 500, time.UTC)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		opts      github.FetchOptions
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "whole history",
+			wantStart: time.Date(2024, 1, 1, 10, 30, 15, 0, time.UTC),
+			wantEnd:   now.Add(time.Second),
+		},
+		{
+			name: "since and until bound the range",
+			opts: github.FetchOptions{
+				Since: timePtr(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+				Until: timePtr(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)),
+			},
+			wantStart: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "since alone starts at since",
+			opts:      github.FetchOptions{Since: timePtr(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))},
+			wantStart: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   now.Add(time.Second),
+		},
+		{
+			name:      "until alone ends at until",
+			opts:      github.FetchOptions{Until: timePtr(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))},
+			wantStart: time.Date(2024, 1, 1, 10, 30, 15, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "sub-second bounds widen to whole seconds",
+			opts: github.FetchOptions{
+				Since: timePtr(time.Date(2024, 2, 1, 9, 0, 0, 250, time.UTC)),
+				Until: timePtr(time.Date(2024, 2, 1, 17, 0, 0, 250, time.UTC)),
+			},
+			wantStart: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 2, 1, 17, 0, 1, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := slicingRange(tt.opts, oldest, now)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("slicingRange() = [%v, %v), want [%v, %v)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,508 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/config"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// newStateCommand creates the state command, whose subcommands inspect and
+// edit the state that --incremental, --resume and sync rely on.
+func newStateCommand(configFile *string) *cobra.Command {
+	var stateDir string
+
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Inspect, edit, export and reset fetch state",
+		Long: `Inspect and edit the state that incremental fetches, --resume and sync
+keep for every repository, without reading state files or the state
+database by hand.
+
+Subcommands:
+  list    List every repository with its watermark, last fetch and totals
+  show    Print the state of one repository as JSON
+  set     Move the watermark of a repository to a date or pull request
+  reset   Delete the state of repositories
+  export  Write the state of repositories to a bundle file
+  import  Read the states of a bundle file into the state store
+
+Checksums are recomputed whenever state is changed, and repositories being
+fetched are locked while their state is changed.
+
+Examples:
+  # Re-fetch everything updated since June 1st on the next incremental run
+  sirseer-relay state set golang/go --watermark 2024-06-01
+
+  # Move state to another machine
+  sirseer-relay state export --output state.json
+  sirseer-relay state import state.json`,
+	}
+
+	cmd.PersistentFlags().StringVar(&stateDir, "state-dir", "", "Directory of the state files or database (default from config or ~/.sirseer/state)")
+
+	// openStore opens the configured state store, in --state-dir if given
+	openStore := func() (state.StateStore, error) {
+		cfg, err := config.LoadConfig(*configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		dir := stateDir
+		if dir == "" {
+			dir = cfg.Defaults.StateDir
+		}
+		return openStateStore(cfg, dir)
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List every repository with saved state",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				store, err := openStore()
+				if err != nil {
+					return err
+				}
+				defer store.Close()
+				return listStates(cmd.OutOrStdout(), store)
+			},
+		},
+		&cobra.Command{
+			Use:   "show <org>/<repo>",
+			Short: "Print the state of a repository as JSON",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				store, err := openStore()
+				if err != nil {
+					return err
+				}
+				defer store.Close()
+				return showState(cmd.OutOrStdout(), store, args[0])
+			},
+		},
+		newStateSetCommand(configFile, &stateDir),
+		&cobra.Command{
+			Use:   "reset <org>/<repo>...",
+			Short: "Delete the state of repositories so the next fetch starts over",
+			Args:  cobra.MinimumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				store, err := openStore()
+				if err != nil {
+					return err
+				}
+				defer store.Close()
+				for _, repoPath := range args {
+					if err := resetState(cmd.Context(), store, repoPath); err != nil {
+						return err
+					}
+					fmt.Fprintf(cmd.ErrOrStderr(), "Reset state of %s\n", repoPath)
+				}
+				return nil
+			},
+		},
+		newStateExportCommand(openStore),
+		newStateImportCommand(openStore),
+	)
+
+	return cmd
+}
+
+// newStateSetCommand creates the state set subcommand. Moving the watermark
+// to a pull request looks up its creation date, so it needs GitHub access.
+func newStateSetCommand(configFile, stateDir *string) *cobra.Command {
+	var (
+		watermark string
+		prNumber  int
+		token     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set <org>/<repo>",
+		Short: "Move the watermark of a repository to a date or pull request",
+		Long: `Move the updated-at watermark of a repository. The next incremental fetch
+re-emits every pull request updated at or after it.
+
+With --pr the watermark moves to the creation date of that pull request, so
+it and every newer pull request are fetched again, and the last seen PR number
+is lowered to just before it. This looks the pull request up on GitHub.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (watermark == "") == (prNumber == 0) {
+				return fmt.Errorf("specify exactly one of --watermark or --pr")
+			}
+			owner, _, err := parseRepository(args[0])
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.LoadConfig(*configFile)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			dir := *stateDir
+			if dir == "" {
+				dir = cfg.Defaults.StateDir
+			}
+			store, err := openStateStore(cfg, dir)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			var client github.Client
+			var watermarkTime *time.Time
+			if prNumber != 0 {
+				creds, err := newCredentials(cmd.Context(), token, cfg, owner, 0)
+				if err != nil {
+					return err
+				}
+				client = newGitHubClient(creds, cfg, 0)
+			} else {
+				parsed, err := parseDate(watermark)
+				if err != nil {
+					return fmt.Errorf("invalid --watermark date format: %w", err)
+				}
+				watermarkTime = &parsed
+			}
+
+			previous, updated, err := setStateWatermark(cmd.Context(), client, store, args[0], watermarkTime, prNumber)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Watermark of %s set to %s (was %s)\n", args[0], formatStateTime(updated), formatStateTime(previous))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&watermark, "watermark", "", "New watermark: YYYY-MM-DD, RFC3339 or relative (7d)")
+	cmd.Flags().IntVar(&prNumber, "pr", 0, "Move the watermark to the creation date of this pull request")
+	cmd.Flags().StringVar(&token, "token", "", "GitHub personal access token for --pr (overrides GITHUB_TOKEN env var)")
+
+	return cmd
+}
+
+// newStateExportCommand creates the state export subcommand.
+func newStateExportCommand(openStore func() (state.StateStore, error)) *cobra.Command {
+	var outputFile string
+
+	cmd := &cobra.Command{
+		Use:   "export [<org>/<repo>...]",
+		Short: "Write the state of repositories to a bundle file",
+		Long: `Write the state of the given repositories, or of every repository, to a
+JSON bundle that state import reads on another machine. Checkpoints of
+interrupted fetches refer to output files on this machine and are only
+useful where those files exist.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			w := cmd.OutOrStdout()
+			if outputFile != "" {
+				file, err := os.Create(outputFile) // #nosec G304 -- user-specified output path
+				if err != nil {
+					return fmt.Errorf("failed to create bundle file: %w", err)
+				}
+				defer file.Close()
+				w = file
+			}
+
+			count, err := exportStates(w, store, args)
+			if err != nil {
+				return err
+			}
+			if outputFile != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Exported the state of %d repositories to %s\n", count, outputFile)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Bundle file to write (default: stdout)")
+
+	return cmd
+}
+
+// newStateImportCommand creates the state import subcommand.
+func newStateImportCommand(openStore func() (state.StateStore, error)) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "import <bundle-file>",
+		Short: "Read the states of a bundle file into the state store",
+		Long: `Save every state of a bundle written by state export. Repositories that
+already have state are skipped unless --force is given; existing state that
+cannot be read stops the import until --force replaces it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			file, err := os.Open(args[0]) // #nosec G304 -- user-specified bundle path
+			if err != nil {
+				return fmt.Errorf("failed to open bundle file: %w", err)
+			}
+			defer file.Close()
+
+			imported, skipped, err := importStates(cmd.Context(), file, store, force)
+			for _, repoPath := range skipped {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Skipped %s: state already exists (use --force to replace it)\n", repoPath)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Imported the state of %d repositories\n", len(imported))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Replace the state of repositories that already have state")
+
+	return cmd
+}
+
+// listStates writes a table of every repository with state in store to w.
+// State that cannot be loaded is listed with the reason.
+func listStates(w io.Writer, store state.StateStore) error {
+	repos, err := store.ListStates()
+	if err != nil {
+		return err
+	}
+	if len(repos) == 0 {
+		fmt.Fprintf(w, "No saved state in %s\n", store.Location())
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPOSITORY\tLAST FETCH\tWATERMARK\tLAST PR\tTOTAL\tSTATUS")
+	for _, repoPath := range repos {
+		fetchState, err := store.LoadState(repoPath)
+		if err != nil {
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t-\terror: %v\n", repoPath, err)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t#%d\t%d\t%s\n",
+			fetchState.Repository,
+			formatStateTime(&fetchState.LastFetchTime),
+			formatStateTime(fetchState.UpdatedWatermark),
+			fetchState.LastPRNumber,
+			fetchState.TotalFetched,
+			stateStatus(fetchState))
+	}
+	return tw.Flush()
+}
+
+// stateStatus summarizes whether the fetch recorded in fetchState finished.
+func stateStatus(fetchState *state.FetchState) string {
+	if fetchState.Checkpoint != nil {
+		return fmt.Sprintf("interrupted after %d PRs", fetchState.Checkpoint.PRsWritten)
+	}
+	return "complete"
+}
+
+// formatStateTime formats an optional state timestamp for display.
+func formatStateTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// showState writes the state of repoPath to w as indented JSON.
+func showState(w io.Writer, store state.StateStore, repoPath string) error {
+	if _, _, err := parseRepository(repoPath); err != nil {
+		return err
+	}
+	fetchState, err := store.LoadState(repoPath)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(fetchState, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// setStateWatermark moves the updated-at watermark of repoPath to watermark,
+// or with a prNumber to the creation date of that pull request, looked up
+// with client. It returns the previous and new watermark.
+func setStateWatermark(ctx context.Context, client github.Client, store state.StateStore, repoPath string, watermark *time.Time, prNumber int) (previous, updated *time.Time, err error) {
+	owner, repo, err := parseRepository(repoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lock, err := lockState(ctx, store, repoPath, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer lock.Unlock()
+
+	fetchState, err := store.LoadState(repoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fetchState.LastFetchTime.IsZero() {
+		return nil, nil, fmt.Errorf("%s has no completed fetch whose watermark could be moved. Finish the initial fetch with --resume first", repoPath)
+	}
+
+	if prNumber != 0 {
+		pr, err := client.FetchPullRequest(ctx, owner, repo, prNumber)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to look up PR #%d: %w", prNumber, err)
+		}
+		createdAt := pr.CreatedAt.UTC()
+		watermark = &createdAt
+		if fetchState.LastPRNumber >= prNumber {
+			fetchState.LastPRNumber = prNumber - 1
+		}
+	}
+
+	previous = fetchState.UpdatedWatermark
+	if previous == nil {
+		previous = &fetchState.LastPRDate
+	}
+	fetchState.UpdatedWatermark = watermark
+
+	// SaveState recomputes the checksum
+	if err := store.SaveState(fetchState); err != nil {
+		return nil, nil, fmt.Errorf("failed to save state: %w", err)
+	}
+	return previous, watermark, nil
+}
+
+// resetState deletes the state of repoPath so its next fetch starts over.
+func resetState(ctx context.Context, store state.StateStore, repoPath string) error {
+	if _, _, err := parseRepository(repoPath); err != nil {
+		return err
+	}
+	lock, err := lockState(ctx, store, repoPath, 0)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	// State that cannot be loaded is deleted all the same
+	if _, err := store.LoadState(repoPath); errors.Is(err, state.ErrNoState) {
+		return fmt.Errorf("no state found for %s in %s", repoPath, store.Location())
+	}
+	return store.DeleteState(repoPath)
+}
+
+// exportStates writes a bundle of the state of repos, or of every
+// repository in store when repos is empty, to w. It returns the number of
+// states exported.
+func exportStates(w io.Writer, store state.StateStore, repos []string) (int, error) {
+	if len(repos) == 0 {
+		var err error
+		if repos, err = store.ListStates(); err != nil {
+			return 0, err
+		}
+	} else {
+		for _, repoPath := range repos {
+			if _, _, err := parseRepository(repoPath); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	states := make([]*state.FetchState, 0, len(repos))
+	for _, repoPath := range repos {
+		fetchState, err := store.LoadState(repoPath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to export %s: %w", repoPath, err)
+		}
+		states = append(states, fetchState)
+	}
+	if err := state.WriteBundle(w, states); err != nil {
+		return 0, err
+	}
+	return len(states), nil
+}
+
+// importStates saves the states of the bundle read from r to store.
+// Repositories that already have state are skipped unless force is set, and
+// existing state that cannot be read stops the import. The bundle is
+// validated completely before anything is saved.
+func importStates(ctx context.Context, r io.Reader, store state.StateStore, force bool) (imported, skipped []string, err error) {
+	states, err := state.ReadBundle(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, fetchState := range states {
+		if _, _, err := parseRepository(fetchState.Repository); err != nil {
+			return nil, nil, fmt.Errorf("invalid state in bundle: %w", err)
+		}
+	}
+
+	for _, fetchState := range states {
+		repoPath := fetchState.Repository
+		saved, err := importState(ctx, fetchState, store, force)
+		if err != nil {
+			return imported, skipped, fmt.Errorf("failed to import %s: %w", repoPath, err)
+		}
+		if saved {
+			imported = append(imported, repoPath)
+		} else {
+			skipped = append(skipped, repoPath)
+		}
+	}
+	return imported, skipped, nil
+}
+
+// importState saves one imported state under the repository's lock. It
+// reports false when existing state was kept. Existing state that cannot be
+// read is an error rather than a skip, so a corrupt state is not mistaken for
+// one worth keeping.
+func importState(ctx context.Context, fetchState *state.FetchState, store state.StateStore, force bool) (bool, error) {
+	lock, err := lockState(ctx, store, fetchState.Repository, 0)
+	if err != nil {
+		return false, err
+	}
+	defer lock.Unlock()
+
+	if !force {
+		_, err := store.LoadState(fetchState.Repository)
+		switch {
+		case err == nil:
+			return false, nil
+		case !errors.Is(err, state.ErrNoState):
+			return false, fmt.Errorf("existing state cannot be read (use --force to replace it): %w", err)
+		}
+	}
+	if err := store.SaveState(fetchState); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/state"
+)
+
+// saveTestStates saves states into a new state directory and returns it.
+func saveTestStates(t *testing.T, states ...*state.FetchState) string {
+	t.Helper()
+	stateDir := t.TempDir()
+	for _, s := range states {
+		if err := state.SaveState(s, state.StateFilePath(stateDir, s.Repository)); err != nil {
+			t.Fatalf("failed to save state: %v", err)
+		}
+	}
+	return stateDir
+}
+
+func TestListStates(t *testing.T) {
+	fetched := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	watermark := fetched.Add(-time.Minute)
+	stateDir := saveTestStates(t,
+		&state.FetchState{Repository: "golang/go", LastPRNumber: 42, UpdatedWatermark: &watermark, LastFetchTime: fetched, TotalFetched: 7},
+		&state.FetchState{Repository: "facebook/react", Checkpoint: &state.Checkpoint{PRsWritten: 200}},
+	)
+	if err := os.WriteFile(state.StateFilePath(stateDir, "broken/repo"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := listStates(&buf, state.NewFileStore(stateDir)); err != nil {
+		t.Fatalf("listStates() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header and 3 rows, got:\n%s", buf.String())
+	}
+	wantRows := []string{
+		"broken-repo - - - - error: state file is corrupted",
+		"facebook/react - - #0 0 interrupted after 200 PRs",
+		"golang/go 2024-06-01T12:00:00Z 2024-06-01T11:59:00Z #42 7 complete",
+	}
+	for i, want := range wantRows {
+		if row := strings.Join(strings.Fields(lines[i+1]), " "); !strings.HasPrefix(row, want) {
+			t.Errorf("row %d = %q, want prefix %q", i+1, row, want)
+		}
+	}
+
+	buf.Reset()
+	if err := listStates(&buf, state.NewFileStore(t.TempDir())); err != nil || !strings.Contains(buf.String(), "No saved state") {
+		t.Errorf("listStates() of an empty directory = %q, %v", buf.String(), err)
+	}
+}
+
+func TestShowState(t *testing.T) {
+	stateDir := saveTestStates(t, &state.FetchState{Repository: "golang/go", LastPRNumber: 42})
+
+	var buf bytes.Buffer
+	if err := showState(&buf, state.NewFileStore(stateDir), "golang/go"); err != nil {
+		t.Fatalf("showState() error = %v", err)
+	}
+	var shown state.FetchState
+	if err := json.Unmarshal(buf.Bytes(), &shown); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if shown.Repository != "golang/go" || shown.LastPRNumber != 42 {
+		t.Errorf("unexpected state: %+v", shown)
+	}
+
+	if err := showState(&buf, state.NewFileStore(stateDir), "golang/missing"); err == nil {
+		t.Error("expected an error for a repository without state")
+	}
+}
+
+func TestSetStateWatermark(t *testing.T) {
+	fetched := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	prs := testPullRequests(5)
+
+	tests := []struct {
+		name          string
+		watermark     *time.Time
+		prNumber      int
+		wantWatermark time.Time
+		wantLastPR    int
+		wantErr       error
+	}{
+		{
+			name:          "date",
+			watermark:     timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			wantWatermark: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantLastPR:    5,
+		},
+		{
+			name:          "pull request",
+			prNumber:      3,
+			wantWatermark: prs[2].CreatedAt,
+			wantLastPR:    2,
+		},
+		{
+			name:     "unknown pull request",
+			prNumber: 99,
+			wantErr:  relaierrors.ErrPullRequestNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateDir := saveTestStates(t, &state.FetchState{Repository: "test/repo", LastPRNumber: 5, UpdatedWatermark: &fetched, LastFetchTime: fetched})
+			client := github.NewMockClientWithOptions(github.WithPullRequests(prs))
+
+			previous, _, err := setStateWatermark(context.Background(), client, state.NewFileStore(stateDir), "test/repo", tt.watermark, tt.prNumber)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setStateWatermark() error = %v", err)
+			}
+			if !previous.Equal(fetched) {
+				t.Errorf("previous watermark = %v, want %v", previous, fetched)
+			}
+
+			// The saved state loads, so its checksum was recomputed
+			saved, err := state.LoadState(state.StateFilePath(stateDir, "test/repo"))
+			if err != nil {
+				t.Fatalf("failed to load the updated state: %v", err)
+			}
+			if saved.UpdatedWatermark == nil || !saved.UpdatedWatermark.Equal(tt.wantWatermark) {
+				t.Errorf("watermark = %v, want %v", saved.UpdatedWatermark, tt.wantWatermark)
+			}
+			if saved.LastPRNumber != tt.wantLastPR {
+				t.Errorf("LastPRNumber = %d, want %d", saved.LastPRNumber, tt.wantLastPR)
+			}
+		})
+	}
+}
+
+func TestSetStateWatermark_InterruptedInitialFetch(t *testing.T) {
+	stateDir := saveTestStates(t, &state.FetchState{Repository: "test/repo", Checkpoint: &state.Checkpoint{PageNum: 1}})
+
+	_, _, err := setStateWatermark(context.Background(), nil, state.NewFileStore(stateDir), "test/repo", timePtr(time.Now()), 0)
+	if err == nil || !strings.Contains(err.Error(), "--resume") {
+		t.Errorf("expected an error pointing to --resume, got %v", err)
+	}
+}
+
+func TestResetState(t *testing.T) {
+	stateDir := saveTestStates(t, &state.FetchState{Repository: "test/repo"})
+
+	held, err := state.AcquireLock(context.Background(), state.StateFilePath(stateDir, "test/repo"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resetState(context.Background(), state.NewFileStore(stateDir), "test/repo"); !errors.Is(err, state.ErrLocked) {
+		t.Errorf("expected ErrLocked while a fetch holds the lock, got %v", err)
+	}
+	_ = held.Unlock()
+
+	if err := resetState(context.Background(), state.NewFileStore(stateDir), "test/repo"); err != nil {
+		t.Fatalf("resetState() error = %v", err)
+	}
+	if _, err := os.Stat(state.StateFilePath(stateDir, "test/repo")); !os.IsNotExist(err) {
+		t.Errorf("expected the state file to be deleted, got %v", err)
+	}
+	if err := resetState(context.Background(), state.NewFileStore(stateDir), "test/repo"); err == nil {
+		t.Error("expected an error when there is no state to reset")
+	}
+}
+
+func TestExportImportStates(t *testing.T) {
+	fetched := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	source := saveTestStates(t,
+		&state.FetchState{Repository: "golang/go", LastPRNumber: 42, LastFetchTime: fetched},
+		&state.FetchState{Repository: "facebook/react", LastPRNumber: 7, LastFetchTime: fetched},
+	)
+
+	var bundle bytes.Buffer
+	count, err := exportStates(&bundle, state.NewFileStore(source), nil)
+	if err != nil || count != 2 {
+		t.Fatalf("exportStates() = %d, %v", count, err)
+	}
+
+	// The target already has state for one repository
+	target := saveTestStates(t, &state.FetchState{Repository: "golang/go", LastPRNumber: 1})
+	imported, skipped, err := importStates(context.Background(), bytes.NewReader(bundle.Bytes()), state.NewFileStore(target), false)
+	if err != nil {
+		t.Fatalf("importStates() error = %v", err)
+	}
+	if strings.Join(imported, ",") != "facebook/react" || strings.Join(skipped, ",") != "golang/go" {
+		t.Errorf("imported %v, skipped %v", imported, skipped)
+	}
+	kept, err := state.LoadState(state.StateFilePath(target, "golang/go"))
+	if err != nil || kept.LastPRNumber != 1 {
+		t.Errorf("expected existing state to be kept, got %+v, %v", kept, err)
+	}
+
+	imported, _, err = importStates(context.Background(), bytes.NewReader(bundle.Bytes()), state.NewFileStore(target), true)
+	if err != nil || len(imported) != 2 {
+		t.Fatalf("importStates() with force = %v, %v", imported, err)
+	}
+	replaced, err := state.LoadState(state.StateFilePath(target, "golang/go"))
+	if err != nil || replaced.LastPRNumber != 42 {
+		t.Errorf("expected the imported state, got %+v, %v", replaced, err)
+	}
+
+	// Existing state that cannot be read is reported, not skipped
+	if err := os.WriteFile(state.StateFilePath(target, "facebook/react"), []byte("{corrupt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := importStates(context.Background(), bytes.NewReader(bundle.Bytes()), state.NewFileStore(target), false); err == nil || !strings.Contains(err.Error(), "facebook/react") {
+		t.Errorf("expected an error importing over corrupt state, got %v", err)
+	}
+	imported, _, err = importStates(context.Background(), bytes.NewReader(bundle.Bytes()), state.NewFileStore(target), true)
+	if err != nil || len(imported) != 2 {
+		t.Errorf("importStates() with force over corrupt state = %v, %v", imported, err)
+	}
+
+	// Exporting selected repositories
+	bundle.Reset()
+	if count, err := exportStates(&bundle, state.NewFileStore(source), []string{"golang/go"}); err != nil || count != 1 {
+		t.Errorf("exportStates() of one repository = %d, %v", count, err)
+	}
+	if _, err := exportStates(&bundle, state.NewFileStore(source), []string{"golang/missing"}); err == nil {
+		t.Error("expected an error exporting a repository without state")
+	}
+}
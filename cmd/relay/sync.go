@@ -0,0 +1,459 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/config"
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/state"
+	"github.com/spf13/cobra"
+)
+
+// How sync brings a repository up to date, chosen from its saved state.
+const (
+	syncModeFull        = "full"
+	syncModeIncremental = "incremental"
+	syncModeResume      = "resume"
+)
+
+// Outcomes of syncing a repository.
+const (
+	syncSucceeded = "succeeded"
+	syncFailed    = "failed"
+	syncSkipped   = "skipped"
+)
+
+// newSyncCommand creates the 'sync' subcommand for the CLI. It keeps every
+// repository listed under sync.repositories in the config file up to date,
+// fetching several at once through a GitHub client shared by every owner
+// whose credentials allow it, so they share one rate limit budget.
+//
+// configFile points at the root command's --config flag value, which is only
+// populated once flags have been parsed.
+func newSyncCommand(configFile *string) *cobra.Command {
+	var (
+		opts           fetchRunOptions
+		concurrency    int
+		requestTimeout int
+		maxDuration    time.Duration
+		summaryFile    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Fetch every repository listed in the config file",
+		Long: `Fetch pull request data for every repository listed under sync.repositories
+in the config file. Entries have the form <org>/<repo>, or <org>/* for every
+repository owned by an organization or user.
+
+Each repository is brought up to date according to its saved state:
+  - full:        no previous fetch, so every PR is fetched
+  - incremental: PRs created or updated since the last fetch are fetched
+  - resume:      an interrupted full fetch is continued from its checkpoint
+
+Output is written to <output-dir>/<org>/<repo>/ as with fetch. A summary of
+every repository is printed when the run ends, and the exit status is 4 when
+only some of them synced.
+
+Example config:
+  sync:
+    concurrency: 4
+    repositories:
+      - golang/go
+      - kubernetes/*
+
+Examples:
+  # Sync the repositories in the default config file
+  sirseer-relay sync
+
+  # Sync with a specific config and save a JSON summary
+  sirseer-relay sync --config relay.yaml --summary-file sync-summary.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadConfig(*configFile)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			if validateErr := cfg.Validate(); validateErr != nil {
+				return fmt.Errorf("invalid configuration: %w", validateErr)
+			}
+			if len(cfg.Sync.Repositories) == 0 {
+				return fmt.Errorf("no repositories to sync. List them under sync.repositories in the config file")
+			}
+			if concurrency == 0 {
+				concurrency = cfg.Sync.Concurrency
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1, got: %d", concurrency)
+			}
+			if opts.stateDir == "" {
+				opts.stateDir = cfg.Defaults.StateDir
+			}
+			if opts.store, err = openStateStore(cfg, opts.stateDir); err != nil {
+				return err
+			}
+			defer opts.store.Close()
+
+			opts.requestTimeout = time.Duration(requestTimeout) * time.Second
+			ctx := cmd.Context()
+			if maxDuration > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, maxDuration)
+				defer cancel()
+			}
+
+			clients, err := newSyncClients(ctx, opts.token, cfg, cfg.Sync.Repositories, opts.requestTimeout)
+			if err != nil {
+				return err
+			}
+
+			filter, err := newRepositoryFilter(cfg.Sync.Filter)
+			if err != nil {
+				return err
+			}
+			summary := runSync(ctx, clients, cfg.Sync.Repositories, filter, concurrency, func(ctx context.Context, repoPath string) (string, error) {
+				owner, _, _ := strings.Cut(repoPath, "/")
+				return syncRepository(ctx, clients(owner), repoPath, opts, cfg)
+			})
+
+			summary.print(os.Stderr)
+			if summaryFile != "" {
+				if err := summary.save(summaryFile); err != nil {
+					return err
+				}
+			}
+			return summary.err
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.token, "token", "", "GitHub personal access token (overrides GITHUB_TOKEN env var)")
+	cmd.Flags().StringVar(&opts.outputDir, "output-dir", "output", "Output directory for generated files")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 0, "Number of repositories fetched at once (default from config or 4)")
+	cmd.Flags().IntVar(&requestTimeout, "request-timeout", 180, "Timeout for each API request in seconds (default: 3 minutes)")
+	cmd.Flags().DurationVar(&maxDuration, "max-duration", 0, "Stop the whole sync after this long, e.g. 30m or 2h (default: no limit)")
+	cmd.Flags().StringVar(&summaryFile, "summary-file", "", "Path to save the run summary as JSON")
+	cmd.Flags().StringVar(&opts.stateDir, "state-dir", "", "Directory for the state files or database that track each repository (default from config or ~/.sirseer/state)")
+	cmd.Flags().DurationVar(&opts.lockWait, "wait-for-lock", 0, "Wait up to this long for another fetch of a repository to finish, e.g. 10m (default: skip it as failed)")
+
+	return cmd
+}
+
+// clientFor returns the client that fetches the repositories of owner.
+type clientFor func(owner string) github.Client
+
+// sharedClient fetches the repositories of every owner with client.
+func sharedClient(client github.Client) clientFor {
+	return func(string) github.Client { return client }
+}
+
+// newSyncClients creates the clients that fetch the repositories listed in
+// entries. Every owner shares one client, and with it one rate limit budget,
+// unless a GitHub App installation is looked up by owner; each owner then
+// gets a client authenticated as the installation on it.
+func newSyncClients(ctx context.Context, flagToken string, cfg *config.Config, entries []string, requestTimeout time.Duration) (clientFor, error) {
+	var owners []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		owner, _, _ := strings.Cut(entry, "/")
+		if !seen[owner] {
+			seen[owner] = true
+			owners = append(owners, owner)
+		}
+	}
+
+	if !installationPerOwner(flagToken, cfg) {
+		creds, err := newCredentials(ctx, flagToken, cfg, owners[0], requestTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return sharedClient(newGitHubClient(creds, cfg, requestTimeout)), nil
+	}
+
+	clients := make(map[string]github.Client, len(owners))
+	for _, owner := range owners {
+		creds, err := newCredentials(ctx, flagToken, cfg, owner, requestTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to authenticate for %s: %w", owner, err)
+		}
+		clients[owner] = newGitHubClient(creds, cfg, requestTimeout)
+	}
+	return func(owner string) github.Client { return clients[owner] }, nil
+}
+
+// installationPerOwner reports whether the credentials newCredentials builds
+// depend on the owner, which is the case when a GitHub App is used without
+// a configured installation ID.
+func installationPerOwner(flagToken string, cfg *config.Config) bool {
+	if flagToken != "" {
+		return false
+	}
+	if len(cfg.GitHub.Tokens) == 0 {
+		return cfg.GitHub.App.Enabled() && cfg.GitHub.App.InstallationID == 0
+	}
+	for _, entry := range cfg.GitHub.Tokens {
+		if entry.App.Enabled() && entry.App.InstallationID == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// syncFunc brings one repository up to date and returns the mode it used.
+type syncFunc func(ctx context.Context, repoPath string) (string, error)
+
+// syncResult is the outcome of syncing one repository.
+type syncResult struct {
+	Repository      string  `json:"repository"`
+	Mode            string  `json:"mode,omitempty"`
+	Status          string  `json:"status"`
+	Error           string  `json:"error,omitempty"`
+	ExitCode        int     `json:"exit_code"`
+	DurationSeconds float64 `json:"duration_seconds"`
+
+	err error
+}
+
+// syncSummary is the combined result of a sync run.
+type syncSummary struct {
+	StartedAt    time.Time    `json:"started_at"`
+	CompletedAt  time.Time    `json:"completed_at"`
+	Succeeded    int          `json:"succeeded"`
+	Failed       int          `json:"failed"`
+	Skipped      int          `json:"skipped"`
+	ExitCode     int          `json:"exit_code"`
+	Repositories []syncResult `json:"repositories"`
+
+	// err is the error the command exits with, nil when every repository
+	// synced
+	err error
+}
+
+// runSync expands the configured entries into repositories, with owner/*
+// entries limited to the repositories matching filter, and syncs up to
+// concurrency of them at a time with syncRepo. Repositories not yet started
+// when ctx is cancelled are skipped. An owner/* entry that cannot be
+// expanded is reported as a failed repository of its own.
+func runSync(ctx context.Context, clients clientFor, entries []string, filter github.RepositoryFilter, concurrency int, syncRepo syncFunc) *syncSummary {
+	summary := &syncSummary{StartedAt: time.Now().UTC()}
+	repos := expandSyncEntries(ctx, clients, entries, filter, summary)
+
+	results := make([]syncResult, len(repos))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, repoPath := range repos {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			results[i] = syncResult{Repository: repoPath, Status: syncSkipped, Error: "sync stopped before the repository was started"}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, repoPath string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			fmt.Fprintf(os.Stderr, "Syncing %s\n", repoPath)
+			start := time.Now()
+			mode, err := syncRepo(ctx, repoPath)
+			results[i] = newSyncResult(repoPath, mode, err, time.Since(start))
+		}(i, repoPath)
+	}
+	wg.Wait()
+
+	summary.Repositories = append(summary.Repositories, results...)
+	summary.finish()
+	return summary
+}
+
+// expandSyncEntries replaces owner/* entries with the repositories of that
+// owner that match filter and drops duplicates, keeping the order of the
+// config file. Entries that cannot be expanded are recorded in summary as
+// failed.
+func expandSyncEntries(ctx context.Context, clients clientFor, entries []string, filter github.RepositoryFilter, summary *syncSummary) []string {
+	seen := make(map[string]bool)
+	var repos []string
+	add := func(repoPath string) {
+		if !seen[repoPath] {
+			seen[repoPath] = true
+			repos = append(repos, repoPath)
+		}
+	}
+
+	for _, entry := range entries {
+		owner, name, _ := strings.Cut(entry, "/")
+		if name != "*" {
+			add(entry)
+			continue
+		}
+
+		ownerRepos, err := clients(owner).GetOrganizationRepositories(ctx, owner, filter)
+		if err != nil {
+			summary.Repositories = append(summary.Repositories, newSyncResult(entry, "", fmt.Errorf("failed to list repositories: %w", err), 0))
+			continue
+		}
+		for _, r := range ownerRepos {
+			add(r.FullName())
+		}
+	}
+	return repos
+}
+
+// newSyncResult records the outcome of syncing repoPath.
+func newSyncResult(repoPath, mode string, err error, elapsed time.Duration) syncResult {
+	result := syncResult{
+		Repository:      repoPath,
+		Mode:            mode,
+		Status:          syncSucceeded,
+		DurationSeconds: elapsed.Seconds(),
+		err:             err,
+	}
+	if err != nil {
+		result.Status = syncFailed
+		result.Error = err.Error()
+		result.ExitCode = mapErrorToExitCode(err)
+	}
+	return result
+}
+
+// finish counts the outcomes and determines the error the run exits with.
+// A run where every repository failed exits like the first failure; a run
+// where only some did exits with ErrPartialFetch.
+func (s *syncSummary) finish() {
+	s.CompletedAt = time.Now().UTC()
+
+	var firstErr error
+	for _, r := range s.Repositories {
+		switch r.Status {
+		case syncSucceeded:
+			s.Succeeded++
+		case syncFailed:
+			s.Failed++
+			if firstErr == nil {
+				firstErr = r.err
+			}
+		case syncSkipped:
+			s.Skipped++
+		}
+	}
+
+	total := len(s.Repositories)
+	switch {
+	case s.Failed == 0 && s.Skipped == 0:
+		s.err = nil
+	case s.Succeeded == 0 && firstErr != nil:
+		s.err = fmt.Errorf("all %d repositories failed to sync: %w", total, firstErr)
+	default:
+		s.err = fmt.Errorf("%d of %d repositories did not sync: %w", s.Failed+s.Skipped, total, relaierrors.ErrPartialFetch)
+	}
+	s.ExitCode = mapErrorToExitCode(s.err)
+}
+
+// print writes a human readable summary of the run to w.
+func (s *syncSummary) print(w io.Writer) {
+	fmt.Fprintf(w, "\nSync finished in %s: %d succeeded, %d failed, %d skipped\n",
+		s.CompletedAt.Sub(s.StartedAt).Round(time.Second), s.Succeeded, s.Failed, s.Skipped)
+	for _, r := range s.Repositories {
+		detail := r.Status
+		if r.Mode != "" {
+			detail = fmt.Sprintf("%s, %s, %s", r.Status, r.Mode, time.Duration(r.DurationSeconds*float64(time.Second)).Round(time.Second))
+		}
+		if r.Error != "" {
+			fmt.Fprintf(w, "  %s (%s): %s\n", r.Repository, detail, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "  %s (%s)\n", r.Repository, detail)
+	}
+}
+
+// save writes the summary to path as JSON.
+func (s *syncSummary) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode sync summary: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write sync summary: %w", err)
+	}
+	return nil
+}
+
+// syncRepository brings one repository up to date: an interrupted full fetch
+// is resumed, a repository fetched before is fetched incrementally and any
+// other is fetched in full. It returns the mode used.
+func syncRepository(ctx context.Context, client github.Client, repoPath string, base fetchRunOptions, cfg *config.Config) (string, error) {
+	owner, repo, err := parseRepository(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	// The mode depends on the saved state, so it is chosen under the lock
+	store := base.stateStore()
+	lock, err := lockState(ctx, store, repoPath, base.lockWait)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Unlock()
+
+	mode := syncModeFor(store, repoPath)
+	runOpts := fetchRunOptions{
+		outputDir:      base.outputDir,
+		batchSize:      cfg.GetBatchSize(repoPath),
+		fetchAll:       true,
+		stateDir:       base.stateDir,
+		store:          store,
+		lockWait:       base.lockWait,
+		requestTimeout: base.requestTimeout,
+	}
+	switch mode {
+	case syncModeResume:
+		runOpts.resume = true
+	case syncModeIncremental:
+		runOpts.incremental = true
+	}
+
+	return mode, fetchRepositoryLocked(ctx, client, owner, repo, runOpts)
+}
+
+// syncModeFor chooses how sync brings repoPath up to date from its state in
+// store. State that cannot be read is left to the incremental fetch, which
+// reports how to recover it.
+func syncModeFor(store state.StateStore, repoPath string) string {
+	fetchState, err := store.LoadState(repoPath)
+	if errors.Is(err, state.ErrNoState) {
+		return syncModeFull
+	}
+	if err != nil {
+		return syncModeIncremental
+	}
+	if fetchState.Checkpoint != nil {
+		return syncModeResume
+	}
+	if fetchState.LastFetchTime.IsZero() {
+		return syncModeFull
+	}
+	return syncModeIncremental
+}
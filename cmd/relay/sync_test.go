@@ -0,0 +1,365 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/config"
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+	"github.com/sirseerhq/sirseer-relay/internal/github"
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+	"github.com/sirseerhq/sirseer-relay/internal/state"
+)
+
+func TestRunSync_Summary(t *testing.T) {
+	client := github.NewMockClientWithOptions(
+		github.WithRepositories("acme", github.Repository{Owner: "acme", Name: "api"}, github.Repository{Owner: "acme", Name: "web"}),
+	)
+
+	tests := []struct {
+		name         string
+		entries      []string
+		failures     map[string]error
+		wantRepos    []string
+		wantStatuses []string
+		wantExitCode int
+	}{
+		{
+			name:         "all repositories synced",
+			entries:      []string{"golang/go", "acme/*", "acme/api"},
+			wantRepos:    []string{"golang/go", "acme/api", "acme/web"},
+			wantStatuses: []string{syncSucceeded, syncSucceeded, syncSucceeded},
+			wantExitCode: 0,
+		},
+		{
+			name:         "some repositories failed",
+			entries:      []string{"golang/go", "acme/*"},
+			failures:     map[string]error{"acme/web": fmt.Errorf("timeout: %w", relaierrors.ErrNetworkFailure)},
+			wantRepos:    []string{"golang/go", "acme/api", "acme/web"},
+			wantStatuses: []string{syncSucceeded, syncSucceeded, syncFailed},
+			wantExitCode: 4,
+		},
+		{
+			name:         "unknown owner is reported as failed",
+			entries:      []string{"nobody/*", "golang/go"},
+			wantRepos:    []string{"nobody/*", "golang/go"},
+			wantStatuses: []string{syncFailed, syncSucceeded},
+			wantExitCode: 4,
+		},
+		{
+			name:    "every repository failed",
+			entries: []string{"golang/go", "golang/tools"},
+			failures: map[string]error{
+				"golang/go":    fmt.Errorf("bad credentials: %w", relaierrors.ErrInvalidToken),
+				"golang/tools": fmt.Errorf("bad credentials: %w", relaierrors.ErrInvalidToken),
+			},
+			wantRepos:    []string{"golang/go", "golang/tools"},
+			wantStatuses: []string{syncFailed, syncFailed},
+			wantExitCode: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := runSync(context.Background(), sharedClient(client), tt.entries, github.RepositoryFilter{}, 2, func(ctx context.Context, repoPath string) (string, error) {
+				return syncModeFull, tt.failures[repoPath]
+			})
+
+			var repos, statuses []string
+			for _, r := range summary.Repositories {
+				repos = append(repos, r.Repository)
+				statuses = append(statuses, r.Status)
+			}
+			if !reflect.DeepEqual(repos, tt.wantRepos) {
+				t.Errorf("repositories = %v, want %v", repos, tt.wantRepos)
+			}
+			if !reflect.DeepEqual(statuses, tt.wantStatuses) {
+				t.Errorf("statuses = %v, want %v", statuses, tt.wantStatuses)
+			}
+			if summary.ExitCode != tt.wantExitCode || mapErrorToExitCode(summary.err) != tt.wantExitCode {
+				t.Errorf("exit code = %d (error %v), want %d", summary.ExitCode, summary.err, tt.wantExitCode)
+			}
+		})
+	}
+}
+
+func TestRunSync_BoundsConcurrency(t *testing.T) {
+	entries := []string{"org/a", "org/b", "org/c", "org/d", "org/e", "org/f"}
+
+	var running, peak int32
+	summary := runSync(context.Background(), sharedClient(github.NewMockClient()), entries, github.RepositoryFilter{}, 2, func(ctx context.Context, repoPath string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return syncModeFull, nil
+	})
+
+	if summary.Succeeded != len(entries) {
+		t.Errorf("succeeded = %d, want %d", summary.Succeeded, len(entries))
+	}
+	if peak > 2 {
+		t.Errorf("%d repositories synced at once, want at most 2", peak)
+	}
+}
+
+func TestRunSync_SkipsAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	summary := runSync(ctx, sharedClient(github.NewMockClient()), []string{"org/a", "org/b", "org/c"}, github.RepositoryFilter{}, 1, func(ctx context.Context, repoPath string) (string, error) {
+		cancel()
+		return syncModeFull, fmt.Errorf("interrupted: %w", relaierrors.ErrPartialFetch)
+	})
+
+	if summary.Failed != 1 || summary.Skipped != 2 {
+		t.Errorf("failed = %d, skipped = %d, want 1 failed and 2 skipped", summary.Failed, summary.Skipped)
+	}
+	if summary.ExitCode != 4 {
+		t.Errorf("exit code = %d, want 4", summary.ExitCode)
+	}
+}
+
+func TestNewSyncClients(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "app.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		flagToken string
+		app       config.GitHubAppConfig
+		wantShare bool
+	}{
+		{name: "token", wantShare: true},
+		{name: "app looked up by owner", app: config.GitHubAppConfig{AppID: 1, PrivateKeyPath: keyFile}, wantShare: false},
+		{name: "app installation configured", app: config.GitHubAppConfig{AppID: 1, InstallationID: 7, PrivateKeyPath: keyFile}, wantShare: true},
+		{name: "flag token overrides app", flagToken: "flag-token", app: config.GitHubAppConfig{AppID: 1, PrivateKeyPath: keyFile}, wantShare: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.GitHub.App = tt.app
+			clients, err := newSyncClients(context.Background(), tt.flagToken, cfg, []string{"acme/api", "other/*", "acme/web"}, time.Minute)
+			if err != nil {
+				t.Fatalf("newSyncClients failed: %v", err)
+			}
+			if clients("acme") == nil || clients("other") == nil {
+				t.Fatal("expected a client for every owner")
+			}
+			if shared := clients("acme") == clients("other"); shared != tt.wantShare {
+				t.Errorf("owners share a client = %v, want %v", shared, tt.wantShare)
+			}
+		})
+	}
+}
+
+func TestSyncModeFor(t *testing.T) {
+	completed := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		state *state.FetchState
+		want  string
+	}{
+		{
+			name: "never fetched",
+			want: syncModeFull,
+		},
+		{
+			name:  "fetched before",
+			state: &state.FetchState{Repository: "org/repo", LastFetchTime: completed},
+			want:  syncModeIncremental,
+		},
+		{
+			name:  "interrupted full fetch",
+			state: &state.FetchState{Repository: "org/repo", LastFetchTime: completed, Checkpoint: &state.Checkpoint{PageNum: 3}},
+			want:  syncModeResume,
+		},
+		{
+			name:  "interrupted initial fetch",
+			state: &state.FetchState{Repository: "org/repo", Checkpoint: &state.Checkpoint{PageNum: 1}},
+			want:  syncModeResume,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			stateDir := t.TempDir()
+			if tt.state != nil {
+				if err := state.SaveState(tt.state, state.StateFilePath(stateDir, "org/repo")); err != nil {
+					t.Fatalf("failed to save state: %v", err)
+				}
+			}
+			if got := syncModeFor(state.NewFileStore(stateDir), "org/repo"); got != tt.want {
+				t.Errorf("syncModeFor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncRepository_FullThenIncremental(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	opts := fetchRunOptions{outputDir: t.TempDir()}
+	cfg := config.DefaultConfig()
+	client := github.NewMockClient()
+
+	for _, want := range []string{syncModeFull, syncModeIncremental} {
+		mode, err := syncRepository(context.Background(), client, "org/repo", opts, cfg)
+		if err != nil {
+			t.Fatalf("%s sync failed: %v", want, err)
+		}
+		if mode != want {
+			t.Errorf("mode = %s, want %s", mode, want)
+		}
+	}
+}
+
+func TestSyncRepository_StateDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	cfg := config.DefaultConfig()
+	client := github.NewMockClient()
+	first := fetchRunOptions{outputDir: t.TempDir(), stateDir: t.TempDir()}
+	second := fetchRunOptions{outputDir: t.TempDir(), stateDir: t.TempDir()}
+
+	for _, tt := range []struct {
+		opts fetchRunOptions
+		want string
+	}{
+		{first, syncModeFull},
+		{first, syncModeIncremental},
+		{second, syncModeFull},
+	} {
+		mode, err := syncRepository(context.Background(), client, "org/repo", tt.opts, cfg)
+		if err != nil {
+			t.Fatalf("%s sync failed: %v", tt.want, err)
+		}
+		if mode != tt.want {
+			t.Errorf("mode = %s, want %s", mode, tt.want)
+		}
+	}
+
+	for _, dir := range []string{first.stateDir, second.stateDir} {
+		if _, err := os.Stat(state.StateFilePath(dir, "org/repo")); err != nil {
+			t.Errorf("expected state file in %s: %v", dir, err)
+		}
+	}
+	if _, err := os.Stat(state.GetStateFilePath("org/repo")); !os.IsNotExist(err) {
+		t.Errorf("expected no state file in the default directory, got %v", err)
+	}
+}
+
+func TestSyncRepository_MetadataPerRepository(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	opts := fetchRunOptions{outputDir: t.TempDir(), stateDir: t.TempDir()}
+	cfg := config.DefaultConfig()
+	client := github.NewMockClient()
+
+	for _, mode := range []string{syncModeFull, syncModeIncremental} {
+		for _, repo := range []string{"org/api", "org/web"} {
+			if _, err := syncRepository(context.Background(), client, repo, opts, cfg); err != nil {
+				t.Fatalf("%s sync of %s failed: %v", mode, repo, err)
+			}
+		}
+	}
+
+	for _, name := range []string{"api", "web"} {
+		matches, err := filepath.Glob(filepath.Join(opts.outputDir, "org", name, "*-metadata.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matches) == 0 {
+			t.Errorf("org/%s: expected a metadata file next to its output", name)
+		}
+		for _, path := range matches {
+			data, err := os.ReadFile(path) // #nosec G304 - test file under TempDir
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fetchMetadata metadata.FetchMetadata
+			if err := json.Unmarshal(data, &fetchMetadata); err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+			if got := fetchMetadata.Parameters.Repository; got != name {
+				t.Errorf("%s records repository %q, want %q", path, got, name)
+			}
+		}
+	}
+	if _, err := os.Stat("fetch-metadata.json"); !os.IsNotExist(err) {
+		t.Errorf("expected no metadata file in the working directory, got %v", err)
+	}
+
+	// Each repository's incremental fetch links to its own full fetch
+	store := state.NewFileStore(opts.stateDir)
+	for _, repo := range []string{"org/api", "org/web"} {
+		latest, err := store.LoadLatestMetadata(repo)
+		if err != nil || latest == nil {
+			t.Fatalf("%s: LoadLatestMetadata() = %v, %v", repo, latest, err)
+		}
+		if !latest.Incremental || latest.PreviousFetch == nil {
+			t.Errorf("%s: expected incremental metadata with previous_fetch, got incremental=%v previous_fetch=%v", repo, latest.Incremental, latest.PreviousFetch)
+		}
+	}
+}
+
+func TestSyncRepository_StateLocked(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	opts := fetchRunOptions{outputDir: t.TempDir(), stateDir: t.TempDir()}
+	client := github.NewMockClient()
+
+	held, err := state.AcquireLock(context.Background(), state.StateFilePath(opts.stateDir, "org/repo"), 0)
+	if err != nil {
+		t.Fatalf("failed to take the lock: %v", err)
+	}
+	defer held.Unlock()
+
+	if _, err := syncRepository(context.Background(), client, "org/repo", opts, config.DefaultConfig()); !errors.Is(err, state.ErrLocked) {
+		t.Errorf("expected ErrLocked, got %v", err)
+	}
+	if client.CallCount != 0 {
+		t.Errorf("expected no API calls while locked, got %d", client.CallCount)
+	}
+}
@@ -1,6 +1,9 @@
 #!/bin/bash
 # Multi-repository fetch script for sirseer-relay
 # Fetch multiple repositories with proper error handling and reporting
+#
+# For repositories listed in a config file, `sirseer-relay sync` does the same
+# in a single process that shares one rate limit budget across repositories.
 
 set -euo pipefail
 
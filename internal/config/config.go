@@ -87,6 +87,11 @@ func LoadConfig(configPath string) (*Config, error) {
 
 	// Expand paths
 	cfg.Defaults.StateDir = expandPath(cfg.Defaults.StateDir)
+	cfg.GitHub.App.PrivateKeyPath = expandPath(cfg.GitHub.App.PrivateKeyPath)
+	cfg.GitHub.TokenFile = expandPath(cfg.GitHub.TokenFile)
+	for i := range cfg.GitHub.Tokens {
+		cfg.GitHub.Tokens[i].App.PrivateKeyPath = expandPath(cfg.GitHub.Tokens[i].App.PrivateKeyPath)
+	}
 
 	return cfg, nil
 }
@@ -137,6 +142,29 @@ func applyEnvOverrides(cfg *Config) {
 		cfg.GitHub.GraphQLEndpoint = endpoint
 	}
 
+	// Token helpers
+	if command := os.Getenv("SIRSEER_TOKEN_COMMAND"); command != "" {
+		cfg.GitHub.TokenCommand = command
+	}
+	if tokenFile := os.Getenv("GITHUB_TOKEN_FILE"); tokenFile != "" {
+		cfg.GitHub.TokenFile = tokenFile
+	}
+
+	// GitHub App authentication
+	if appID := os.Getenv("GITHUB_APP_ID"); appID != "" {
+		if id, err := parsePositiveInt(appID); err == nil {
+			cfg.GitHub.App.AppID = int64(id)
+		}
+	}
+	if keyPath := os.Getenv("GITHUB_APP_PRIVATE_KEY_PATH"); keyPath != "" {
+		cfg.GitHub.App.PrivateKeyPath = keyPath
+	}
+	if installationID := os.Getenv("GITHUB_APP_INSTALLATION_ID"); installationID != "" {
+		if id, err := parsePositiveInt(installationID); err == nil {
+			cfg.GitHub.App.InstallationID = int64(id)
+		}
+	}
+
 	// Defaults
 	if batchSize := os.Getenv("SIRSEER_BATCH_SIZE"); batchSize != "" {
 		if size, err := parsePositiveInt(batchSize); err == nil {
@@ -146,11 +174,28 @@ func applyEnvOverrides(cfg *Config) {
 	if stateDir := os.Getenv("SIRSEER_STATE_DIR"); stateDir != "" {
 		cfg.Defaults.StateDir = stateDir
 	}
+	if stateStore := os.Getenv("SIRSEER_STATE_STORE"); stateStore != "" {
+		cfg.Defaults.StateStore = stateStore
+	}
 
 	// Rate limit settings
 	if autoWait := os.Getenv("SIRSEER_RATE_LIMIT_AUTO_WAIT"); autoWait != "" {
 		cfg.RateLimit.AutoWait = parseBool(autoWait)
 	}
+
+	// Retry settings
+	if attempts := os.Getenv("SIRSEER_RETRY_MAX_ATTEMPTS"); attempts != "" {
+		if n, err := parsePositiveInt(attempts); err == nil {
+			cfg.Retry.MaxAttempts = n
+		}
+	}
+
+	// Sync settings
+	if concurrency := os.Getenv("SIRSEER_SYNC_CONCURRENCY"); concurrency != "" {
+		if n, err := parsePositiveInt(concurrency); err == nil {
+			cfg.Sync.Concurrency = n
+		}
+	}
 }
 
 // expandPath expands ~ and environment variables in paths
@@ -206,11 +251,71 @@ func (c *Config) Validate() error {
 	if c.Defaults.BatchSize > 100 {
 		return fmt.Errorf("default batch size %d exceeds GitHub API limit of 100", c.Defaults.BatchSize)
 	}
+	switch c.Defaults.StateStore {
+	case "", "file", "sqlite":
+	default:
+		return fmt.Errorf("state store must be file or sqlite, got: %s", c.Defaults.StateStore)
+	}
 	if c.GitHub.APIEndpoint == "" {
 		return fmt.Errorf("GitHub API endpoint cannot be empty")
 	}
 	if c.GitHub.GraphQLEndpoint == "" {
 		return fmt.Errorf("GitHub GraphQL endpoint cannot be empty")
 	}
+	if c.GitHub.App.AppID < 0 || c.GitHub.App.InstallationID < 0 {
+		return fmt.Errorf("GitHub App and installation IDs cannot be negative")
+	}
+	if c.GitHub.App.Enabled() && c.GitHub.App.PrivateKeyPath == "" {
+		return fmt.Errorf("GitHub App private key path is required when an App ID is set")
+	}
+	if c.GitHub.TokenCommand != "" && c.GitHub.TokenFile != "" {
+		return fmt.Errorf("token_command and token_file cannot both be set")
+	}
+	if err := validateTokenPool(c.GitHub.Tokens); err != nil {
+		return err
+	}
+	if c.Retry.MaxAttempts < 1 {
+		return fmt.Errorf("retry max attempts must be at least 1, got: %d", c.Retry.MaxAttempts)
+	}
+	if c.Retry.BaseDelay < 0 || c.Retry.MaxDelay < 0 {
+		return fmt.Errorf("retry delays cannot be negative")
+	}
+	if c.Retry.MaxDelay < c.Retry.BaseDelay {
+		return fmt.Errorf("retry max delay %s is shorter than base delay %s", c.Retry.MaxDelay, c.Retry.BaseDelay)
+	}
+	if c.Sync.Concurrency < 1 {
+		return fmt.Errorf("sync concurrency must be at least 1, got: %d", c.Sync.Concurrency)
+	}
+	for _, entry := range c.Sync.Repositories {
+		if owner, name, ok := strings.Cut(entry, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return fmt.Errorf("sync repository %q must have the form owner/repo or owner/*", entry)
+		}
+	}
+	switch strings.ToLower(c.Sync.Filter.Visibility) {
+	case "", "public", "private", "internal":
+	default:
+		return fmt.Errorf("sync filter visibility must be public, private or internal, got: %s", c.Sync.Filter.Visibility)
+	}
+	return nil
+}
+
+// validateTokenPool checks that every token pool entry names exactly one
+// credential and that names are unique.
+func validateTokenPool(tokens []TokenConfig) error {
+	names := make(map[string]bool)
+	for i, token := range tokens {
+		if (token.Env != "") == token.App.Enabled() {
+			return fmt.Errorf("token pool entry %d must set exactly one of env or app", i+1)
+		}
+		if token.App.Enabled() && token.App.PrivateKeyPath == "" {
+			return fmt.Errorf("token pool entry %d: GitHub App private key path is required", i+1)
+		}
+		if token.Name != "" {
+			if names[token.Name] {
+				return fmt.Errorf("token pool name %q is used more than once", token.Name)
+			}
+			names[token.Name] = true
+		}
+	}
 	return nil
 }
@@ -17,8 +17,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -53,6 +55,16 @@ func TestDefaultConfig(t *testing.T) {
 	if !cfg.RateLimit.ShowProgress {
 		t.Error("ShowProgress = false, want true")
 	}
+
+	// Test retry defaults
+	if cfg.Retry.MaxAttempts != 5 || cfg.Retry.BaseDelay != time.Second || cfg.Retry.MaxDelay != 30*time.Second {
+		t.Errorf("Retry = %+v, want 5 attempts, 1s base delay and 30s max delay", cfg.Retry)
+	}
+
+	// Test sync defaults
+	if cfg.Sync.Concurrency != 4 || len(cfg.Sync.Repositories) != 0 {
+		t.Errorf("Sync = %+v, want concurrency 4 and no repositories", cfg.Sync)
+	}
 }
 
 func TestLoadConfigFile(t *testing.T) {
@@ -78,6 +90,20 @@ repositories:
 rate_limit:
   auto_wait: false
   show_progress: false
+
+retry:
+  max_attempts: 8
+  base_delay: 500ms
+  max_delay: 1m
+
+sync:
+  concurrency: 2
+  repositories:
+    - org/repo
+    - other-org/*
+  filter:
+    exclude_archived: true
+    topics: [backend]
 `
 	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
 		t.Fatalf("Failed to write test config: %v", err)
@@ -116,6 +142,19 @@ rate_limit:
 	if cfg.RateLimit.AutoWait {
 		t.Error("AutoWait = true, want false")
 	}
+
+	// Verify retry settings
+	if cfg.Retry.MaxAttempts != 8 || cfg.Retry.BaseDelay != 500*time.Millisecond || cfg.Retry.MaxDelay != time.Minute {
+		t.Errorf("Retry = %+v, want 8 attempts, 500ms base delay and 1m max delay", cfg.Retry)
+	}
+
+	// Verify sync settings
+	if cfg.Sync.Concurrency != 2 || !reflect.DeepEqual(cfg.Sync.Repositories, []string{"org/repo", "other-org/*"}) {
+		t.Errorf("Sync = %+v, want concurrency 2 with org/repo and other-org/*", cfg.Sync)
+	}
+	if !cfg.Sync.Filter.ExcludeArchived || !reflect.DeepEqual(cfg.Sync.Filter.Topics, []string{"backend"}) {
+		t.Errorf("Sync.Filter = %+v, want archived repositories excluded and topic backend", cfg.Sync.Filter)
+	}
 }
 
 func TestEnvironmentOverrides(t *testing.T) {
@@ -124,14 +163,26 @@ func TestEnvironmentOverrides(t *testing.T) {
 	os.Setenv("GITHUB_GRAPHQL_ENDPOINT", "https://custom.graphql.com")
 	os.Setenv("SIRSEER_BATCH_SIZE", "75")
 	os.Setenv("SIRSEER_STATE_DIR", "/env/state")
+	os.Setenv("SIRSEER_STATE_STORE", "sqlite")
 	os.Setenv("SIRSEER_RATE_LIMIT_AUTO_WAIT", "false")
+	os.Setenv("SIRSEER_RETRY_MAX_ATTEMPTS", "3")
+	os.Setenv("GITHUB_APP_ID", "1234")
+	os.Setenv("GITHUB_APP_PRIVATE_KEY_PATH", "/env/app.pem")
+	os.Setenv("GITHUB_TOKEN_FILE", "/env/github-token")
+	os.Setenv("SIRSEER_SYNC_CONCURRENCY", "8")
 
 	defer func() {
 		os.Unsetenv("GITHUB_API_ENDPOINT")
 		os.Unsetenv("GITHUB_GRAPHQL_ENDPOINT")
 		os.Unsetenv("SIRSEER_BATCH_SIZE")
 		os.Unsetenv("SIRSEER_STATE_DIR")
+		os.Unsetenv("SIRSEER_STATE_STORE")
 		os.Unsetenv("SIRSEER_RATE_LIMIT_AUTO_WAIT")
+		os.Unsetenv("SIRSEER_RETRY_MAX_ATTEMPTS")
+		os.Unsetenv("GITHUB_APP_ID")
+		os.Unsetenv("GITHUB_APP_PRIVATE_KEY_PATH")
+		os.Unsetenv("GITHUB_TOKEN_FILE")
+		os.Unsetenv("SIRSEER_SYNC_CONCURRENCY")
 	}()
 
 	cfg, err := LoadConfig("")
@@ -152,9 +203,24 @@ func TestEnvironmentOverrides(t *testing.T) {
 	if cfg.Defaults.StateDir != "/env/state" {
 		t.Errorf("StateDir = %s, want /env/state", cfg.Defaults.StateDir)
 	}
+	if cfg.Defaults.StateStore != "sqlite" {
+		t.Errorf("StateStore = %s, want sqlite", cfg.Defaults.StateStore)
+	}
 	if cfg.RateLimit.AutoWait {
 		t.Error("AutoWait = true, want false")
 	}
+	if cfg.Retry.MaxAttempts != 3 {
+		t.Errorf("Retry.MaxAttempts = %d, want 3", cfg.Retry.MaxAttempts)
+	}
+	if cfg.GitHub.App.AppID != 1234 || cfg.GitHub.App.PrivateKeyPath != "/env/app.pem" {
+		t.Errorf("App = %+v, want app 1234 with key /env/app.pem", cfg.GitHub.App)
+	}
+	if cfg.GitHub.TokenFile != "/env/github-token" {
+		t.Errorf("TokenFile = %s, want /env/github-token", cfg.GitHub.TokenFile)
+	}
+	if cfg.Sync.Concurrency != 8 {
+		t.Errorf("Sync.Concurrency = %d, want 8", cfg.Sync.Concurrency)
+	}
 }
 
 func TestGetBatchSize(t *testing.T) {
@@ -219,6 +285,106 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: "GitHub API endpoint cannot be empty",
 		},
+		{
+			name: "unknown state store",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Defaults.StateStore = "postgres"
+				return cfg
+			}(),
+			wantErr: "state store must be file or sqlite",
+		},
+		{
+			name: "retries without attempts",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Retry.MaxAttempts = 0
+				return cfg
+			}(),
+			wantErr: "retry max attempts must be at least 1",
+		},
+		{
+			name: "retry max delay below base delay",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Retry.MaxDelay = cfg.Retry.BaseDelay / 2
+				return cfg
+			}(),
+			wantErr: "is shorter than base delay",
+		},
+		{
+			name: "GitHub App without private key",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.GitHub.App.AppID = 1234
+				return cfg
+			}(),
+			wantErr: "private key path is required",
+		},
+		{
+			name: "token command and file",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.GitHub.TokenCommand = "gh auth token"
+				cfg.GitHub.TokenFile = "/etc/token"
+				return cfg
+			}(),
+			wantErr: "cannot both be set",
+		},
+		{
+			name: "token pool entry without credential",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.GitHub.Tokens = []TokenConfig{{Env: "TOKEN_A"}, {Name: "empty"}}
+				return cfg
+			}(),
+			wantErr: "token pool entry 2 must set exactly one of env or app",
+		},
+		{
+			name: "token pool duplicate names",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.GitHub.Tokens = []TokenConfig{{Name: "bot", Env: "TOKEN_A"}, {Name: "bot", Env: "TOKEN_B"}}
+				return cfg
+			}(),
+			wantErr: "is used more than once",
+		},
+		{
+			name: "sync without concurrency",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Sync.Concurrency = 0
+				return cfg
+			}(),
+			wantErr: "sync concurrency must be at least 1",
+		},
+		{
+			name: "sync repositories and wildcards",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Sync.Repositories = []string{"golang/go", "kubernetes/*"}
+				return cfg
+			}(),
+			wantErr: "",
+		},
+		{
+			name: "sync repository without owner",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Sync.Repositories = []string{"golang/go", "tools"}
+				return cfg
+			}(),
+			wantErr: `sync repository "tools" must have the form owner/repo or owner/*`,
+		},
+		{
+			name: "sync filter with unknown visibility",
+			config: func() *Config {
+				cfg := DefaultConfig()
+				cfg.Sync.Filter.Visibility = "secret"
+				return cfg
+			}(),
+			wantErr: "sync filter visibility must be public, private or internal",
+		},
 		{
 			name: "empty GraphQL endpoint",
 			config: &Config{
@@ -17,6 +17,8 @@
 // YAML configuration files, environment variables, or command-line flags.
 package config
 
+import "time"
+
 // Config represents the complete configuration for sirseer-relay.
 // It consolidates settings from various sources and provides a unified
 // interface for accessing configuration values throughout the application.
@@ -25,15 +27,55 @@ type Config struct {
 	Defaults     DefaultsConfig        `yaml:"defaults"`
 	Repositories map[string]RepoConfig `yaml:"repositories"`
 	RateLimit    RateLimitConfig       `yaml:"rate_limit"`
+	Retry        RetryConfig           `yaml:"retry"`
+	Sync         SyncConfig            `yaml:"sync"`
 }
 
 // GitHubConfig contains GitHub-specific settings including API endpoints
 // and authentication configuration. This allows easy configuration for
 // GitHub Enterprise deployments by specifying custom endpoints.
 type GitHubConfig struct {
-	APIEndpoint     string `yaml:"api_endpoint"`
-	GraphQLEndpoint string `yaml:"graphql_endpoint"`
-	TokenEnv        string `yaml:"token_env"`
+	APIEndpoint     string          `yaml:"api_endpoint"`
+	GraphQLEndpoint string          `yaml:"graphql_endpoint"`
+	TokenEnv        string          `yaml:"token_env"`
+	App             GitHubAppConfig `yaml:"app"`
+
+	// TokenCommand is a credential helper command, such as "gh auth token",
+	// whose output is the token. TokenFile is a file holding the token. Both
+	// are read at startup and again when GitHub rejects the token, and take
+	// precedence over TokenEnv.
+	TokenCommand string `yaml:"token_command"`
+	TokenFile    string `yaml:"token_file"`
+
+	// Tokens lists the credentials of a token pool. When set, requests
+	// rotate across them by remaining rate limit budget instead of using
+	// TokenEnv or App.
+	Tokens []TokenConfig `yaml:"tokens"`
+}
+
+// TokenConfig is one credential of a token pool: either a token read from
+// the environment variable Env or a GitHub App installation. Name identifies
+// the credential in fetch metadata and defaults to its position in the pool.
+type TokenConfig struct {
+	Name string          `yaml:"name"`
+	Env  string          `yaml:"env"`
+	App  GitHubAppConfig `yaml:"app"`
+}
+
+// GitHubAppConfig configures authentication as a GitHub App installation
+// instead of with a personal access token. Installation tokens are created
+// from the App's private key and refreshed before they expire. When
+// InstallationID is zero, the installation on the owner of the fetched
+// repository is used.
+type GitHubAppConfig struct {
+	AppID          int64  `yaml:"app_id"`
+	PrivateKeyPath string `yaml:"private_key_path"`
+	InstallationID int64  `yaml:"installation_id"`
+}
+
+// Enabled reports whether GitHub App authentication is configured.
+func (a GitHubAppConfig) Enabled() bool {
+	return a.AppID != 0
 }
 
 // DefaultsConfig contains default settings that apply to all fetch operations
@@ -43,6 +85,11 @@ type DefaultsConfig struct {
 	BatchSize    int    `yaml:"batch_size"`
 	OutputFormat string `yaml:"output_format"`
 	StateDir     string `yaml:"state_dir"`
+
+	// StateStore selects how state is kept in StateDir: "file" for one
+	// state file per repository, or "sqlite" for a single database that
+	// also holds the run history and metadata of every fetch.
+	StateStore string `yaml:"state_store"`
 }
 
 // RepoConfig contains repository-specific overrides that allow fine-tuning
@@ -62,6 +109,36 @@ type RateLimitConfig struct {
 	ShowProgress bool `yaml:"show_progress"`
 }
 
+// RetryConfig controls how API requests that fail with a transient error,
+// such as a 5xx response, a dropped connection or a secondary rate limit,
+// are retried. Delays double from BaseDelay up to MaxDelay between attempts.
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseDelay   time.Duration `yaml:"base_delay"`
+	MaxDelay    time.Duration `yaml:"max_delay"`
+}
+
+// SyncConfig lists the repositories kept up to date by the sync command.
+// Entries have the form owner/repo, or owner/* for every repository of an
+// organization or user that matches Filter. Up to Concurrency repositories
+// are fetched at once.
+type SyncConfig struct {
+	Repositories []string               `yaml:"repositories"`
+	Concurrency  int                    `yaml:"concurrency"`
+	Filter       RepositoryFilterConfig `yaml:"filter"`
+}
+
+// RepositoryFilterConfig selects which repositories an owner/* entry stands
+// for. Visibility is public, private or internal; a repository must carry
+// at least one of Topics; PushedSince accepts the same formats as --since.
+type RepositoryFilterConfig struct {
+	ExcludeArchived bool     `yaml:"exclude_archived"`
+	ExcludeForks    bool     `yaml:"exclude_forks"`
+	Visibility      string   `yaml:"visibility"`
+	Topics          []string `yaml:"topics"`
+	PushedSince     string   `yaml:"pushed_since"`
+}
+
 // DefaultConfig returns a Config with sensible defaults suitable for most
 // use cases. These defaults are optimized for public GitHub.com usage but
 // can be overridden for GitHub Enterprise or special requirements.
@@ -76,11 +153,20 @@ func DefaultConfig() *Config {
 			BatchSize:    50,
 			OutputFormat: "ndjson",
 			StateDir:     "~/.sirseer/state",
+			StateStore:   "file",
 		},
 		Repositories: make(map[string]RepoConfig),
 		RateLimit: RateLimitConfig{
 			AutoWait:     true,
 			ShowProgress: true,
 		},
+		Retry: RetryConfig{
+			MaxAttempts: 5,
+			BaseDelay:   time.Second,
+			MaxDelay:    30 * time.Second,
+		},
+		Sync: SyncConfig{
+			Concurrency: 4,
+		},
 	}
 }
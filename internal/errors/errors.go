@@ -28,6 +28,10 @@ var (
 	// Maps to exit code 2.
 	ErrRepoNotFound = errors.New("repository not found")
 
+	// ErrPullRequestNotFound indicates a pull request requested by number
+	// does not exist in the repository. Maps to exit code 2.
+	ErrPullRequestNotFound = errors.New("pull request not found")
+
 	// ErrNetworkFailure indicates a network connection problem.
 	// Maps to exit code 3.
 	ErrNetworkFailure = errors.New("network connection failed")
@@ -40,4 +44,9 @@ var (
 	// This typically happens with large repositories and requires reducing batch size.
 	// Maps to exit code 1 (handled internally with retry).
 	ErrQueryComplexity = errors.New("graphql query complexity exceeded")
+
+	// ErrPartialFetch indicates the fetch stopped early, for example because
+	// it reached its maximum run duration, after saving enough progress to be
+	// continued. Maps to exit code 4.
+	ErrPartialFetch = errors.New("fetch stopped before completion")
 )
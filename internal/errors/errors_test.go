@@ -76,6 +76,7 @@ func TestErrorMessages(t *testing.T) {
 	}{
 		{ErrInvalidToken, "invalid github token"},
 		{ErrRepoNotFound, "repository not found"},
+		{ErrPullRequestNotFound, "pull request not found"},
 		{ErrNetworkFailure, "network connection failed"},
 		{ErrRateLimit, "github rate limit exceeded"},
 	}
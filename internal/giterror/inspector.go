@@ -16,9 +16,14 @@ package giterror
 
 import (
 	"errors"
+	"regexp"
 	"strings"
 )
 
+// serverErrorPattern matches the HTTP status of a 5xx response as reported
+// by the GraphQL client.
+var serverErrorPattern = regexp.MustCompile(`status code: 5\d\d`)
+
 // Inspector provides methods for analyzing GitHub API errors.
 type Inspector interface {
 	// IsAuthError returns true if the error represents an authentication or authorization failure.
@@ -35,6 +40,12 @@ type Inspector interface {
 
 	// IsNetworkError returns true if the error represents a network connectivity error.
 	IsNetworkError(err error) bool
+
+	// IsTransientError returns true if the request is likely to succeed when
+	// retried: server errors, dropped connections, timeouts, secondary rate
+	// limits and GraphQL resource limit errors. A host that cannot be
+	// resolved or refuses the connection is not retried.
+	IsTransientError(err error) bool
 }
 
 // GitHubErrorInspector implements the Inspector interface for GitHub API errors.
@@ -107,6 +118,45 @@ func (i *GitHubErrorInspector) IsNetworkError(err error) bool {
 		strings.Contains(errStr, "network is unreachable")
 }
 
+// IsTransientError checks if the error is worth retrying.
+func (i *GitHubErrorInspector) IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	errStr := strings.ToLower(err.Error())
+	if isUnreachableHost(errStr) {
+		return false
+	}
+	return serverErrorPattern.MatchString(errStr) ||
+		isSecondaryRateLimit(errStr) ||
+		strings.Contains(errStr, "timeout") ||
+		strings.Contains(errStr, "tls handshake") ||
+		strings.Contains(errStr, "connection reset") ||
+		strings.Contains(errStr, "broken pipe") ||
+		strings.Contains(errStr, "unexpected eof") ||
+		strings.Contains(errStr, "resource limits") ||
+		strings.Contains(errStr, "resource_limits_exceeded")
+}
+
+// isUnreachableHost reports whether a lowercased error message says the host
+// could not be resolved or refused the connection. These usually mean a
+// mistyped endpoint or no network at all, so they are reported at once
+// rather than retried.
+func isUnreachableHost(errStr string) bool {
+	return strings.Contains(errStr, "no such host") ||
+		strings.Contains(errStr, "name resolution") ||
+		strings.Contains(errStr, "server misbehaving") ||
+		strings.Contains(errStr, "connection refused")
+}
+
+// isSecondaryRateLimit reports whether a lowercased error message is one of
+// GitHub's secondary rate limits, which throttle bursts of requests rather
+// than exhausting the hourly budget and clear after a short backoff.
+func isSecondaryRateLimit(errStr string) bool {
+	return strings.Contains(errStr, "secondary rate limit") ||
+		strings.Contains(errStr, "abuse detection")
+}
+
 // ErrorChainInspector wraps a base inspector and adds support for checking errors
 // in the error chain using errors.Is and errors.As.
 type ErrorChainInspector struct {
@@ -164,3 +214,12 @@ func (e *ErrorChainInspector) IsNetworkError(err error) bool {
 	}
 	return e.base.IsNetworkError(err)
 }
+
+// IsTransientError checks the error chain first, then falls back to base inspector.
+func (e *ErrorChainInspector) IsTransientError(err error) bool {
+	var transientErr interface{ IsTransientError() bool }
+	if errors.As(err, &transientErr) && transientErr.IsTransientError() {
+		return true
+	}
+	return e.base.IsTransientError(err)
+}
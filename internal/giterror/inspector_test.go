@@ -285,6 +285,90 @@ func TestGitHubErrorInspector_IsNetworkError(t *testing.T) {
 	}
 }
 
+func TestGitHubErrorInspector_IsTransientError(t *testing.T) {
+	inspector := NewInspector()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "bad gateway",
+			err:  errors.New(`non-200 OK status code: 502 Bad Gateway body: "Bad Gateway"`),
+			want: true,
+		},
+		{
+			name: "service unavailable",
+			err:  errors.New(`non-200 OK status code: 503 Service Unavailable body: ""`),
+			want: true,
+		},
+		{
+			name: "connection reset",
+			err:  errors.New("read tcp 10.0.0.1:5000->140.82.112.6:443: read: connection reset by peer"),
+			want: true,
+		},
+		{
+			name: "unexpected eof",
+			err:  errors.New(`Post "https://api.github.com/graphql": unexpected EOF`),
+			want: true,
+		},
+		{
+			name: "timeout",
+			err:  errors.New(`Post "https://api.github.com/graphql": dial tcp 140.82.112.6:443: i/o timeout`),
+			want: true,
+		},
+		{
+			name: "connection refused",
+			err:  errors.New("dial tcp 127.0.0.1:443: connection refused"),
+			want: false,
+		},
+		{
+			name: "no such host",
+			err:  errors.New(`Post "https://github.example.invalid/api/graphql": dial tcp: lookup github.example.invalid: no such host`),
+			want: false,
+		},
+		{
+			name: "dns failure",
+			err:  errors.New("dial tcp: lookup api.github.com: temporary failure in name resolution"),
+			want: false,
+		},
+		{
+			name: "secondary rate limit",
+			err:  errors.New(`non-200 OK status code: 403 Forbidden body: "You have exceeded a secondary rate limit"`),
+			want: true,
+		},
+		{
+			name: "resource limits exceeded",
+			err:  errors.New("Resource limits for this query exceeded."),
+			want: true,
+		},
+		{
+			name: "primary rate limit",
+			err:  errors.New("API rate limit exceeded for user ID 1"),
+			want: false,
+		},
+		{
+			name: "bad credentials",
+			err:  errors.New(`non-200 OK status code: 401 Unauthorized body: "Bad credentials"`),
+			want: false,
+		},
+		{
+			name: "nil error",
+			err:  nil,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inspector.IsTransientError(tt.err); got != tt.want {
+				t.Errorf("IsTransientError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // Custom error types for testing ErrorChainInspector
 type authError struct{}
 
@@ -0,0 +1,344 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/pkg/version"
+)
+
+const (
+	// appJWTLifetime is how long the JWT used to authenticate as a GitHub App
+	// is valid. GitHub accepts at most ten minutes.
+	appJWTLifetime = 9 * time.Minute
+
+	// appJWTClockSkew backdates the JWT issue time to tolerate clock drift
+	// between this host and GitHub.
+	appJWTClockSkew = time.Minute
+
+	// tokenRefreshMargin is how long before expiry an installation token is
+	// replaced, so a request never starts with a token about to expire.
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+// TokenSource supplies the token sent with every API request. It is called
+// before each request, so implementations can refresh short-lived tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// Invalidator is implemented by token sources that can obtain a new token
+// when GitHub rejects the current one. The client calls Invalidate after an
+// authentication failure and retries the request once with the new token.
+type Invalidator interface {
+	Invalidate()
+}
+
+// ResolvedToken is a TokenSource that obtains its token from a resolve
+// function, such as a credential helper command or a token file, and caches
+// it. Invalidate drops the cached token, so a rotated credential is picked
+// up by resolving again without restarting the fetch.
+type ResolvedToken struct {
+	resolve func(ctx context.Context) (string, error)
+
+	mu    sync.Mutex
+	token string
+}
+
+// NewResolvedToken creates a ResolvedToken that calls resolve whenever it
+// needs a token.
+func NewResolvedToken(resolve func(ctx context.Context) (string, error)) *ResolvedToken {
+	return &ResolvedToken{resolve: resolve}
+}
+
+// Token implements TokenSource.
+func (t *ResolvedToken) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token == "" {
+		token, err := t.resolve(ctx)
+		if err != nil {
+			return "", err
+		}
+		t.token = token
+	}
+	return t.token, nil
+}
+
+// Invalidate implements Invalidator.
+func (t *ResolvedToken) Invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = ""
+}
+
+// StaticToken is a TokenSource that always returns the same token, such as
+// a personal access token.
+type StaticToken string
+
+// Token implements TokenSource.
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// AppConfig identifies a GitHub App installation to authenticate as.
+type AppConfig struct {
+	// AppID is the numeric ID of the GitHub App.
+	AppID int64
+
+	// PrivateKey is the App's PEM encoded RSA private key.
+	PrivateKey []byte
+
+	// InstallationID selects the installation to use. When zero, the
+	// installation is looked up by Owner.
+	InstallationID int64
+
+	// Owner is the organization or user the App is installed on. It is only
+	// used when InstallationID is zero.
+	Owner string
+
+	// APIEndpoint is the REST API base URL, such as https://api.github.com
+	// or https://github.example.com/api/v3 for GitHub Enterprise Server.
+	APIEndpoint string
+
+	// HTTPClient sends the token requests. http.DefaultClient is used when nil.
+	HTTPClient *http.Client
+}
+
+// AppTokenSource authenticates as a GitHub App installation. It signs a JWT
+// with the App's private key, exchanges it for an installation token and
+// replaces that token shortly before it expires, so fetches can run longer
+// than the hour an installation token is valid for.
+type AppTokenSource struct {
+	appID       int64
+	key         *rsa.PrivateKey
+	owner       string
+	apiEndpoint string
+	httpClient  *http.Client
+	now         func() time.Time
+
+	mu             sync.Mutex
+	installationID int64
+	token          string
+	expiresAt      time.Time
+}
+
+// NewAppTokenSource creates a token source for the App installation described
+// by cfg. No request is made until the first token is needed.
+func NewAppTokenSource(cfg AppConfig) (*AppTokenSource, error) {
+	if cfg.AppID <= 0 {
+		return nil, fmt.Errorf("GitHub App ID must be positive, got: %d", cfg.AppID)
+	}
+	if cfg.InstallationID <= 0 && cfg.Owner == "" {
+		return nil, fmt.Errorf("GitHub App installation ID or owner is required")
+	}
+	key, err := parsePrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := cfg.APIEndpoint
+	if endpoint == "" {
+		endpoint = "https://api.github.com"
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &AppTokenSource{
+		appID:          cfg.AppID,
+		key:            key,
+		owner:          cfg.Owner,
+		apiEndpoint:    strings.TrimSuffix(endpoint, "/"),
+		httpClient:     httpClient,
+		now:            time.Now,
+		installationID: cfg.InstallationID,
+	}, nil
+}
+
+// Token returns a valid installation token, creating a new one when there is
+// none yet or the current one is about to expire.
+func (s *AppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.token != "" && now.Add(tokenRefreshMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	jwt, err := s.signJWT(now)
+	if err != nil {
+		return "", err
+	}
+
+	if s.installationID == 0 {
+		id, err := s.findInstallation(ctx, jwt)
+		if err != nil {
+			return "", err
+		}
+		s.installationID = id
+	}
+
+	var resp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", s.installationID)
+	if err := s.appRequest(ctx, http.MethodPost, path, jwt, &resp); err != nil {
+		return "", fmt.Errorf("failed to create GitHub App installation token: %w", err)
+	}
+	if resp.Token == "" {
+		return "", fmt.Errorf("failed to create GitHub App installation token: response contained no token")
+	}
+
+	s.token = resp.Token
+	s.expiresAt = resp.ExpiresAt
+	return s.token, nil
+}
+
+// Invalidate implements Invalidator by discarding the installation token,
+// for example after the installation's permissions changed.
+func (s *AppTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+// findInstallation looks up the ID of the App's installation on the
+// configured owner, trying it as an organization first and then as a user.
+func (s *AppTokenSource) findInstallation(ctx context.Context, jwt string) (int64, error) {
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	err := s.appRequest(ctx, http.MethodGet, "/orgs/"+s.owner+"/installation", jwt, &installation)
+	var statusErr *appStatusError
+	if errors.As(err, &statusErr) && statusErr.status == http.StatusNotFound {
+		err = s.appRequest(ctx, http.MethodGet, "/users/"+s.owner+"/installation", jwt, &installation)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find GitHub App installation for %s: %w", s.owner, err)
+	}
+	return installation.ID, nil
+}
+
+// signJWT creates the RS256 signed JWT that authenticates as the App itself.
+func (s *AppTokenSource) signJWT(now time.Time) (string, error) {
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	claims := map[string]interface{}{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": fmt.Sprintf("%d", s.appID),
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// appStatusError reports a non-2xx response from the GitHub App endpoints.
+// The status is formatted like the GraphQL client's errors so the error
+// inspector classifies it the same way.
+type appStatusError struct {
+	status int
+	body   string
+}
+
+func (e *appStatusError) Error() string {
+	return fmt.Sprintf("non-2xx status code: %d %s body: %q", e.status, http.StatusText(e.status), e.body)
+}
+
+// appRequest sends a REST request authenticated with the App's JWT and
+// decodes the JSON response into out.
+func (s *AppTokenSource) appRequest(ctx context.Context, method, path, jwt string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, s.apiEndpoint+path, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", fmt.Sprintf("sirseer-relay/%s", version.Version))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &appStatusError{status: resp.StatusCode, body: string(bytes.TrimSpace(body))}
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// parsePrivateKey decodes a PEM encoded RSA private key in PKCS#1 form, as
+// GitHub generates it, or in PKCS#8 form.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key must be an RSA key")
+	}
+	return key, nil
+}
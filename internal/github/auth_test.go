@@ -0,0 +1,242 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+)
+
+// newTestAppKey returns a PEM encoded RSA key along with the key itself.
+func newTestAppKey(t *testing.T) ([]byte, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	return pem.EncodeToMemory(block), key
+}
+
+// verifyAppJWT checks the signature of a JWT and returns its claims.
+func verifyAppJWT(t *testing.T, jwt string, key *rsa.PrivateKey) map[string]interface{} {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("malformed JWT %q", jwt)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("invalid JWT signature encoding: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("JWT signature does not verify: %v", err)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("invalid JWT payload encoding: %v", err)
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("invalid JWT claims: %v", err)
+	}
+	return claims
+}
+
+func TestAppTokenSource_DiscoversInstallationAndRefreshes(t *testing.T) {
+	pemKey, key := newTestAppKey(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour).Format(time.RFC3339)
+
+	var exchanges int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/octo/installation", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not Found"}`)
+	})
+	mux.HandleFunc("/users/octo/installation", func(w http.ResponseWriter, r *http.Request) {
+		claims := verifyAppJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), key)
+		if claims["iss"] != "1234" {
+			t.Errorf("expected the JWT to be issued by app 1234, got %v", claims["iss"])
+		}
+		fmt.Fprint(w, `{"id": 42}`)
+	})
+	mux.HandleFunc("/app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		verifyAppJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), key)
+		n := atomic.AddInt32(&exchanges, 1)
+		fmt.Fprintf(w, `{"token": "installation-token-%d", "expires_at": %q}`, n, expiresAt)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	source, err := NewAppTokenSource(AppConfig{AppID: 1234, PrivateKey: pemKey, Owner: "octo", APIEndpoint: server.URL})
+	if err != nil {
+		t.Fatalf("NewAppTokenSource failed: %v", err)
+	}
+	source.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil || token != "installation-token-1" {
+			t.Fatalf("Token() = %q, %v; want the first installation token", token, err)
+		}
+	}
+
+	// Shortly before expiry the token is replaced
+	now = now.Add(56 * time.Minute)
+	token, err := source.Token(context.Background())
+	if err != nil || token != "installation-token-2" {
+		t.Fatalf("Token() = %q, %v; want a refreshed installation token", token, err)
+	}
+	if exchanges != 2 {
+		t.Errorf("expected 2 token exchanges, got %d", exchanges)
+	}
+}
+
+func TestAppTokenSource_RejectedApp(t *testing.T) {
+	pemKey, _ := newTestAppKey(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message":"A JSON web token could not be decoded"}`)
+	}))
+	defer server.Close()
+
+	source, err := NewAppTokenSource(AppConfig{AppID: 1234, PrivateKey: pemKey, InstallationID: 42, APIEndpoint: server.URL})
+	if err != nil {
+		t.Fatalf("NewAppTokenSource failed: %v", err)
+	}
+
+	_, err = source.Token(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected a 401 error, got %v", err)
+	}
+	if !NewGraphQLClient("").inspector.IsAuthError(err) {
+		t.Errorf("expected %v to be classified as an auth error", err)
+	}
+}
+
+func TestNewAppTokenSource_InvalidConfig(t *testing.T) {
+	pemKey, _ := newTestAppKey(t)
+
+	tests := []struct {
+		name    string
+		cfg     AppConfig
+		wantErr string
+	}{
+		{
+			name:    "missing app ID",
+			cfg:     AppConfig{PrivateKey: pemKey, InstallationID: 1},
+			wantErr: "App ID must be positive",
+		},
+		{
+			name:    "no installation or owner",
+			cfg:     AppConfig{AppID: 1, PrivateKey: pemKey},
+			wantErr: "installation ID or owner is required",
+		},
+		{
+			name:    "key not PEM",
+			cfg:     AppConfig{AppID: 1, PrivateKey: []byte("not a key"), InstallationID: 1},
+			wantErr: "not PEM encoded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAppTokenSource(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewAppTokenSource() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGraphQLClient_UsesTokenSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer from-source" {
+			t.Errorf("expected the token source's token, got %q", auth)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"search":{"issueCount":1}}}`)
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("static", WithEndpoint(server.URL), WithTokenSource(StaticToken("from-source")))
+	if _, err := client.CountPullRequests(context.Background(), "org", "repo", FetchOptions{}); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+}
+
+func TestGraphQLClient_ResolvesTokenAgainAfterUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer rotated" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "Bad credentials"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"search":{"issueCount":2}}}`)
+	}))
+	defer server.Close()
+
+	tokens := []string{"revoked", "rotated"}
+	var resolved int32
+	source := NewResolvedToken(func(ctx context.Context) (string, error) {
+		n := atomic.AddInt32(&resolved, 1)
+		return tokens[n-1], nil
+	})
+
+	client := NewGraphQLClient("", WithEndpoint(server.URL), WithTokenSource(source))
+	count, err := client.CountPullRequests(context.Background(), "org", "repo", FetchOptions{})
+	if err != nil || count != 2 {
+		t.Fatalf("expected the query to succeed with the rotated token, got %d, %v", count, err)
+	}
+	if resolved != 2 {
+		t.Errorf("expected the token to be resolved twice, got %d", resolved)
+	}
+
+	// A token that stays invalid fails after one new attempt
+	source = NewResolvedToken(func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&resolved, 1)
+		return "revoked", nil
+	})
+	client = NewGraphQLClient("", WithEndpoint(server.URL), WithTokenSource(source))
+	if _, err := client.CountPullRequests(context.Background(), "org", "repo", FetchOptions{}); !errors.Is(err, relaierrors.ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken, got %v", err)
+	}
+	if resolved != 4 {
+		t.Errorf("expected one more resolution per attempt, got %d in total", resolved)
+	}
+}
@@ -31,7 +31,27 @@ type Client interface {
 	// It's the preferred method for fetching PRs with time windows or incremental updates.
 	FetchPullRequestsSearch(ctx context.Context, owner, repo string, opts FetchOptions) (*PullRequestPage, error)
 
+	// FetchPullRequest retrieves a single pull request by number. It returns
+	// an error wrapping ErrPullRequestNotFound when the repository has no
+	// such PR.
+	FetchPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error)
+
+	// FetchPullRequestsByNumber retrieves the pull requests with the given
+	// numbers in the order given, batching several into each query. Numbers
+	// without a PR are listed in the page's Missing instead of failing.
+	FetchPullRequestsByNumber(ctx context.Context, owner, repo string, numbers []int) (*PullRequestPage, error)
+
+	// CountPullRequests returns the number of pull requests matching the search
+	// query built from opts, without fetching them. Pagination fields are ignored.
+	// Used to size time windows below the search API's result cap.
+	CountPullRequests(ctx context.Context, owner, repo string, opts FetchOptions) (int, error)
+
 	// GetRepositoryInfo retrieves basic repository metadata including total PR count.
 	// Used for progress tracking and ETA calculation.
 	GetRepositoryInfo(ctx context.Context, owner, repo string) (*RepositoryInfo, error)
+
+	// GetOrganizationRepositories lists the repositories owned by an
+	// organization or user that match filter, sorted by name. Used to expand
+	// owner/* arguments into the repositories they stand for.
+	GetOrganizationRepositories(ctx context.Context, owner string, filter RepositoryFilter) ([]Repository, error)
 }
@@ -0,0 +1,218 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+
+	"github.com/shurcooL/graphql"
+)
+
+// nestedPageSize is the page size used when draining nested connections.
+// 100 is the maximum GitHub allows for a single connection.
+const nestedPageSize = 100
+
+// completeNestedConnections drains every nested connection on the pull request
+// whose totalCount exceeds the nodes returned with the initial query. The
+// extra nodes are appended in place so convertGraphQLPR sees complete data.
+// It returns the number of follow-up queries that were executed.
+func (c *GraphQLClient) completeNestedConnections(ctx context.Context, owner, repo string, node *pullRequestNode) (int, error) {
+	number := node.Number
+	queries := 0
+
+	steps := []func() (int, error){
+		func() (int, error) {
+			return drainConnection(ctx, &node.Files, func(after string) (*connection[fileNode], error) {
+				var query struct {
+					rateLimited
+					Repository struct {
+						PullRequest struct {
+							Files connection[fileNode] `graphql:"files(first: $first, after: $after)"`
+						} `graphql:"pullRequest(number: $number)"`
+					} `graphql:"repository(owner: $owner, name: $repo)"`
+				}
+				err := c.query(ctx, &query, nestedVariables(owner, repo, number, after))
+				return &query.Repository.PullRequest.Files, err
+			})
+		},
+		func() (int, error) {
+			return drainConnection(ctx, &node.Commits, func(after string) (*connection[commitNode], error) {
+				var query struct {
+					rateLimited
+					Repository struct {
+						PullRequest struct {
+							Commits connection[commitNode] `graphql:"commits(first: $first, after: $after)"`
+						} `graphql:"pullRequest(number: $number)"`
+					} `graphql:"repository(owner: $owner, name: $repo)"`
+				}
+				err := c.query(ctx, &query, nestedVariables(owner, repo, number, after))
+				return &query.Repository.PullRequest.Commits, err
+			})
+		},
+		func() (int, error) {
+			return drainConnection(ctx, &node.Reviews, func(after string) (*connection[reviewNode], error) {
+				var query struct {
+					rateLimited
+					Repository struct {
+						PullRequest struct {
+							Reviews connection[reviewNode] `graphql:"reviews(first: $first, after: $after)"`
+						} `graphql:"pullRequest(number: $number)"`
+					} `graphql:"repository(owner: $owner, name: $repo)"`
+				}
+				err := c.query(ctx, &query, nestedVariables(owner, repo, number, after))
+				return &query.Repository.PullRequest.Reviews, err
+			})
+		},
+		func() (int, error) {
+			return drainConnection(ctx, &node.Labels, func(after string) (*connection[labelNode], error) {
+				var query struct {
+					rateLimited
+					Repository struct {
+						PullRequest struct {
+							Labels connection[labelNode] `graphql:"labels(first: $first, after: $after)"`
+						} `graphql:"pullRequest(number: $number)"`
+					} `graphql:"repository(owner: $owner, name: $repo)"`
+				}
+				err := c.query(ctx, &query, nestedVariables(owner, repo, number, after))
+				return &query.Repository.PullRequest.Labels, err
+			})
+		},
+		func() (int, error) {
+			return drainConnection(ctx, &node.Assignees, func(after string) (*connection[actorNode], error) {
+				var query struct {
+					rateLimited
+					Repository struct {
+						PullRequest struct {
+							Assignees connection[actorNode] `graphql:"assignees(first: $first, after: $after)"`
+						} `graphql:"pullRequest(number: $number)"`
+					} `graphql:"repository(owner: $owner, name: $repo)"`
+				}
+				err := c.query(ctx, &query, nestedVariables(owner, repo, number, after))
+				return &query.Repository.PullRequest.Assignees, err
+			})
+		},
+		func() (int, error) {
+			return drainConnection(ctx, &node.TimelineItems, func(after string) (*connection[timelineItemNode], error) {
+				var query struct {
+					rateLimited
+					Repository struct {
+						PullRequest struct {
+							TimelineItems connection[timelineItemNode] `graphql:"timelineItems(first: $first, after: $after, itemTypes: $itemTypes)"`
+						} `graphql:"pullRequest(number: $number)"`
+					} `graphql:"repository(owner: $owner, name: $repo)"`
+				}
+				err := c.query(ctx, &query, withTimelineItemTypes(nestedVariables(owner, repo, number, after)))
+				return &query.Repository.PullRequest.TimelineItems, err
+			})
+		},
+		func() (int, error) {
+			return drainConnection(ctx, &node.ReviewThreads, func(after string) (*connection[reviewThreadNode], error) {
+				var query struct {
+					rateLimited
+					Repository struct {
+						PullRequest struct {
+							ReviewThreads connection[reviewThreadNode] `graphql:"reviewThreads(first: $first, after: $after)"`
+						} `graphql:"pullRequest(number: $number)"`
+					} `graphql:"repository(owner: $owner, name: $repo)"`
+				}
+				err := c.query(ctx, &query, nestedVariables(owner, repo, number, after))
+				return &query.Repository.PullRequest.ReviewThreads, err
+			})
+		},
+		// Runs after the review threads step so every thread is present
+		func() (int, error) {
+			total := 0
+			for i := range node.ReviewThreads.Nodes {
+				n, err := c.drainThreadComments(ctx, &node.ReviewThreads.Nodes[i])
+				total += n
+				if err != nil {
+					return total, err
+				}
+			}
+			return total, nil
+		},
+	}
+
+	for _, step := range steps {
+		n, err := step()
+		queries += n
+		if err != nil {
+			return queries, c.mapError(err, owner, repo)
+		}
+	}
+
+	return queries, nil
+}
+
+// drainThreadComments fetches the remaining comments of a review thread.
+// Threads are addressed by node ID because they have no number of their own.
+func (c *GraphQLClient) drainThreadComments(ctx context.Context, thread *reviewThreadNode) (int, error) {
+	return drainConnection(ctx, &thread.Comments, func(after string) (*connection[reviewCommentNode], error) {
+		var query struct {
+			rateLimited
+			Node struct {
+				PullRequestReviewThread struct {
+					Comments connection[reviewCommentNode] `graphql:"comments(first: $first, after: $after)"`
+				} `graphql:"... on PullRequestReviewThread"`
+			} `graphql:"node(id: $id)"`
+		}
+		variables := map[string]interface{}{
+			"id":    thread.ID,
+			"first": graphql.Int(nestedPageSize),
+			"after": graphql.String(after),
+		}
+		err := c.query(ctx, &query, variables)
+		return &query.Node.PullRequestReviewThread.Comments, err
+	})
+}
+
+// drainConnection fetches the remaining pages of conn by cursor, appending
+// their nodes to conn.Nodes until the server reports no further pages.
+// It returns the number of pages fetched.
+func drainConnection[T any](ctx context.Context, conn *connection[T], fetchPage func(after string) (*connection[T], error)) (int, error) {
+	pages := 0
+	for conn.needsMore() {
+		if err := ctx.Err(); err != nil {
+			return pages, err
+		}
+
+		page, err := fetchPage(string(conn.PageInfo.EndCursor))
+		pages++
+		if err != nil {
+			return pages, err
+		}
+
+		conn.Nodes = append(conn.Nodes, page.Nodes...)
+		conn.PageInfo = page.PageInfo
+		conn.TotalCount = page.TotalCount
+
+		// Guard against a server that reports more pages but returns nothing
+		if len(page.Nodes) == 0 {
+			break
+		}
+	}
+	return pages, nil
+}
+
+// nestedVariables builds the variables for a nested connection follow-up query.
+func nestedVariables(owner, repo string, number graphql.Int, after string) map[string]interface{} {
+	return map[string]interface{}{
+		"owner":  graphql.String(owner),
+		"repo":   graphql.String(repo),
+		"number": number,
+		"first":  graphql.Int(nestedPageSize),
+		"after":  graphql.String(after),
+	}
+}
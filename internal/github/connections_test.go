@@ -0,0 +1,160 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// graphQLRequest is the body the GraphQL client posts to the server.
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+func TestFetchPullRequestsSearch_PaginatesNestedConnections(t *testing.T) {
+	var followUps []graphQLRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.Contains(req.Query, "search("):
+			fmt.Fprint(w, `{"data":{"search":{
+				"pageInfo":{"hasNextPage":false,"endCursor":""},
+				"nodes":[{
+					"number":7,"title":"Large refactor",
+					"files":{"totalCount":3,"pageInfo":{"hasNextPage":true,"endCursor":"files-1"},
+						"nodes":[{"path":"a.go","additions":1,"deletions":0,"changeType":"ADDED"}]},
+					"commits":{"totalCount":2,"pageInfo":{"hasNextPage":true,"endCursor":"commits-1"},
+						"nodes":[{"commit":{"oid":"c1"}}]},
+					"reviews":{"totalCount":1,"pageInfo":{"hasNextPage":false,"endCursor":"r"},
+						"nodes":[{"id":"r1","state":"APPROVED"}]},
+					"labels":{"totalCount":0,"pageInfo":{"hasNextPage":false},"nodes":[]},
+					"assignees":{"totalCount":0,"pageInfo":{"hasNextPage":false},"nodes":[]}
+				}]}}}`)
+		case strings.Contains(req.Query, "files(first: $first, after: $after)"):
+			followUps = append(followUps, req)
+			if req.Variables["after"] == "files-1" {
+				fmt.Fprint(w, `{"data":{"repository":{"pullRequest":{"files":{
+					"totalCount":3,"pageInfo":{"hasNextPage":true,"endCursor":"files-2"},
+					"nodes":[{"path":"b.go","changeType":"MODIFIED"}]}}}}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"repository":{"pullRequest":{"files":{
+				"totalCount":3,"pageInfo":{"hasNextPage":false,"endCursor":"files-3"},
+				"nodes":[{"path":"c.go","changeType":"DELETED"}]}}}}}`)
+		case strings.Contains(req.Query, "commits(first: $first, after: $after)"):
+			followUps = append(followUps, req)
+			fmt.Fprint(w, `{"data":{"repository":{"pullRequest":{"commits":{
+				"totalCount":2,"pageInfo":{"hasNextPage":false,"endCursor":"commits-2"},
+				"nodes":[{"commit":{"oid":"c2"}}]}}}}}`)
+		default:
+			t.Errorf("unexpected query: %s", req.Query)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	page, err := client.FetchPullRequestsSearch(context.Background(), "org", "repo", FetchOptions{})
+	if err != nil {
+		t.Fatalf("FetchPullRequestsSearch failed: %v", err)
+	}
+
+	if len(page.PullRequests) != 1 {
+		t.Fatalf("expected 1 PR, got %d", len(page.PullRequests))
+	}
+	pr := page.PullRequests[0]
+
+	var files []string
+	for _, f := range pr.Files {
+		files = append(files, f.Filename+":"+f.Status)
+	}
+	if got := strings.Join(files, ","); got != "a.go:added,b.go:modified,c.go:removed" {
+		t.Errorf("unexpected files: %s", got)
+	}
+
+	if len(pr.CommitList) != 2 || pr.CommitList[1].SHA != "c2" {
+		t.Errorf("expected commits c1,c2, got %+v", pr.CommitList)
+	}
+	if len(pr.Reviews) != 1 {
+		t.Errorf("expected 1 review, got %d", len(pr.Reviews))
+	}
+
+	// One search query, two file pages and one commit page
+	if page.APICalls != 4 {
+		t.Errorf("expected 4 API calls, got %d", page.APICalls)
+	}
+
+	for _, req := range followUps {
+		if req.Variables["number"] != float64(7) || req.Variables["owner"] != "org" || req.Variables["repo"] != "repo" {
+			t.Errorf("unexpected follow-up variables: %v", req.Variables)
+		}
+		if req.Variables["first"] != float64(nestedPageSize) {
+			t.Errorf("expected follow-up page size %d, got %v", nestedPageSize, req.Variables["first"])
+		}
+	}
+}
+
+func TestDrainConnection(t *testing.T) {
+	t.Run("complete connection makes no requests", func(t *testing.T) {
+		conn := &connection[fileNode]{TotalCount: 1, Nodes: []fileNode{{Path: "a"}}}
+		pages, err := drainConnection(context.Background(), conn, func(string) (*connection[fileNode], error) {
+			t.Fatal("unexpected fetch")
+			return nil, nil
+		})
+		if err != nil || pages != 0 {
+			t.Errorf("expected no pages and no error, got %d, %v", pages, err)
+		}
+	})
+
+	t.Run("stops on empty page", func(t *testing.T) {
+		conn := &connection[fileNode]{TotalCount: 5, Nodes: []fileNode{{Path: "a"}}}
+		conn.PageInfo.HasNextPage = true
+		pages, err := drainConnection(context.Background(), conn, func(string) (*connection[fileNode], error) {
+			next := &connection[fileNode]{TotalCount: 5}
+			next.PageInfo.HasNextPage = true
+			return next, nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pages != 1 {
+			t.Errorf("expected 1 page, got %d", pages)
+		}
+	})
+
+	t.Run("returns fetch errors", func(t *testing.T) {
+		conn := &connection[fileNode]{TotalCount: 5}
+		conn.PageInfo.HasNextPage = true
+		_, err := drainConnection(context.Background(), conn, func(string) (*connection[fileNode], error) {
+			return nil, fmt.Errorf("boom")
+		})
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -35,4 +35,10 @@
 //	for _, pr := range page.PullRequests {
 //	    // Process pull request
 //	}
+//
+// GitHub Enterprise Server installations are supported by pointing the client
+// at the instance's GraphQL endpoint:
+//
+//	client := github.NewGraphQLClient("your-github-token",
+//	    github.WithEndpoint("https://github.example.com/api/graphql"))
 package github
@@ -34,7 +34,11 @@ import (
 type GraphQLClient struct {
 	client    *graphql.Client
 	token     string
+	endpoint  string
 	inspector giterror.Inspector
+	pool      *credentialPool
+	retry     RetryPolicy
+	retries   int64
 }
 
 // NewGraphQLClient creates a new GitHub GraphQL client with the provided token.
@@ -44,39 +48,71 @@ type GraphQLClient struct {
 //   - Response size limiting to prevent memory issues
 //   - User-Agent header for API compliance
 //   - Optimized connection pooling for API performance
-func NewGraphQLClient(token string) *GraphQLClient {
-	// Create optimized transport with connection pooling
-	transport := &http.Transport{
-		MaxIdleConns:        10,
-		MaxIdleConnsPerHost: 10, // Increased from default 2
-		MaxConnsPerHost:     10,
-		IdleConnTimeout:     90 * time.Second,
-		DisableCompression:  false,
-		ForceAttemptHTTP2:   true, // Ensure HTTP/2 is used
+//
+// By default the client talks to GitHub.com. Pass WithEndpoint to target a
+// GitHub Enterprise Server installation, and WithTokenSource to authenticate
+// with tokens that change over time instead of token.
+func NewGraphQLClient(token string, opts ...ClientOption) *GraphQLClient {
+	options := defaultClientOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	transport := options.transport
+	if transport == nil {
+		// Create optimized transport with connection pooling
+		transport = &http.Transport{
+			MaxIdleConns:        10,
+			MaxIdleConnsPerHost: 10, // Increased from default 2
+			MaxConnsPerHost:     10,
+			IdleConnTimeout:     90 * time.Second,
+			DisableCompression:  false,
+			ForceAttemptHTTP2:   true, // Ensure HTTP/2 is used
+		}
+	}
+
+	tokens := options.pool
+	if len(tokens) == 0 {
+		source := options.tokens
+		if source == nil {
+			source = StaticToken(token)
+		}
+		tokens = []PooledToken{{Source: source}}
 	}
+	pool := newCredentialPool(tokens, options.waiter)
 
 	httpClient := &http.Client{
 		Transport: &authTransport{
 			token: token,
 			base:  transport,
 		},
+		Timeout: options.timeout,
 	}
 
-	client := graphql.NewClient("https://api.github.com/graphql", httpClient)
+	client := graphql.NewClient(options.endpoint, httpClient)
 
 	return &GraphQLClient{
 		client:    client,
 		token:     token,
+		endpoint:  options.endpoint,
 		inspector: giterror.NewInspector(),
+		pool:      pool,
+		retry:     options.retry,
 	}
 }
 
+// Endpoint returns the GraphQL endpoint URL the client sends queries to.
+func (c *GraphQLClient) Endpoint() string {
+	return c.endpoint
+}
+
 // GetRepositoryInfo retrieves basic repository metadata including total PR count.
 // This is used to display progress information when fetching all pull requests.
 // It executes a minimal GraphQL query to get just the total count of PRs.
 func (c *GraphQLClient) GetRepositoryInfo(ctx context.Context, owner, repo string) (*RepositoryInfo, error) {
 	// Define minimal query for repository info
 	var query struct {
+		rateLimited
 		Repository struct {
 			PullRequests struct {
 				TotalCount graphql.Int
@@ -91,7 +127,7 @@ func (c *GraphQLClient) GetRepositoryInfo(ctx context.Context, owner, repo strin
 	}
 
 	// Execute the query
-	err := c.client.Query(ctx, &query, variables)
+	err := c.query(ctx, &query, variables)
 	if err != nil {
 		return nil, c.mapError(err, owner, repo)
 	}
@@ -119,154 +155,24 @@ func (c *GraphQLClient) FetchPullRequests(ctx context.Context, owner, repo strin
 
 	// Define the comprehensive GraphQL query structure
 	var query struct {
+		rateLimited
 		Repository struct {
 			PullRequests struct {
 				PageInfo struct {
 					HasNextPage graphql.Boolean
 					EndCursor   graphql.String
 				}
-				Nodes []struct {
-					Number             graphql.Int
-					Title              graphql.String
-					State              graphql.String
-					Body               graphql.String
-					URL                graphql.String
-					CreatedAt          time.Time
-					UpdatedAt          time.Time
-					ClosedAt           *time.Time
-					MergedAt           *time.Time
-					Merged             graphql.Boolean
-					Mergeable          graphql.String
-					Additions          graphql.Int
-					Deletions          graphql.Int
-					ChangedFiles       graphql.Int
-					TotalCommentsCount graphql.Int
-
-					// Author information
-					Author struct {
-						Login graphql.String `graphql:"login"`
-					} `graphql:"author"`
-
-					// MergedBy information
-					MergedBy *struct {
-						Login graphql.String `graphql:"login"`
-					} `graphql:"mergedBy"`
-
-					// Base and head references
-					BaseRef *struct {
-						Name   graphql.String
-						Target struct {
-							OID graphql.String `graphql:"oid"`
-						}
-					} `graphql:"baseRef"`
-
-					HeadRef *struct {
-						Name   graphql.String
-						Target struct {
-							OID graphql.String `graphql:"oid"`
-						}
-					} `graphql:"headRef"`
-
-					// Merge commit SHA
-					MergeCommit *struct {
-						OID graphql.String `graphql:"oid"`
-					} `graphql:"mergeCommit"`
-
-					// Labels
-					Labels struct {
-						Nodes []struct {
-							Name        graphql.String
-							Color       graphql.String
-							Description graphql.String
-						}
-					} `graphql:"labels(first: 100)"`
-
-					// Assignees
-					Assignees struct {
-						Nodes []struct {
-							Login graphql.String
-						}
-					} `graphql:"assignees(first: 100)"`
-
-					// Requested reviewers
-					ReviewRequests struct {
-						Nodes []struct {
-							RequestedReviewer struct {
-								User struct {
-									Login graphql.String
-								} `graphql:"... on User"`
-							} `graphql:"requestedReviewer"`
-						}
-					} `graphql:"reviewRequests(first: 100)"`
-
-					// Files changed
-					Files struct {
-						TotalCount graphql.Int
-						Nodes      []struct {
-							Path       graphql.String
-							Additions  graphql.Int
-							Deletions  graphql.Int
-							ChangeType graphql.String
-						}
-					} `graphql:"files(first: 100)"`
-
-					// Reviews
-					Reviews struct {
-						Nodes []struct {
-							ID          graphql.String
-							State       graphql.String
-							Body        graphql.String
-							SubmittedAt *time.Time
-							Author      struct {
-								Login graphql.String
-							} `graphql:"author"`
-						}
-					} `graphql:"reviews(first: 50)"`
-
-					// Commits
-					Commits struct {
-						TotalCount graphql.Int
-						Nodes      []struct {
-							Commit struct {
-								OID           graphql.String `graphql:"oid"`
-								Message       graphql.String
-								AuthoredDate  time.Time
-								CommittedDate time.Time
-								Additions     graphql.Int
-								Deletions     graphql.Int
-								Author        struct {
-									User *struct {
-										Login graphql.String
-									} `graphql:"user"`
-									Name  graphql.String
-									Email graphql.String
-								} `graphql:"author"`
-								Committer struct {
-									User *struct {
-										Login graphql.String
-									} `graphql:"user"`
-									Name  graphql.String
-									Email graphql.String
-								} `graphql:"committer"`
-								Parents struct {
-									Nodes []struct {
-										OID graphql.String `graphql:"oid"`
-									}
-								} `graphql:"parents(first: 2)"`
-							} `graphql:"commit"`
-						}
-					} `graphql:"commits(first: 100)"`
-				}
+				Nodes []pullRequestNode
 			} `graphql:"pullRequests(first: $first, after: $after, orderBy: {field: UPDATED_AT, direction: DESC})"`
 		} `graphql:"repository(owner: $owner, name: $repo)"`
 	}
 
 	// Set up variables
-	variables := map[string]interface{}{
+	variables := withTimelineItemTypes(map[string]interface{}{
 		"owner": graphql.String(owner),
 		"repo":  graphql.String(repo),
 		"first": graphql.Int(int32(pageSize)), // #nosec G115 - pageSize is capped at 100
-	}
+	})
 
 	// Add after cursor if provided
 	if opts.After != "" {
@@ -274,7 +180,7 @@ func (c *GraphQLClient) FetchPullRequests(ctx context.Context, owner, repo strin
 	}
 
 	// Execute the query
-	err := c.client.Query(ctx, &query, variables)
+	err := c.query(ctx, &query, variables)
 	if err != nil {
 		return nil, c.mapError(err, owner, repo)
 	}
@@ -284,13 +190,27 @@ func (c *GraphQLClient) FetchPullRequests(ctx context.Context, owner, repo strin
 		HasNextPage:  bool(query.Repository.PullRequests.PageInfo.HasNextPage),
 		EndCursor:    string(query.Repository.PullRequests.PageInfo.EndCursor),
 		PullRequests: make([]PullRequest, 0, len(query.Repository.PullRequests.Nodes)),
+		APICalls:     1,
 	}
 
-	for _, node := range query.Repository.PullRequests.Nodes {
-		pr := c.convertGraphQLPR(&node)
+	for i := range query.Repository.PullRequests.Nodes {
+		node := &query.Repository.PullRequests.Nodes[i]
+
+		// Fetch the rest of any nested connection truncated by the page size
+		followUps, err := c.completeNestedConnections(ctx, owner, repo, node)
+		page.APICalls += followUps
+		if err != nil {
+			return nil, err
+		}
+
+		pr := c.convertGraphQLPR(node)
 		page.PullRequests = append(page.PullRequests, pr)
 	}
 
+	stats := c.RateLimitStats()
+	page.RateLimit = &stats
+	page.Retries = c.Retries()
+
 	return page, nil
 }
 
@@ -300,7 +220,12 @@ func (c *GraphQLClient) mapError(err error, owner, repo string) error {
 		return nil
 	}
 
-	// Use the inspector to classify errors
+	// Use the inspector to classify errors. Rate limits are checked first
+	// because GitHub reports some of them with a 403 status.
+	if c.inspector.IsRateLimitError(err) {
+		return fmt.Errorf("GitHub API rate limit exceeded. Please wait before retrying: %w", relaierrors.ErrRateLimit)
+	}
+
 	if c.inspector.IsAuthError(err) {
 		return fmt.Errorf("GitHub API authentication failed. Please provide a valid token via --token flag or GITHUB_TOKEN environment variable: %w", relaierrors.ErrInvalidToken)
 	}
@@ -309,10 +234,6 @@ func (c *GraphQLClient) mapError(err error, owner, repo string) error {
 		return fmt.Errorf("repository '%s/%s' not found. Please check the repository name and your access permissions: %w", owner, repo, relaierrors.ErrRepoNotFound)
 	}
 
-	if c.inspector.IsRateLimitError(err) {
-		return fmt.Errorf("GitHub API rate limit exceeded. Please wait before retrying: %w", relaierrors.ErrRateLimit)
-	}
-
 	if c.inspector.IsComplexityError(err) {
 		return fmt.Errorf("GraphQL query complexity exceeded. Reducing batch size may help: %w", relaierrors.ErrQueryComplexity)
 	}
@@ -325,6 +246,12 @@ func (c *GraphQLClient) mapError(err error, owner, repo string) error {
 	return fmt.Errorf("failed to fetch pull requests: %w", err)
 }
 
+// isBotLogin reports whether a login belongs to a bot, such as
+// dependabot[bot] or a renovate-bot style account.
+func isBotLogin(login string) bool {
+	return strings.Contains(login, "[bot]") || strings.HasSuffix(login, "-bot")
+}
+
 // limitedReader wraps a ReadCloser with a size limit to prevent excessive memory usage.
 type limitedReader struct {
 	io.ReadCloser
@@ -350,7 +277,10 @@ func (lr *limitedReader) Read(p []byte) (n int, err error) {
 	return n, err
 }
 
-// authTransport adds authentication header and safety limits to HTTP requests
+// authTransport adds authentication header and safety limits to HTTP requests.
+// Requests sent by the client carry the credential chosen for them in their
+// context; it supplies the token and records the rate limit reported in the
+// response headers. Other requests are sent with token.
 type authTransport struct {
 	token string
 	base  http.RoundTripper
@@ -362,7 +292,15 @@ func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req = req.Clone(req.Context())
 
 	// Add auth header
-	req.Header.Set("Authorization", "Bearer "+t.token)
+	token := t.token
+	cred := credentialFrom(req.Context())
+	if cred != nil {
+		var err error
+		if token, err = cred.tokens.Token(req.Context()); err != nil {
+			return nil, fmt.Errorf("failed to get GitHub token: %w", err)
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	// Add user agent for identification
 	req.Header.Set("User-Agent", fmt.Sprintf("sirseer-relay/%s", version.Version))
@@ -373,6 +311,11 @@ func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 
+	// Track the remaining budget and any requested backoff
+	if cred != nil {
+		cred.limits.observeHeaders(resp.Header, time.Now())
+	}
+
 	// Apply response size limit (10MB)
 	if resp.Body != nil {
 		resp.Body = &limitedReader{
@@ -385,150 +328,24 @@ func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 }
 
 // convertGraphQLPR converts a GraphQL pull request node to our domain model
-func (c *GraphQLClient) convertGraphQLPR(node interface{}) PullRequest {
-	// Use reflection to access the node fields
-	// This is necessary because the node is an anonymous struct from the query
-	n := node.(*struct {
-		Number             graphql.Int
-		Title              graphql.String
-		State              graphql.String
-		Body               graphql.String
-		URL                graphql.String
-		CreatedAt          time.Time
-		UpdatedAt          time.Time
-		ClosedAt           *time.Time
-		MergedAt           *time.Time
-		Merged             graphql.Boolean
-		Mergeable          graphql.String
-		Additions          graphql.Int
-		Deletions          graphql.Int
-		ChangedFiles       graphql.Int
-		TotalCommentsCount graphql.Int
-
-		Author struct {
-			Login graphql.String `graphql:"login"`
-		} `graphql:"author"`
-
-		MergedBy *struct {
-			Login graphql.String `graphql:"login"`
-		} `graphql:"mergedBy"`
-
-		BaseRef *struct {
-			Name   graphql.String
-			Target struct {
-				OID graphql.String `graphql:"oid"`
-			}
-		} `graphql:"baseRef"`
-
-		HeadRef *struct {
-			Name   graphql.String
-			Target struct {
-				OID graphql.String `graphql:"oid"`
-			}
-		} `graphql:"headRef"`
-
-		MergeCommit *struct {
-			OID graphql.String `graphql:"oid"`
-		} `graphql:"mergeCommit"`
-
-		Labels struct {
-			Nodes []struct {
-				Name        graphql.String
-				Color       graphql.String
-				Description graphql.String
-			}
-		} `graphql:"labels(first: 100)"`
-
-		Assignees struct {
-			Nodes []struct {
-				Login graphql.String
-			}
-		} `graphql:"assignees(first: 100)"`
-
-		ReviewRequests struct {
-			Nodes []struct {
-				RequestedReviewer struct {
-					User struct {
-						Login graphql.String
-					} `graphql:"... on User"`
-				} `graphql:"requestedReviewer"`
-			}
-		} `graphql:"reviewRequests(first: 100)"`
-
-		Files struct {
-			TotalCount graphql.Int
-			Nodes      []struct {
-				Path       graphql.String
-				Additions  graphql.Int
-				Deletions  graphql.Int
-				ChangeType graphql.String
-			}
-		} `graphql:"files(first: 100)"`
-
-		Reviews struct {
-			Nodes []struct {
-				ID          graphql.String
-				State       graphql.String
-				Body        graphql.String
-				SubmittedAt *time.Time
-				Author      struct {
-					Login graphql.String
-				} `graphql:"author"`
-			}
-		} `graphql:"reviews(first: 50)"`
-
-		Commits struct {
-			TotalCount graphql.Int
-			Nodes      []struct {
-				Commit struct {
-					OID           graphql.String `graphql:"oid"`
-					Message       graphql.String
-					AuthoredDate  time.Time
-					CommittedDate time.Time
-					Additions     graphql.Int
-					Deletions     graphql.Int
-					Author        struct {
-						User *struct {
-							Login graphql.String
-						} `graphql:"user"`
-						Name  graphql.String
-						Email graphql.String
-					} `graphql:"author"`
-					Committer struct {
-						User *struct {
-							Login graphql.String
-						} `graphql:"user"`
-						Name  graphql.String
-						Email graphql.String
-					} `graphql:"committer"`
-					Parents struct {
-						Nodes []struct {
-							OID graphql.String `graphql:"oid"`
-						}
-					} `graphql:"parents(first: 2)"`
-				} `graphql:"commit"`
-			}
-		} `graphql:"commits(first: 100)"`
-	})
-
+func (c *GraphQLClient) convertGraphQLPR(n *pullRequestNode) PullRequest {
 	// Build the PR object
 	pr := PullRequest{
-		Number:         int(n.Number),
-		Title:          string(n.Title),
-		State:          string(n.State),
-		Body:           string(n.Body),
-		URL:            string(n.URL),
-		CreatedAt:      n.CreatedAt,
-		UpdatedAt:      n.UpdatedAt,
-		ClosedAt:       n.ClosedAt,
-		MergedAt:       n.MergedAt,
-		Merged:         bool(n.Merged),
-		Additions:      int(n.Additions),
-		Deletions:      int(n.Deletions),
-		ChangedFiles:   int(n.ChangedFiles),
-		Comments:       int(n.TotalCommentsCount),
-		ReviewComments: 0, // GitHub doesn't provide this in GraphQL
-		Commits:        int(n.Commits.TotalCount),
+		Number:       int(n.Number),
+		Title:        string(n.Title),
+		State:        string(n.State),
+		Body:         string(n.Body),
+		URL:          string(n.URL),
+		CreatedAt:    n.CreatedAt,
+		UpdatedAt:    n.UpdatedAt,
+		ClosedAt:     n.ClosedAt,
+		MergedAt:     n.MergedAt,
+		Merged:       bool(n.Merged),
+		Additions:    int(n.Additions),
+		Deletions:    int(n.Deletions),
+		ChangedFiles: int(n.ChangedFiles),
+		Comments:     int(n.TotalCommentsCount),
+		Commits:      int(n.Commits.TotalCount),
 	}
 
 	// Set author
@@ -538,7 +355,7 @@ func (c *GraphQLClient) convertGraphQLPR(node interface{}) PullRequest {
 	}
 
 	// Check if author is a bot
-	if strings.Contains(pr.Author.Login, "[bot]") || strings.HasSuffix(pr.Author.Login, "-bot") {
+	if isBotLogin(pr.Author.Login) {
 		pr.Author.Type = "Bot"
 		pr.IsBot = true
 	}
@@ -687,9 +504,21 @@ func (c *GraphQLClient) convertGraphQLPR(node interface{}) PullRequest {
 		pr.CommitList = append(pr.CommitList, c)
 	}
 
-	// Note: Conversations would require a separate timeline query
-	// For now, we'll leave it empty
-	pr.Conversations = []Conversation{}
+	// Convert review threads; ReviewComments counts every inline comment
+	pr.ReviewThreads = make([]ReviewThread, 0, len(n.ReviewThreads.Nodes))
+	for i := range n.ReviewThreads.Nodes {
+		thread := convertReviewThread(&n.ReviewThreads.Nodes[i])
+		pr.ReviewComments += len(thread.Comments)
+		pr.ReviewThreads = append(pr.ReviewThreads, thread)
+	}
+
+	// Convert timeline events
+	pr.Conversations = make([]Conversation, 0, len(n.TimelineItems.Nodes))
+	for i := range n.TimelineItems.Nodes {
+		if conv, ok := convertTimelineItem(&n.TimelineItems.Nodes[i]); ok {
+			pr.Conversations = append(pr.Conversations, conv)
+		}
+	}
 
 	return pr
 }
@@ -15,6 +15,7 @@
 package github
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -222,6 +223,53 @@ func TestAuthTransport(t *testing.T) {
 	}
 }
 
+func TestNewGraphQLClient_Endpoint(t *testing.T) {
+	t.Run("defaults to github.com", func(t *testing.T) {
+		client := NewGraphQLClient("test-token")
+		if client.Endpoint() != DefaultGraphQLEndpoint {
+			t.Errorf("expected endpoint %q, got %q", DefaultGraphQLEndpoint, client.Endpoint())
+		}
+	})
+
+	t.Run("empty endpoint keeps default", func(t *testing.T) {
+		client := NewGraphQLClient("test-token", WithEndpoint(""))
+		if client.Endpoint() != DefaultGraphQLEndpoint {
+			t.Errorf("expected endpoint %q, got %q", DefaultGraphQLEndpoint, client.Endpoint())
+		}
+	})
+
+	t.Run("queries custom endpoint", func(t *testing.T) {
+		var gotPath, gotAuth string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPath = r.URL.Path
+			gotAuth = r.Header.Get("Authorization")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"data":{"repository":{"pullRequests":{"totalCount":42}}}}`)
+		}))
+		defer server.Close()
+
+		endpoint := server.URL + "/api/graphql"
+		client := NewGraphQLClient("ghes-token", WithEndpoint(endpoint))
+		if client.Endpoint() != endpoint {
+			t.Errorf("expected endpoint %q, got %q", endpoint, client.Endpoint())
+		}
+
+		info, err := client.GetRepositoryInfo(context.Background(), "org", "repo")
+		if err != nil {
+			t.Fatalf("GetRepositoryInfo failed: %v", err)
+		}
+		if info.TotalPullRequests != 42 {
+			t.Errorf("expected 42 pull requests, got %d", info.TotalPullRequests)
+		}
+		if gotPath != "/api/graphql" {
+			t.Errorf("expected request to /api/graphql, got %q", gotPath)
+		}
+		if gotAuth != "Bearer ghes-token" {
+			t.Errorf("expected Authorization 'Bearer ghes-token', got %q", gotAuth)
+		}
+	})
+}
+
 func TestLimitedReader(t *testing.T) {
 	t.Run("within limit", func(t *testing.T) {
 		data := "hello world"
@@ -49,6 +49,16 @@ type MockClient struct {
 	// Query complexity simulation
 	ComplexityErrorOnCall int // Return complexity error on this call number (0 = never)
 	CallsSinceComplexity  int // Track calls since last complexity error
+
+	// Interrupted run simulation
+	NetworkErrorOnCall int // Return network error on this call number (0 = never)
+
+	// Search result cap simulation
+	ResultCap  int // Maximum results returned per query across all pages (0 = unlimited)
+	CountCalls int // Number of CountPullRequests calls
+
+	// Repositories returned by GetOrganizationRepositories, keyed by owner
+	Repositories map[string][]Repository
 }
 
 // NewMockClient creates a new mock client with default test data
@@ -94,6 +104,29 @@ func (m *MockClient) GetRepositoryInfo(ctx context.Context, owner, repo string)
 	}, nil
 }
 
+// GetOrganizationRepositories implements the Client interface
+func (m *MockClient) GetOrganizationRepositories(ctx context.Context, owner string, filter RepositoryFilter) ([]Repository, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if m.ShouldFailAuth {
+		return nil, fmt.Errorf("authentication failed: %w", relaierrors.ErrInvalidToken)
+	}
+
+	repos, ok := m.Repositories[owner]
+	if !ok {
+		return nil, fmt.Errorf("organization or user '%s' not found: %w", owner, relaierrors.ErrRepoNotFound)
+	}
+
+	var matched []Repository
+	for _, r := range repos {
+		if filter.Matches(r) {
+			matched = append(matched, r)
+		}
+	}
+	return matched, nil
+}
+
 // FetchPullRequests implements the Client interface
 func (m *MockClient) FetchPullRequests(ctx context.Context, owner, repo string, opts FetchOptions) (*PullRequestPage, error) {
 	// Track the call
@@ -116,24 +149,116 @@ func (m *MockClient) FetchPullRequests(ctx context.Context, owner, repo string,
 	}
 	m.CallsSinceComplexity++
 
+	if m.NetworkErrorOnCall > 0 && m.CallCount == m.NetworkErrorOnCall {
+		return nil, fmt.Errorf("connection reset: %w", relaierrors.ErrNetworkFailure)
+	}
+
 	// Check for other errors
 	if err := m.checkErrors(owner, repo); err != nil {
 		return nil, err
 	}
 
+	prs := m.matchingPullRequests(opts)
+	totalCount := len(prs)
+	if m.ResultCap > 0 && len(prs) > m.ResultCap {
+		prs = prs[:m.ResultCap]
+	}
+
 	// Handle pagination if enabled
 	if m.SimulatePages && m.PageSize > 0 {
-		return m.getPaginatedPage(opts)
+		page, err := m.getPaginatedPage(prs, opts)
+		if page != nil {
+			page.TotalCount = totalCount
+		}
+		return page, err
 	}
 
 	// Default behavior: return all PRs in one page
 	return &PullRequestPage{
-		PullRequests: m.PullRequests,
+		PullRequests: prs,
 		HasNextPage:  false,
 		EndCursor:    "",
+		TotalCount:   totalCount,
 	}, nil
 }
 
+// FetchPullRequest implements the Client interface
+func (m *MockClient) FetchPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	page, err := m.FetchPullRequestsByNumber(ctx, owner, repo, []int{number})
+	if err != nil {
+		return nil, err
+	}
+	if len(page.PullRequests) == 0 {
+		return nil, fmt.Errorf("pull request #%d not found in %s/%s: %w", number, owner, repo, relaierrors.ErrPullRequestNotFound)
+	}
+	return &page.PullRequests[0], nil
+}
+
+// FetchPullRequestsByNumber implements the Client interface
+func (m *MockClient) FetchPullRequestsByNumber(ctx context.Context, owner, repo string, numbers []int) (*PullRequestPage, error) {
+	m.CallCount++
+	m.LastOwner = owner
+	m.LastRepo = repo
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := m.checkErrors(owner, repo); err != nil {
+		return nil, err
+	}
+
+	byNumber := make(map[int]PullRequest, len(m.PullRequests))
+	for _, pr := range m.PullRequests {
+		byNumber[pr.Number] = pr
+	}
+
+	page := &PullRequestPage{APICalls: 1}
+	for _, number := range numbers {
+		if pr, ok := byNumber[number]; ok {
+			page.PullRequests = append(page.PullRequests, pr)
+		} else {
+			page.Missing = append(page.Missing, number)
+		}
+	}
+	page.TotalCount = len(page.PullRequests)
+	return page, nil
+}
+
+// CountPullRequests implements the Client interface
+func (m *MockClient) CountPullRequests(ctx context.Context, owner, repo string, opts FetchOptions) (int, error) {
+	m.CountCalls++
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	if err := m.checkErrors(owner, repo); err != nil {
+		return 0, err
+	}
+
+	return len(m.matchingPullRequests(opts)), nil
+}
+
+// matchingPullRequests returns the configured PRs that lie within the
+// window selected by opts, or all of them when no window is set. Bot PRs
+// are left out when opts.ExcludeBots is set.
+func (m *MockClient) matchingPullRequests(opts FetchOptions) []PullRequest {
+	var prs []PullRequest
+	for i := range m.PullRequests {
+		pr := &m.PullRequests[i]
+		if opts.ExcludeBots && pr.IsBot {
+			continue
+		}
+		if !opts.inWindow(pr) {
+			continue
+		}
+		prs = append(prs, *pr)
+	}
+	return prs
+}
+
 func (m *MockClient) checkErrors(owner, repo string) error {
 	// Simulate various error conditions
 	if m.ShouldFailAuth {
@@ -160,7 +285,7 @@ func (m *MockClient) FetchPullRequestsSearch(ctx context.Context, owner, repo st
 	return m.FetchPullRequests(ctx, owner, repo, opts)
 }
 
-func (m *MockClient) getPaginatedPage(opts FetchOptions) (*PullRequestPage, error) {
+func (m *MockClient) getPaginatedPage(all []PullRequest, opts FetchOptions) (*PullRequestPage, error) {
 	// Calculate pagination based on cursor
 	startIdx := 0
 	if opts.After != "" {
@@ -177,16 +302,16 @@ func (m *MockClient) getPaginatedPage(opts FetchOptions) (*PullRequestPage, erro
 	}
 
 	endIdx := startIdx + pageSize
-	if endIdx > len(m.PullRequests) {
-		endIdx = len(m.PullRequests)
+	if endIdx > len(all) {
+		endIdx = len(all)
 	}
 
 	prs := []PullRequest{}
-	if startIdx < len(m.PullRequests) {
-		prs = m.PullRequests[startIdx:endIdx]
+	if startIdx < len(all) {
+		prs = all[startIdx:endIdx]
 	}
 
-	hasNext := endIdx < len(m.PullRequests)
+	hasNext := endIdx < len(all)
 	cursor := ""
 	if hasNext {
 		cursor = fmt.Sprintf("cursor_%d", endIdx)
@@ -318,6 +443,32 @@ func WithComplexityError(callNumber int) MockClientOption {
 	}
 }
 
+// WithNetworkErrorOnCall makes the client return a network error on a specific call,
+// simulating a run that fails part way through
+func WithNetworkErrorOnCall(callNumber int) MockClientOption {
+	return func(m *MockClient) {
+		m.NetworkErrorOnCall = callNumber
+	}
+}
+
+// WithResultCap limits the results of each search query to n PRs across all
+// pages, simulating the search API's result cap
+func WithResultCap(n int) MockClientOption {
+	return func(m *MockClient) {
+		m.ResultCap = n
+	}
+}
+
+// WithRepositories sets the repositories listed for an organization or user
+func WithRepositories(owner string, repos ...Repository) MockClientOption {
+	return func(m *MockClient) {
+		if m.Repositories == nil {
+			m.Repositories = make(map[string][]Repository)
+		}
+		m.Repositories[owner] = repos
+	}
+}
+
 // NewMockClientWithOptions creates a mock client with options
 func NewMockClientWithOptions(opts ...MockClientOption) *MockClient {
 	mock := NewMockClient()
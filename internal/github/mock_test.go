@@ -337,3 +337,22 @@ func TestMockClient_GetRepositoryInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestMockClient_FetchPullRequestsByNumber(t *testing.T) {
+	mock := NewMockClientWithOptions(WithPullRequests([]PullRequest{{Number: 1}, {Number: 2}, {Number: 3}}))
+
+	page, err := mock.FetchPullRequestsByNumber(context.Background(), "test", "repo", []int{3, 5, 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.PullRequests) != 2 || page.PullRequests[0].Number != 3 || page.PullRequests[1].Number != 1 {
+		t.Errorf("PullRequests = %v, want #3 and #1 in request order", page.PullRequests)
+	}
+	if len(page.Missing) != 1 || page.Missing[0] != 5 {
+		t.Errorf("Missing = %v, want [5]", page.Missing)
+	}
+
+	if _, err := mock.FetchPullRequest(context.Background(), "test", "repo", 5); !errors.Is(err, relaierrors.ErrPullRequestNotFound) {
+		t.Errorf("expected ErrPullRequestNotFound, got %v", err)
+	}
+}
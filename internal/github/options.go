@@ -0,0 +1,123 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultGraphQLEndpoint is the GraphQL endpoint for public GitHub.com.
+const DefaultGraphQLEndpoint = "https://api.github.com/graphql"
+
+// ClientOption configures optional behavior of a GraphQLClient.
+// Options are applied in order by NewGraphQLClient.
+type ClientOption func(*clientOptions)
+
+// clientOptions holds the settings collected from ClientOption values.
+type clientOptions struct {
+	endpoint  string
+	transport http.RoundTripper
+	waiter    RateLimitWaiter
+	retry     RetryPolicy
+	timeout   time.Duration
+	tokens    TokenSource
+	pool      []PooledToken
+}
+
+// defaultClientOptions returns the settings used when no options are given.
+func defaultClientOptions() *clientOptions {
+	return &clientOptions{
+		endpoint: DefaultGraphQLEndpoint,
+		retry:    DefaultRetryPolicy(),
+	}
+}
+
+// WithEndpoint sets the GraphQL endpoint the client sends queries to.
+// Use this for GitHub Enterprise Server, whose endpoint has the form
+// https://github.example.com/api/graphql. An empty endpoint is ignored.
+func WithEndpoint(endpoint string) ClientOption {
+	return func(o *clientOptions) {
+		if endpoint != "" {
+			o.endpoint = endpoint
+		}
+	}
+}
+
+// WithTransport replaces the base HTTP transport used for API requests.
+// Authentication, the User-Agent header and the response size limit are
+// still applied on top of the provided transport. A nil transport is ignored.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(o *clientOptions) {
+		if transport != nil {
+			o.transport = transport
+		}
+	}
+}
+
+// WithRateLimitWaiter makes the client wait for the rate limit to reset
+// instead of failing with ErrRateLimit. The waiter is called whenever the
+// budget is used up or GitHub rejects a query for exceeding it, and the
+// query is retried once it returns. A nil waiter is ignored.
+func WithRateLimitWaiter(waiter RateLimitWaiter) ClientOption {
+	return func(o *clientOptions) {
+		if waiter != nil {
+			o.waiter = waiter
+		}
+	}
+}
+
+// WithRetryPolicy replaces the policy used to retry queries that fail with a
+// transient error. Use a MaxAttempts of 1 to disable retries.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.retry = policy
+	}
+}
+
+// WithRequestTimeout limits how long a single HTTP request may take,
+// including reading the response body. A request that times out is retried
+// like any other transient failure. Zero or a negative value means no limit.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+// WithTokenSource authenticates requests with tokens from source instead of
+// the static token passed to NewGraphQLClient. The source is asked for a
+// token before every request, which lets it refresh tokens that expire
+// during a long fetch. A nil source is ignored.
+func WithTokenSource(source TokenSource) ClientOption {
+	return func(o *clientOptions) {
+		if source != nil {
+			o.tokens = source
+		}
+	}
+}
+
+// WithTokenPool spreads queries across several tokens, each with its own
+// rate limit budget. Every query uses the token with the most remaining
+// budget, and a token that runs out is parked until its budget resets. The
+// pool replaces the token passed to NewGraphQLClient and any WithTokenSource.
+func WithTokenPool(tokens ...PooledToken) ClientOption {
+	return func(o *clientOptions) {
+		if len(tokens) > 0 {
+			o.pool = tokens
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"time"
+)
+
+// PooledToken is one named token source of a token pool. The name
+// identifies the token in rate limit statistics, so it should not be the
+// token itself.
+type PooledToken struct {
+	Name   string
+	Source TokenSource
+}
+
+// credential is a token source together with the rate limit budget of the
+// token it supplies.
+type credential struct {
+	name   string
+	tokens TokenSource
+	limits *rateLimiter
+}
+
+// credentialKey is the context key carrying the credential a request is
+// sent with from the client to its transport.
+type credentialKey struct{}
+
+// withCredential returns a context whose requests are sent with cred.
+func withCredential(ctx context.Context, cred *credential) context.Context {
+	return context.WithValue(ctx, credentialKey{}, cred)
+}
+
+// credentialFrom returns the credential a request is sent with, if any.
+func credentialFrom(ctx context.Context) *credential {
+	cred, _ := ctx.Value(credentialKey{}).(*credential)
+	return cred
+}
+
+// credentialPool holds the credentials a client authenticates with. Each
+// query uses the credential with the most remaining budget; exhausted ones
+// are parked until their reset time. A client with a single token has a
+// pool of one.
+type credentialPool struct {
+	creds []*credential
+}
+
+// newCredentialPool creates a pool for the given tokens, all sharing waiter.
+func newCredentialPool(tokens []PooledToken, waiter RateLimitWaiter) *credentialPool {
+	pool := &credentialPool{}
+	for _, t := range tokens {
+		pool.creds = append(pool.creds, &credential{
+			name:   t.Name,
+			tokens: t.Source,
+			limits: &rateLimiter{wait: waiter},
+		})
+	}
+	return pool
+}
+
+// acquire returns the credential for the next query: the one with the most
+// remaining budget among those not exhausted. When every credential is
+// exhausted it waits for the earliest reset, or returns ErrRateLimit if no
+// waiter is configured.
+func (p *credentialPool) acquire(ctx context.Context) (*credential, error) {
+	for {
+		now := time.Now()
+		var best, earliest *credential
+		bestBudget := -1
+		var earliestReset time.Time
+		for _, cred := range p.creds {
+			if until := cred.limits.exhaustedUntil(now); !until.IsZero() {
+				if earliest == nil || until.Before(earliestReset) {
+					earliest, earliestReset = cred, until
+				}
+				continue
+			}
+			if budget := cred.limits.budget(); budget > bestBudget {
+				best, bestBudget = cred, budget
+			}
+		}
+		if best != nil {
+			return best, nil
+		}
+		if err := earliest.limits.waitIfExhausted(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// canRotate reports whether a query rejected for exceeding the rate limit
+// can be retried, either with another token or after waiting for the reset.
+func (p *credentialPool) canRotate() bool {
+	return len(p.creds) > 1 || p.creds[0].limits.canWait()
+}
+
+// stats adds up the budget consumed by every credential. Per-token figures
+// are included when the pool holds more than one token.
+func (p *credentialPool) stats() RateLimitStats {
+	if len(p.creds) == 1 {
+		return p.creds[0].limits.stats()
+	}
+
+	var total RateLimitStats
+	for _, cred := range p.creds {
+		s := cred.limits.stats()
+		total.PointsUsed += s.PointsUsed
+		total.Limit += s.Limit
+		total.Remaining += s.Remaining
+		total.Waits += s.Waits
+		total.WaitTime += s.WaitTime
+		if !s.ResetAt.IsZero() && (total.ResetAt.IsZero() || s.ResetAt.Before(total.ResetAt)) {
+			total.ResetAt = s.ResetAt
+		}
+		total.Tokens = append(total.Tokens, TokenRateLimitStats{
+			Name:       cred.name,
+			PointsUsed: s.PointsUsed,
+			Limit:      s.Limit,
+			Remaining:  s.Remaining,
+			ResetAt:    s.ResetAt,
+		})
+	}
+	return total
+}
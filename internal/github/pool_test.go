@@ -0,0 +1,97 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGraphQLClient_TokenPoolRotatesOnBudget(t *testing.T) {
+	resetAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	remaining := map[string]int{"Bearer token-a": 1, "Bearer token-b": 4000}
+
+	var mu sync.Mutex
+	var used []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		mu.Lock()
+		used = append(used, auth)
+		remaining[auth]--
+		left := remaining[auth]
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":{"rateLimit":{"cost":1,"limit":5000,"remaining":%d,"resetAt":%q},"search":{"issueCount":7}}}`,
+			left, resetAt.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("", WithEndpoint(server.URL), WithTokenPool(
+		PooledToken{Name: "a", Source: StaticToken("token-a")},
+		PooledToken{Name: "b", Source: StaticToken("token-b")},
+	))
+	for i := 0; i < 3; i++ {
+		if _, err := client.CountPullRequests(context.Background(), "org", "repo", FetchOptions{}); err != nil {
+			t.Fatalf("CountPullRequests failed: %v", err)
+		}
+	}
+
+	// Token a is used first and parked once its budget is gone
+	want := []string{"Bearer token-a", "Bearer token-b", "Bearer token-b"}
+	if fmt.Sprint(used) != fmt.Sprint(want) {
+		t.Errorf("tokens used = %v, want %v", used, want)
+	}
+
+	stats := client.RateLimitStats()
+	if stats.PointsUsed != 3 || stats.Limit != 10000 || stats.Remaining != 3998 || len(stats.Tokens) != 2 {
+		t.Fatalf("unexpected pool stats: %+v", stats)
+	}
+	if a, b := stats.Tokens[0], stats.Tokens[1]; a.Name != "a" || a.PointsUsed != 1 || a.Remaining != 0 || b.Name != "b" || b.PointsUsed != 2 {
+		t.Errorf("unexpected per-token stats: %+v", stats.Tokens)
+	}
+}
+
+func TestGraphQLClient_TokenPoolRotatesOnRateLimitError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-a" {
+			w.Header().Set("Retry-After", "600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"search":{"issueCount":7}}}`)
+	}))
+	defer server.Close()
+
+	// No waiter: the query must move to the other token instead of failing
+	client := NewGraphQLClient("", WithEndpoint(server.URL), WithTokenPool(
+		PooledToken{Name: "a", Source: StaticToken("token-a")},
+		PooledToken{Name: "b", Source: StaticToken("token-b")},
+	))
+	count, err := client.CountPullRequests(context.Background(), "org", "repo", FetchOptions{})
+	if err != nil || count != 7 {
+		t.Fatalf("expected the query to succeed with the second token, got %d, %v", count, err)
+	}
+	if stats := client.RateLimitStats(); stats.Waits != 0 {
+		t.Errorf("expected no waiting while another token has budget, got %+v", stats)
+	}
+}
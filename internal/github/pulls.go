@@ -0,0 +1,142 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/shurcooL/graphql"
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+)
+
+// pullRequestBatchSize is the number of pull requests requested by number in
+// a single query. Each one carries the full pullRequestNode selection, so it
+// matches the page size used for complex search queries.
+const pullRequestBatchSize = complexityPageSize
+
+// FetchPullRequest fetches a single pull request by number. It returns an
+// error wrapping ErrPullRequestNotFound when the repository has no such PR.
+func (c *GraphQLClient) FetchPullRequest(ctx context.Context, owner, repo string, number int) (*PullRequest, error) {
+	page, err := c.FetchPullRequestsByNumber(ctx, owner, repo, []int{number})
+	if err != nil {
+		return nil, err
+	}
+	if len(page.PullRequests) == 0 {
+		return nil, fmt.Errorf("pull request #%d not found in %s/%s: %w", number, owner, repo, relaierrors.ErrPullRequestNotFound)
+	}
+	return &page.PullRequests[0], nil
+}
+
+// FetchPullRequestsByNumber fetches the pull requests with the given numbers,
+// in the order given. Up to pullRequestBatchSize of them are requested in one
+// query through aliased pullRequest fields. Numbers the repository has no PR
+// for are left out of the page and listed in its Missing.
+func (c *GraphQLClient) FetchPullRequestsByNumber(ctx context.Context, owner, repo string, numbers []int) (*PullRequestPage, error) {
+	page := &PullRequestPage{
+		PullRequests: make([]PullRequest, 0, len(numbers)),
+	}
+
+	for start := 0; start < len(numbers); start += pullRequestBatchSize {
+		end := start + pullRequestBatchSize
+		if end > len(numbers) {
+			end = len(numbers)
+		}
+		batch := numbers[start:end]
+
+		nodes, err := c.queryPullRequestBatch(ctx, owner, repo, batch)
+		page.APICalls++
+		if err != nil {
+			return nil, err
+		}
+
+		for i, node := range nodes {
+			if node == nil {
+				page.Missing = append(page.Missing, batch[i])
+				continue
+			}
+
+			// Fetch the rest of any nested connection truncated by the query
+			followUps, err := c.completeNestedConnections(ctx, owner, repo, node)
+			page.APICalls += followUps
+			if err != nil {
+				return nil, err
+			}
+			page.PullRequests = append(page.PullRequests, c.convertGraphQLPR(node))
+		}
+	}
+
+	page.TotalCount = len(page.PullRequests)
+	stats := c.RateLimitStats()
+	page.RateLimit = &stats
+	page.Retries = c.Retries()
+
+	return page, nil
+}
+
+// queryPullRequestBatch requests the pull requests with the given numbers in
+// one query and returns their nodes in the same order, nil for numbers the
+// repository has no PR for. The number of aliased fields varies, so the query
+// type is built at run time.
+func (c *GraphQLClient) queryPullRequestBatch(ctx context.Context, owner, repo string, numbers []int) ([]*pullRequestNode, error) {
+	nodeType := reflect.TypeOf((*pullRequestNode)(nil))
+	fields := make([]reflect.StructField, len(numbers))
+	for i, number := range numbers {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("PR%d", i),
+			Type: nodeType,
+			Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"pr%d: pullRequest(number: %d)"`, i, number)),
+		}
+	}
+	queryType := reflect.StructOf([]reflect.StructField{
+		{
+			Name: "RateLimit",
+			Type: reflect.TypeOf((*rateLimitNode)(nil)),
+			Tag:  `graphql:"rateLimit"`,
+		},
+		{
+			Name: "Repository",
+			Type: reflect.StructOf(fields),
+			Tag:  `graphql:"repository(owner: $owner, name: $repo)"`,
+		},
+	})
+
+	query := reflect.New(queryType)
+	variables := withTimelineItemTypes(map[string]interface{}{
+		"owner": graphql.String(owner),
+		"repo":  graphql.String(repo),
+	})
+
+	// GitHub answers with the PRs it found and an error for each one it did
+	// not, so an unresolved PR only marks that alias as missing
+	if err := c.query(ctx, query.Interface(), variables); err != nil && !isUnresolvedPullRequest(err) {
+		return nil, c.mapError(err, owner, repo)
+	}
+
+	repository := query.Elem().Field(1)
+	nodes := make([]*pullRequestNode, len(numbers))
+	for i := range nodes {
+		nodes[i], _ = repository.Field(i).Interface().(*pullRequestNode)
+	}
+	return nodes, nil
+}
+
+// isUnresolvedPullRequest reports whether err is GitHub's answer to a query
+// for a pull request number the repository does not have.
+func isUnresolvedPullRequest(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "could not resolve to a pullrequest")
+}
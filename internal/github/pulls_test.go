@@ -0,0 +1,144 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+)
+
+// pullRequestBatchServer answers aliased pullRequest queries, resolving the
+// numbers in found and reporting the others as unresolved like GitHub does.
+// It records the numbers requested by each query.
+func pullRequestBatchServer(t *testing.T, found map[int]bool, queries *[][]int) *httptest.Server {
+	alias := regexp.MustCompile(`(pr\d+): pullRequest\(number: (\d+)\)`)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+
+		var numbers []int
+		var fields, errs []string
+		for _, m := range alias.FindAllStringSubmatch(req.Query, -1) {
+			var number int
+			fmt.Sscan(m[2], &number)
+			numbers = append(numbers, number)
+			if found[number] {
+				fields = append(fields, fmt.Sprintf(`"%s":{"number":%d,"author":{"login":"alice"},"createdAt":"2024-01-01T00:00:00Z","updatedAt":"2024-01-01T00:00:00Z"}`, m[1], number))
+			} else {
+				fields = append(fields, fmt.Sprintf(`"%s":null`, m[1]))
+				errs = append(errs, fmt.Sprintf(`{"type":"NOT_FOUND","path":["repository","%s"],"message":"Could not resolve to a PullRequest with the number of %d."}`, m[1], number))
+			}
+		}
+		*queries = append(*queries, numbers)
+
+		body := fmt.Sprintf(`{"data":{"rateLimit":{"cost":1,"limit":5000,"remaining":4990,"resetAt":"2030-01-01T00:00:00Z"},"repository":{%s}}`, strings.Join(fields, ","))
+		if len(errs) > 0 {
+			body += fmt.Sprintf(`,"errors":[%s]`, strings.Join(errs, ","))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body+"}")
+	}))
+}
+
+func TestFetchPullRequestsByNumber(t *testing.T) {
+	found := map[int]bool{}
+	var numbers []int
+	for n := 1; n <= 12; n++ {
+		numbers = append(numbers, n*10)
+		found[n*10] = n != 3
+	}
+
+	var queries [][]int
+	server := pullRequestBatchServer(t, found, &queries)
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	page, err := client.FetchPullRequestsByNumber(context.Background(), "org", "repo", numbers)
+	if err != nil {
+		t.Fatalf("FetchPullRequestsByNumber failed: %v", err)
+	}
+
+	// Twelve numbers take a full batch and a partial one
+	wantQueries := [][]int{numbers[:pullRequestBatchSize], numbers[pullRequestBatchSize:]}
+	if !reflect.DeepEqual(queries, wantQueries) {
+		t.Errorf("queries = %v, want %v", queries, wantQueries)
+	}
+
+	var got []int
+	for _, pr := range page.PullRequests {
+		got = append(got, pr.Number)
+	}
+	want := []int{10, 20, 40, 50, 60, 70, 80, 90, 100, 110, 120}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PR numbers = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(page.Missing, []int{30}) {
+		t.Errorf("Missing = %v, want [30]", page.Missing)
+	}
+	if page.APICalls != 2 {
+		t.Errorf("APICalls = %d, want 2", page.APICalls)
+	}
+	if page.RateLimit == nil || page.RateLimit.Remaining != 4990 {
+		t.Errorf("RateLimit = %+v, want 4990 remaining", page.RateLimit)
+	}
+}
+
+func TestFetchPullRequest_NotFound(t *testing.T) {
+	var queries [][]int
+	server := pullRequestBatchServer(t, map[int]bool{7: true}, &queries)
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	pr, err := client.FetchPullRequest(context.Background(), "org", "repo", 7)
+	if err != nil {
+		t.Fatalf("FetchPullRequest failed: %v", err)
+	}
+	if pr.Number != 7 {
+		t.Errorf("Number = %d, want 7", pr.Number)
+	}
+
+	_, err = client.FetchPullRequest(context.Background(), "org", "repo", 8)
+	if !errors.Is(err, relaierrors.ErrPullRequestNotFound) {
+		t.Errorf("expected ErrPullRequestNotFound, got %v", err)
+	}
+}
+
+func TestFetchPullRequestsByNumber_UnknownRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"repository":null},"errors":[{"type":"NOT_FOUND","message":"Could not resolve to a Repository with the name 'org/missing'."}]}`)
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	_, err := client.FetchPullRequestsByNumber(context.Background(), "org", "missing", []int{1})
+	if !errors.Is(err, relaierrors.ErrRepoNotFound) {
+		t.Errorf("expected ErrRepoNotFound, got %v", err)
+	}
+}
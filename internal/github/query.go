@@ -0,0 +1,205 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"time"
+
+	"github.com/shurcooL/graphql"
+)
+
+// The types in this file describe the shape of the GraphQL pull request
+// selection. They are shared by the repository and search queries and by the
+// follow-up queries that page through nested connections, so the query text
+// and convertGraphQLPR always agree on the fields requested.
+
+// PullRequestTimelineItemsItemType is GitHub's enum of pull request timeline
+// item types. The query builder names variable types after their Go type,
+// so the name must match the schema.
+type PullRequestTimelineItemsItemType string
+
+// timelineItemTypes are the timeline events recorded as conversations. Every
+// query that selects timelineItems passes them as $itemTypes, added with
+// withTimelineItemTypes.
+var timelineItemTypes = []PullRequestTimelineItemsItemType{
+	"ISSUE_COMMENT",
+	"REVIEW_REQUESTED_EVENT",
+	"LABELED_EVENT",
+	"UNLABELED_EVENT",
+	"READY_FOR_REVIEW_EVENT",
+	"HEAD_REF_FORCE_PUSHED_EVENT",
+	"CLOSED_EVENT",
+	"REOPENED_EVENT",
+	"MERGED_EVENT",
+}
+
+// withTimelineItemTypes adds $itemTypes to the variables of a query that
+// selects timelineItems and returns them.
+func withTimelineItemTypes(variables map[string]interface{}) map[string]interface{} {
+	variables["itemTypes"] = timelineItemTypes
+	return variables
+}
+
+// pageInfo holds the cursor information for a GraphQL connection.
+type pageInfo struct {
+	HasNextPage graphql.Boolean
+	EndCursor   graphql.String
+}
+
+// connection is a generic GraphQL connection with pagination details.
+// The page size and cursor arguments are supplied by the field's graphql tag.
+type connection[T any] struct {
+	TotalCount graphql.Int
+	PageInfo   pageInfo
+	Nodes      []T
+}
+
+// needsMore reports whether the server holds nodes beyond those fetched so far.
+func (c *connection[T]) needsMore() bool {
+	return bool(c.PageInfo.HasNextPage) && len(c.Nodes) < int(c.TotalCount)
+}
+
+// actorNode is a GitHub actor such as a user, bot or mannequin.
+type actorNode struct {
+	Login graphql.String `graphql:"login"`
+}
+
+// refNode is a git reference together with the commit it points to.
+type refNode struct {
+	Name   graphql.String
+	Target struct {
+		OID graphql.String `graphql:"oid"`
+	}
+}
+
+// labelNode is a label applied to a pull request.
+type labelNode struct {
+	Name        graphql.String
+	Color       graphql.String
+	Description graphql.String
+}
+
+// reviewRequestNode is a pending review request on a pull request.
+type reviewRequestNode struct {
+	RequestedReviewer struct {
+		User struct {
+			Login graphql.String
+		} `graphql:"... on User"`
+	} `graphql:"requestedReviewer"`
+}
+
+// fileNode is a file changed by a pull request.
+type fileNode struct {
+	Path       graphql.String
+	Additions  graphql.Int
+	Deletions  graphql.Int
+	ChangeType graphql.String
+}
+
+// reviewNode is a submitted review on a pull request.
+type reviewNode struct {
+	ID          graphql.String
+	State       graphql.String
+	Body        graphql.String
+	SubmittedAt *time.Time
+	Author      actorNode `graphql:"author"`
+}
+
+// gitActorNode is the author or committer recorded on a git commit.
+type gitActorNode struct {
+	User  *actorNode `graphql:"user"`
+	Name  graphql.String
+	Email graphql.String
+}
+
+// commitNode is a commit that is part of a pull request.
+type commitNode struct {
+	Commit struct {
+		OID           graphql.String `graphql:"oid"`
+		Message       graphql.String
+		AuthoredDate  time.Time
+		CommittedDate time.Time
+		Additions     graphql.Int
+		Deletions     graphql.Int
+		Author        gitActorNode `graphql:"author"`
+		Committer     gitActorNode `graphql:"committer"`
+		Parents       struct {
+			Nodes []struct {
+				OID graphql.String `graphql:"oid"`
+			}
+		} `graphql:"parents(first: 2)"`
+	} `graphql:"commit"`
+}
+
+// pullRequestNode is the full pull request selection used by every query
+// that returns pull requests.
+type pullRequestNode struct {
+	Number             graphql.Int
+	Title              graphql.String
+	State              graphql.String
+	Body               graphql.String
+	URL                graphql.String
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	ClosedAt           *time.Time
+	MergedAt           *time.Time
+	Merged             graphql.Boolean
+	Mergeable          graphql.String
+	Additions          graphql.Int
+	Deletions          graphql.Int
+	ChangedFiles       graphql.Int
+	TotalCommentsCount graphql.Int
+
+	// Author information
+	Author actorNode `graphql:"author"`
+
+	// MergedBy information
+	MergedBy *actorNode `graphql:"mergedBy"`
+
+	// Base and head references
+	BaseRef *refNode `graphql:"baseRef"`
+	HeadRef *refNode `graphql:"headRef"`
+
+	// Merge commit SHA
+	MergeCommit *struct {
+		OID graphql.String `graphql:"oid"`
+	} `graphql:"mergeCommit"`
+
+	// Labels
+	Labels connection[labelNode] `graphql:"labels(first: 100)"`
+
+	// Assignees
+	Assignees connection[actorNode] `graphql:"assignees(first: 100)"`
+
+	// Requested reviewers
+	ReviewRequests struct {
+		Nodes []reviewRequestNode
+	} `graphql:"reviewRequests(first: 100)"`
+
+	// Files changed
+	Files connection[fileNode] `graphql:"files(first: 100)"`
+
+	// Reviews
+	Reviews connection[reviewNode] `graphql:"reviews(first: 50)"`
+
+	// Commits
+	Commits connection[commitNode] `graphql:"commits(first: 100)"`
+
+	// Inline review comment threads
+	ReviewThreads connection[reviewThreadNode] `graphql:"reviewThreads(first: 50)"`
+
+	// Timeline events recorded as conversations
+	TimelineItems connection[timelineItemNode] `graphql:"timelineItems(first: 100, itemTypes: $itemTypes)"`
+}
@@ -0,0 +1,280 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shurcooL/graphql"
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+)
+
+// defaultRateLimitWait is how long to wait after a rate limit error that
+// carries neither a Retry-After nor an X-RateLimit-Reset header. GitHub asks
+// clients to wait at least a minute before retrying in that case.
+const defaultRateLimitWait = time.Minute
+
+// maxRateLimitRetries is the number of times a query is retried after a
+// primary rate limit error before the error is returned.
+const maxRateLimitRetries = 5
+
+// RateLimitWaiter blocks until the rate limit resets at resetAt, or returns
+// the context's error if ctx is done first. Implementations may report the
+// wait to the user.
+type RateLimitWaiter func(ctx context.Context, resetAt time.Time) error
+
+// RateLimitStats summarizes the rate limit budget a client has consumed.
+// Limit, Remaining and ResetAt describe the budget as last reported by
+// GitHub and are zero until a response has reported them.
+type RateLimitStats struct {
+	// PointsUsed is the total cost of the queries the client has executed.
+	PointsUsed int
+	Limit      int
+	Remaining  int
+	ResetAt    time.Time
+
+	// Waits and WaitTime record how often and how long the client waited
+	// for the rate limit to reset.
+	Waits    int
+	WaitTime time.Duration
+
+	// Tokens breaks the usage down by token when the client rotates across
+	// a token pool. It is empty for a client with a single token.
+	Tokens []TokenRateLimitStats
+}
+
+// TokenRateLimitStats is the rate limit budget consumed by one token of a
+// token pool.
+type TokenRateLimitStats struct {
+	Name       string
+	PointsUsed int
+	Limit      int
+	Remaining  int
+	ResetAt    time.Time
+}
+
+// rateLimitNode is GitHub's rateLimit object, requested alongside every query.
+type rateLimitNode struct {
+	Cost      graphql.Int
+	Limit     graphql.Int
+	Remaining graphql.Int
+	ResetAt   time.Time
+}
+
+// rateLimited is embedded in every top-level query so each response reports
+// the query's cost and the remaining budget.
+type rateLimited struct {
+	RateLimit *rateLimitNode `graphql:"rateLimit"`
+}
+
+// rateLimitInfo returns the rateLimit object decoded from the response.
+func (r *rateLimited) rateLimitInfo() *rateLimitNode {
+	return r.RateLimit
+}
+
+// rateLimitReporter is implemented by queries that embed rateLimited.
+type rateLimitReporter interface {
+	rateLimitInfo() *rateLimitNode
+}
+
+// rateLimitInfoOf returns the rateLimit object decoded into the query q, or
+// nil when q does not request one. Queries built at run time with
+// reflect.StructOf cannot embed rateLimited, so their RateLimit field is
+// read directly.
+func rateLimitInfoOf(q interface{}) *rateLimitNode {
+	if reporter, ok := q.(rateLimitReporter); ok {
+		return reporter.rateLimitInfo()
+	}
+	v := reflect.ValueOf(q)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	field := v.Elem().FieldByName("RateLimit")
+	if !field.IsValid() {
+		return nil
+	}
+	node, _ := field.Interface().(*rateLimitNode)
+	return node
+}
+
+// rateLimiter tracks the rate limit budget reported by GitHub, both in the
+// rateLimit object of query responses and in the X-RateLimit-* and
+// Retry-After response headers. It is shared by the client and its
+// transport, so it is safe for concurrent use.
+type rateLimiter struct {
+	mu sync.Mutex
+
+	// wait is called to sleep until the budget resets. When nil, an exhausted
+	// budget is reported as ErrRateLimit instead.
+	wait RateLimitWaiter
+
+	known       bool
+	limit       int
+	remaining   int
+	resetAt     time.Time
+	retryAfter  time.Time
+	parkedUntil time.Time
+
+	pointsUsed int
+	waits      int
+	waitTime   time.Duration
+}
+
+// observeHeaders records the rate limit state carried by response headers.
+func (r *rateLimiter) observeHeaders(header http.Header, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil {
+		r.known = true
+		r.remaining = v
+	}
+	if v, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		r.limit = v
+	}
+	if v, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		r.resetAt = time.Unix(v, 0).UTC()
+	}
+	if v, err := strconv.Atoi(header.Get("Retry-After")); err == nil && v >= 0 {
+		r.retryAfter = now.Add(time.Duration(v) * time.Second)
+	}
+}
+
+// observe records the rateLimit object returned with a query.
+func (r *rateLimiter) observe(node *rateLimitNode) {
+	if node == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.known = true
+	r.pointsUsed += int(node.Cost)
+	r.limit = int(node.Limit)
+	r.remaining = int(node.Remaining)
+	if !node.ResetAt.IsZero() {
+		r.resetAt = node.ResetAt
+	}
+}
+
+// stats returns a snapshot of the budget consumed so far.
+func (r *rateLimiter) stats() RateLimitStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return RateLimitStats{
+		PointsUsed: r.pointsUsed,
+		Limit:      r.limit,
+		Remaining:  r.remaining,
+		ResetAt:    r.resetAt,
+		Waits:      r.waits,
+		WaitTime:   r.waitTime,
+	}
+}
+
+// exhaustedUntil returns the time the budget resets if it is known to be
+// used up at now, and the zero time otherwise.
+func (r *rateLimiter) exhaustedUntil(now time.Time) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.parkedUntil.After(now) {
+		return r.parkedUntil
+	}
+	if r.retryAfter.After(now) {
+		return r.retryAfter
+	}
+	if r.known && r.remaining <= 0 && r.resetAt.After(now) {
+		return r.resetAt
+	}
+	return time.Time{}
+}
+
+// waitIfExhausted blocks until the budget resets when it is known to be used
+// up. Without a waiter it returns ErrRateLimit instead of blocking.
+func (r *rateLimiter) waitIfExhausted(ctx context.Context) error {
+	resetAt := r.exhaustedUntil(time.Now())
+	if resetAt.IsZero() {
+		return nil
+	}
+	if r.wait == nil {
+		return fmt.Errorf("GitHub API rate limit exhausted until %s: %w", resetAt.Format(time.RFC3339), relaierrors.ErrRateLimit)
+	}
+	return r.waitUntil(ctx, resetAt)
+}
+
+// park marks the budget as used up after a query failed with a rate limit
+// error, until the time given by the response headers or for
+// defaultRateLimitWait.
+func (r *rateLimiter) park(now time.Time) {
+	until := r.exhaustedUntil(now)
+	if until.IsZero() {
+		until = now.Add(defaultRateLimitWait)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.parkedUntil = until
+}
+
+// budget returns the points known to remain. A budget that has not been
+// reported yet counts as unlimited, so unused tokens are preferred.
+func (r *rateLimiter) budget() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.known {
+		return math.MaxInt
+	}
+	return r.remaining
+}
+
+// waitUntil calls the waiter and records the time spent waiting.
+func (r *rateLimiter) waitUntil(ctx context.Context, resetAt time.Time) error {
+	start := time.Now()
+	err := r.wait(ctx, resetAt)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.waits++
+	r.waitTime += time.Since(start)
+	if err == nil {
+		// The budget has been replenished; wait for fresh numbers
+		r.known = false
+		r.retryAfter = time.Time{}
+		r.parkedUntil = time.Time{}
+	}
+	return err
+}
+
+// canWait reports whether a waiter is configured.
+func (r *rateLimiter) canWait() bool {
+	return r.wait != nil
+}
+
+// RateLimitStats returns the rate limit budget consumed by the client so far.
+// For a token pool, the budgets of all tokens are added up and ResetAt is
+// the earliest reset.
+func (c *GraphQLClient) RateLimitStats() RateLimitStats {
+	return c.pool.stats()
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+)
+
+func TestGraphQLClient_RecordsRateLimit(t *testing.T) {
+	resetAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !strings.Contains(req.Query, "rateLimit{cost,limit,remaining,resetAt}") {
+			t.Errorf("expected the query to request the rate limit, got %q", req.Query)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":{"rateLimit":{"cost":3,"limit":5000,"remaining":4990,"resetAt":%q},"search":{"issueCount":7}}}`,
+			resetAt.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	for i := 0; i < 2; i++ {
+		if _, err := client.CountPullRequests(context.Background(), "org", "repo", FetchOptions{}); err != nil {
+			t.Fatalf("CountPullRequests failed: %v", err)
+		}
+	}
+
+	stats := client.RateLimitStats()
+	if stats.PointsUsed != 6 || stats.Limit != 5000 || stats.Remaining != 4990 || !stats.ResetAt.Equal(resetAt) {
+		t.Errorf("unexpected rate limit stats: %+v", stats)
+	}
+}
+
+func TestGraphQLClient_RateLimitError(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     map[string]string
+		status     int
+		wantReset  time.Duration
+		wantWaiter bool
+	}{
+		{
+			name:       "retry after header",
+			header:     map[string]string{"Retry-After": "30"},
+			status:     http.StatusTooManyRequests,
+			wantReset:  30 * time.Second,
+			wantWaiter: true,
+		},
+		{
+			name:       "reset header",
+			header:     map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": fmt.Sprint(time.Now().Add(10 * time.Minute).Unix())},
+			status:     http.StatusForbidden,
+			wantReset:  10 * time.Minute,
+			wantWaiter: true,
+		},
+		{
+			name:       "no headers",
+			status:     http.StatusTooManyRequests,
+			wantReset:  defaultRateLimitWait,
+			wantWaiter: true,
+		},
+		{
+			name:   "auto wait disabled",
+			header: map[string]string{"Retry-After": "30"},
+			status: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) == 1 {
+					for k, v := range tt.header {
+						w.Header().Set(k, v)
+					}
+					w.WriteHeader(tt.status)
+					fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"data":{"search":{"issueCount":7}}}`)
+			}))
+			defer server.Close()
+
+			var waits []time.Time
+			opts := []ClientOption{WithEndpoint(server.URL)}
+			if tt.wantWaiter {
+				opts = append(opts, WithRateLimitWaiter(func(ctx context.Context, resetAt time.Time) error {
+					waits = append(waits, resetAt)
+					return nil
+				}))
+			}
+			client := NewGraphQLClient("test-token", opts...)
+
+			start := time.Now()
+			count, err := client.CountPullRequests(context.Background(), "org", "repo", FetchOptions{})
+
+			if !tt.wantWaiter {
+				if !errors.Is(err, relaierrors.ErrRateLimit) {
+					t.Fatalf("expected ErrRateLimit, got %v", err)
+				}
+				return
+			}
+			if err != nil || count != 7 {
+				t.Fatalf("expected the retried query to succeed, got %d, %v", count, err)
+			}
+			if len(waits) != 1 {
+				t.Fatalf("expected one wait, got %d", len(waits))
+			}
+			if got := waits[0].Sub(start); got < tt.wantReset-5*time.Second || got > tt.wantReset+5*time.Second {
+				t.Errorf("waited until %v after start, want about %v", got, tt.wantReset)
+			}
+			if stats := client.RateLimitStats(); stats.Waits != 1 {
+				t.Errorf("expected the wait to be recorded, got %+v", stats)
+			}
+		})
+	}
+}
+
+func TestGraphQLClient_WaitsBeforeExhaustedBudget(t *testing.T) {
+	resetAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":{"rateLimit":{"cost":1,"limit":5000,"remaining":0,"resetAt":%q},"search":{"issueCount":7}}}`,
+			resetAt.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	var waitedFor time.Time
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL),
+		WithRateLimitWaiter(func(ctx context.Context, until time.Time) error {
+			if n := atomic.LoadInt32(&requests); n != 1 {
+				t.Errorf("expected to wait before the second request, %d requests sent", n)
+			}
+			waitedFor = until
+			return nil
+		}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.CountPullRequests(context.Background(), "org", "repo", FetchOptions{}); err != nil {
+			t.Fatalf("CountPullRequests failed: %v", err)
+		}
+	}
+
+	if !waitedFor.Equal(resetAt) {
+		t.Errorf("expected to wait until %v, got %v", resetAt, waitedFor)
+	}
+}
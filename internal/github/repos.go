@@ -0,0 +1,107 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shurcooL/graphql"
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+)
+
+// repositoryPageSize is the number of repositories listed per query.
+const repositoryPageSize = 100
+
+// repositoryNode is the selection of a repository listed by
+// GetOrganizationRepositories.
+type repositoryNode struct {
+	Name             graphql.String
+	IsArchived       graphql.Boolean
+	IsFork           graphql.Boolean
+	Visibility       graphql.String
+	PushedAt         *time.Time
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name graphql.String
+			}
+		}
+	} `graphql:"repositoryTopics(first: 20)"`
+}
+
+// repository converts the node into a Repository owned by owner.
+func (n *repositoryNode) repository(owner string) Repository {
+	r := Repository{
+		Owner:      owner,
+		Name:       string(n.Name),
+		IsArchived: bool(n.IsArchived),
+		IsFork:     bool(n.IsFork),
+		Visibility: string(n.Visibility),
+		PushedAt:   n.PushedAt,
+	}
+	for _, t := range n.RepositoryTopics.Nodes {
+		r.Topics = append(r.Topics, string(t.Topic.Name))
+	}
+	return r
+}
+
+// GetOrganizationRepositories lists the repositories owned by an organization
+// or user that match filter, sorted by name. Repositories the owner can only
+// access as a collaborator or organization member are not included. The
+// filter is applied to each page as it arrives rather than in the query, so
+// it works the same on GitHub Enterprise Server versions that lack the
+// newer connection arguments.
+func (c *GraphQLClient) GetOrganizationRepositories(ctx context.Context, owner string, filter RepositoryFilter) ([]Repository, error) {
+	var repos []Repository
+	var after *graphql.String
+
+	for {
+		var query struct {
+			rateLimited
+			RepositoryOwner *struct {
+				Repositories struct {
+					PageInfo pageInfo
+					Nodes    []repositoryNode
+				} `graphql:"repositories(first: $first, after: $after, ownerAffiliations: [OWNER], orderBy: {field: NAME, direction: ASC})"`
+			} `graphql:"repositoryOwner(login: $owner)"`
+		}
+		variables := map[string]interface{}{
+			"owner": graphql.String(owner),
+			"first": graphql.Int(repositoryPageSize),
+			"after": after,
+		}
+
+		if err := c.query(ctx, &query, variables); err != nil {
+			return nil, c.mapError(err, owner, "*")
+		}
+		if query.RepositoryOwner == nil {
+			return nil, fmt.Errorf("organization or user '%s' not found. Please check the name and your access permissions: %w", owner, relaierrors.ErrRepoNotFound)
+		}
+
+		conn := query.RepositoryOwner.Repositories
+		for _, node := range conn.Nodes {
+			if r := node.repository(owner); filter.Matches(r) {
+				repos = append(repos, r)
+			}
+		}
+		if !conn.PageInfo.HasNextPage || len(conn.Nodes) == 0 {
+			return repos, nil
+		}
+		cursor := conn.PageInfo.EndCursor
+		after = &cursor
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	relaierrors "github.com/sirseerhq/sirseer-relay/internal/errors"
+)
+
+func TestGetOrganizationRepositories(t *testing.T) {
+	pages := []string{
+		`{"data":{"repositoryOwner":{"repositories":{"pageInfo":{"hasNextPage":true,"endCursor":"c1"},"nodes":[` +
+			`{"name":"api","visibility":"PUBLIC","pushedAt":"2024-05-01T00:00:00Z","repositoryTopics":{"nodes":[{"topic":{"name":"backend"}}]}},` +
+			`{"name":"old","isArchived":true,"visibility":"PUBLIC"}]}}}}`,
+		`{"data":{"repositoryOwner":{"repositories":{"pageInfo":{"hasNextPage":false,"endCursor":"c2"},"nodes":[` +
+			`{"name":"web","isFork":false,"visibility":"PRIVATE"}]}}}}`,
+	}
+	var cursors []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if req.Variables["owner"] != "acme" {
+			t.Errorf("owner = %v, want acme", req.Variables["owner"])
+		}
+		cursors = append(cursors, req.Variables["after"])
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, pages[len(cursors)-1])
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	repos, err := client.GetOrganizationRepositories(context.Background(), "acme", RepositoryFilter{ExcludeArchived: true})
+	if err != nil {
+		t.Fatalf("GetOrganizationRepositories failed: %v", err)
+	}
+
+	pushedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	want := []Repository{
+		{Owner: "acme", Name: "api", Visibility: "PUBLIC", Topics: []string{"backend"}, PushedAt: &pushedAt},
+		{Owner: "acme", Name: "web", Visibility: "PRIVATE"},
+	}
+	if !reflect.DeepEqual(repos, want) {
+		t.Errorf("repositories = %v, want %v", repos, want)
+	}
+	if !reflect.DeepEqual(cursors, []interface{}{nil, "c1"}) {
+		t.Errorf("cursors = %v, want [<nil> c1]", cursors)
+	}
+}
+
+func TestGetOrganizationRepositories_UnknownOwner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"repositoryOwner":null}}`)
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	_, err := client.GetOrganizationRepositories(context.Background(), "nobody", RepositoryFilter{})
+	if !errors.Is(err, relaierrors.ErrRepoNotFound) {
+		t.Errorf("expected ErrRepoNotFound, got %v", err)
+	}
+}
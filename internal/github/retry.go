@@ -0,0 +1,151 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy controls how the client retries queries that fail with a
+// transient error: 5xx responses, dropped connections, secondary rate limits
+// and GraphQL resource limit errors. Delays grow exponentially from BaseDelay
+// up to MaxDelay, with jitter so parallel clients don't retry in lockstep.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per query, including the
+	// first. Values below 1 are treated as 1, which disables retries.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+
+	// OnRetry, when set, is called before sleeping for a retry. attempt is
+	// the number of the attempt that failed.
+	OnRetry func(attempt int, delay time.Duration, err error)
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// backoff returns the delay after the given failed attempt (1-based). Half of
+// the exponential delay is fixed and the other half is random.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1)) // #nosec G404 - jitter does not need a secure source
+}
+
+// query executes a GraphQL query and records the rate limit it reports.
+// Transient failures are retried according to the client's RetryPolicy.
+// Each attempt uses the token with the most remaining budget. When every
+// token's budget is known to be used up, it first waits for the earliest
+// reset, and after a primary rate limit error the token is parked and the
+// query retried with another token, or after waiting as long as a
+// RateLimitWaiter was configured with WithRateLimitWaiter. A query rejected
+// as unauthorized is retried once with a new token if the token source
+// implements Invalidator.
+func (c *GraphQLClient) query(ctx context.Context, q interface{}, variables map[string]interface{}) error {
+	attempt, rateLimitHits, reauthenticated := 1, 0, false
+	for {
+		cred, err := c.pool.acquire(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = c.client.Query(withCredential(ctx, cred), q, variables)
+		if err == nil {
+			cred.limits.observe(rateLimitInfoOf(q))
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		switch {
+		case c.inspector.IsTransientError(err):
+			if attempt >= c.retry.MaxAttempts {
+				return err
+			}
+			delay := c.retry.backoff(attempt)
+			// Secondary rate limits say how long to back off
+			if until := cred.limits.exhaustedUntil(time.Now()); !until.IsZero() {
+				if wait := time.Until(until); wait > delay {
+					delay = wait
+				}
+			}
+			if c.retry.OnRetry != nil {
+				c.retry.OnRetry(attempt, delay, err)
+			}
+			atomic.AddInt64(&c.retries, 1)
+			if err := sleepContext(ctx, delay); err != nil {
+				return err
+			}
+			attempt++
+
+		case c.inspector.IsRateLimitError(err) && c.pool.canRotate() && rateLimitHits < maxRateLimitRetries:
+			cred.limits.park(time.Now())
+			rateLimitHits++
+
+		case c.inspector.IsAuthError(err) && !c.inspector.IsRateLimitError(err) && !reauthenticated:
+			// A rotated credential is picked up by resolving the token again
+			invalidator, ok := cred.tokens.(Invalidator)
+			if !ok {
+				return err
+			}
+			invalidator.Invalidate()
+			reauthenticated = true
+
+		default:
+			return err
+		}
+	}
+}
+
+// Retries returns the number of times the client has retried a query after
+// a transient failure.
+func (c *GraphQLClient) Retries() int {
+	return int(atomic.LoadInt64(&c.retries))
+}
+
+// sleepContext sleeps for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 3, max: 400 * time.Millisecond},
+		{attempt: 5, max: time.Second},
+		{attempt: 50, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				got := policy.backoff(tt.attempt)
+				if got < tt.max/2 || got > tt.max {
+					t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.max/2, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphQLClient_RetriesSecondaryRateLimit(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"search":{"issueCount":7}}}`)
+	}))
+	defer server.Close()
+
+	var retried []int
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			retried = append(retried, attempt)
+		},
+	}))
+
+	count, err := client.CountPullRequests(context.Background(), "org", "repo", FetchOptions{})
+	if err != nil || count != 7 {
+		t.Fatalf("expected the retried query to succeed, got %d, %v", count, err)
+	}
+	if len(retried) != 1 || client.Retries() != 1 {
+		t.Errorf("expected one retry, got callbacks %v and %d retries", retried, client.Retries())
+	}
+}
+
+func TestGraphQLClient_RequestTimeoutIsRetried(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"search":{"issueCount":3}}}`)
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token",
+		WithEndpoint(server.URL),
+		WithRequestTimeout(50*time.Millisecond),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	)
+
+	count, err := client.CountPullRequests(context.Background(), "org", "repo", FetchOptions{})
+	if err != nil || count != 3 {
+		t.Fatalf("expected the query to succeed after the slow request timed out, got %d, %v", count, err)
+	}
+	if client.Retries() != 1 {
+		t.Errorf("expected the timed out request to be retried once, got %d retries", client.Retries())
+	}
+}
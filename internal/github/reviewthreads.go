@@ -0,0 +1,75 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"time"
+
+	"github.com/shurcooL/graphql"
+)
+
+// reviewCommentNode is an inline comment within a review thread.
+type reviewCommentNode struct {
+	Author    *actorNode `graphql:"author"`
+	Body      graphql.String
+	CreatedAt time.Time
+}
+
+// reviewThreadNode is a thread of inline review comments anchored to a line
+// of a file in the pull request diff.
+type reviewThreadNode struct {
+	ID         graphql.ID `graphql:"id"`
+	Path       graphql.String
+	Line       *graphql.Int
+	IsResolved graphql.Boolean
+	IsOutdated graphql.Boolean
+	ResolvedBy *actorNode                    `graphql:"resolvedBy"`
+	Comments   connection[reviewCommentNode] `graphql:"comments(first: 50)"`
+}
+
+// convertReviewThread maps a review thread node to a ReviewThread record.
+func convertReviewThread(n *reviewThreadNode) ReviewThread {
+	thread := ReviewThread{
+		Path:       string(n.Path),
+		IsResolved: bool(n.IsResolved),
+		IsOutdated: bool(n.IsOutdated),
+		Comments:   make([]ReviewComment, 0, len(n.Comments.Nodes)),
+	}
+
+	if n.Line != nil {
+		line := int(*n.Line)
+		thread.Line = &line
+	}
+
+	if n.ResolvedBy != nil {
+		thread.ResolvedBy = &User{
+			Login: string(n.ResolvedBy.Login),
+			Type:  "User",
+		}
+	}
+
+	for _, comment := range n.Comments.Nodes {
+		thread.Comments = append(thread.Comments, ReviewComment{
+			Author: User{
+				Login: actorLogin(comment.Author),
+				Type:  "User",
+			},
+			Body:      string(comment.Body),
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+
+	return thread
+}
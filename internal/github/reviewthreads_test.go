@@ -0,0 +1,98 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchPullRequestsSearch_ReviewThreads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.Contains(req.Query, "search("):
+			fmt.Fprint(w, `{"data":{"search":{
+				"pageInfo":{"hasNextPage":false,"endCursor":""},
+				"nodes":[{"number":3,"reviewThreads":{"totalCount":2,"pageInfo":{"hasNextPage":false},"nodes":[
+					{"id":"T1","path":"main.go","line":42,"isResolved":true,"isOutdated":false,
+						"resolvedBy":{"login":"alice"},
+						"comments":{"totalCount":2,"pageInfo":{"hasNextPage":true,"endCursor":"c-1"},
+							"nodes":[{"author":{"login":"alice"},"body":"Needs a nil check","createdAt":"2024-01-01T10:00:00Z"}]}},
+					{"id":"T2","path":"util.go","line":null,"isResolved":false,"isOutdated":true,
+						"resolvedBy":null,
+						"comments":{"totalCount":1,"pageInfo":{"hasNextPage":false},
+							"nodes":[{"author":null,"body":"Typo","createdAt":"2024-01-01T11:00:00Z"}]}}
+				]}}]}}}`)
+		case strings.Contains(req.Query, "node(id: $id)"):
+			if req.Variables["id"] != "T1" || req.Variables["after"] != "c-1" {
+				t.Errorf("unexpected thread follow-up variables: %v", req.Variables)
+			}
+			fmt.Fprint(w, `{"data":{"node":{"comments":{
+				"totalCount":2,"pageInfo":{"hasNextPage":false,"endCursor":"c-2"},
+				"nodes":[{"author":{"login":"bob"},"body":"Done","createdAt":"2024-01-01T10:30:00Z"}]}}}}`)
+		default:
+			t.Errorf("unexpected query: %s", req.Query)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	page, err := client.FetchPullRequestsSearch(context.Background(), "org", "repo", FetchOptions{})
+	if err != nil {
+		t.Fatalf("FetchPullRequestsSearch failed: %v", err)
+	}
+	pr := page.PullRequests[0]
+
+	if pr.ReviewComments != 3 {
+		t.Errorf("expected 3 review comments, got %d", pr.ReviewComments)
+	}
+	if page.APICalls != 2 {
+		t.Errorf("expected 2 API calls, got %d", page.APICalls)
+	}
+	if len(pr.ReviewThreads) != 2 {
+		t.Fatalf("expected 2 review threads, got %d", len(pr.ReviewThreads))
+	}
+
+	resolved := pr.ReviewThreads[0]
+	if resolved.Path != "main.go" || resolved.Line == nil || *resolved.Line != 42 {
+		t.Errorf("unexpected thread location: %+v", resolved)
+	}
+	if !resolved.IsResolved || resolved.ResolvedBy == nil || resolved.ResolvedBy.Login != "alice" {
+		t.Errorf("expected thread resolved by alice, got %+v", resolved)
+	}
+	if len(resolved.Comments) != 2 || resolved.Comments[1].Author.Login != "bob" || resolved.Comments[1].Body != "Done" {
+		t.Errorf("unexpected comments: %+v", resolved.Comments)
+	}
+
+	outdated := pr.ReviewThreads[1]
+	if !outdated.IsOutdated || outdated.Line != nil || outdated.ResolvedBy != nil {
+		t.Errorf("unexpected outdated thread: %+v", outdated)
+	}
+	if outdated.Comments[0].Author.Login != "ghost" {
+		t.Errorf("expected ghost author for deleted account, got %q", outdated.Comments[0].Author.Login)
+	}
+}
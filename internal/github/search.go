@@ -24,7 +24,8 @@ import (
 )
 
 // buildSearchQuery constructs a GitHub search query for pull requests.
-// It builds a query string that filters by repository, type (PR), and optionally by date range.
+// It builds a query string that filters by repository, type (PR), optionally
+// by a date range on opts.DateField, and by any extra qualifiers.
 // The query uses GitHub's search syntax to enable server-side filtering.
 func buildSearchQuery(owner, repo string, opts FetchOptions) string {
 	// If a custom query is provided, use it directly
@@ -38,27 +39,72 @@ func buildSearchQuery(owner, repo string, opts FetchOptions) string {
 		"is:pr",
 	}
 
+	field := opts.DateField
+	if field == "" {
+		field = DateFieldCreated
+	}
+
 	// Add date filters if provided
 	switch {
+	case opts.DateRange != nil:
+		// Exact range with second precision; the range syntax is inclusive,
+		// so the last included second is one before End
+		parts = append(parts, fmt.Sprintf("%s:%s..%s", field,
+			searchTime(opts.DateRange.Start),
+			searchTime(opts.DateRange.End.Add(-time.Second))))
 	case opts.Since != nil && opts.Until != nil:
-		// Range query: created:YYYY-MM-DD..YYYY-MM-DD
-		parts = append(parts, fmt.Sprintf("created:%s..%s",
-			opts.Since.Format("2006-01-02"),
-			opts.Until.Format("2006-01-02")))
+		// Inclusive range from the second holding Since to the last second
+		// before Until
+		parts = append(parts, fmt.Sprintf("%s:%s..%s", field,
+			searchTime(floorSecond(*opts.Since)),
+			searchTime(ceilSecond(*opts.Until).Add(-time.Second))))
 	case opts.Since != nil:
-		// After query: created:>YYYY-MM-DD
-		parts = append(parts, fmt.Sprintf("created:>%s", opts.Since.Format("2006-01-02")))
+		parts = append(parts, fmt.Sprintf("%s:>=%s", field, searchTime(floorSecond(*opts.Since))))
 	case opts.Until != nil:
-		// Before query: created:<YYYY-MM-DD
-		parts = append(parts, fmt.Sprintf("created:<%s", opts.Until.Format("2006-01-02")))
+		parts = append(parts, fmt.Sprintf("%s:<%s", field, searchTime(ceilSecond(*opts.Until))))
 	}
 
-	// Always sort by created date ascending for consistent ordering
-	parts = append(parts, "sort:created-asc")
+	if opts.Qualifiers != "" {
+		parts = append(parts, opts.Qualifiers)
+	}
+
+	// Incremental fetches select by update time and walk PRs in update
+	// order, as do windows on the updated date; otherwise sort by created
+	// date ascending for consistent ordering. Search cannot sort by merged
+	// or closed date.
+	switch {
+	case opts.UpdatedSince != nil:
+		parts = append(parts,
+			fmt.Sprintf("updated:>=%s", searchTime(floorSecond(*opts.UpdatedSince))),
+			"sort:updated-asc")
+	case field == DateFieldUpdated:
+		parts = append(parts, "sort:updated-asc")
+	default:
+		parts = append(parts, "sort:created-asc")
+	}
 
 	return strings.Join(parts, " ")
 }
 
+// searchTime formats t for a search qualifier. Qualifiers take RFC3339
+// timestamps and ignore fractions of a second.
+func searchTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// floorSecond returns t rounded down to a whole second.
+func floorSecond(t time.Time) time.Time {
+	return t.Truncate(time.Second)
+}
+
+// ceilSecond returns t rounded up to a whole second.
+func ceilSecond(t time.Time) time.Time {
+	if floored := t.Truncate(time.Second); floored.Before(t) {
+		return floored.Add(time.Second)
+	}
+	return t
+}
+
 // FetchPullRequestsSearch uses GitHub's search API to fetch pull requests.
 // This method supports date filtering and always returns PRs in chronological order (CREATED_AT ASC).
 // It's more flexible than the pullRequests API and enables server-side date filtering.
@@ -79,154 +125,25 @@ func (c *GraphQLClient) FetchPullRequestsSearch(ctx context.Context, owner, repo
 
 	// Define the comprehensive GraphQL query structure for search
 	var query struct {
+		rateLimited
 		Search struct {
-			PageInfo struct {
+			IssueCount graphql.Int
+			PageInfo   struct {
 				HasNextPage graphql.Boolean
 				EndCursor   graphql.String
 			}
 			Nodes []struct {
-				PullRequest struct {
-					Number             graphql.Int
-					Title              graphql.String
-					State              graphql.String
-					Body               graphql.String
-					URL                graphql.String
-					CreatedAt          time.Time
-					UpdatedAt          time.Time
-					ClosedAt           *time.Time
-					MergedAt           *time.Time
-					Merged             graphql.Boolean
-					Mergeable          graphql.String
-					Additions          graphql.Int
-					Deletions          graphql.Int
-					ChangedFiles       graphql.Int
-					TotalCommentsCount graphql.Int
-
-					// Author information
-					Author struct {
-						Login graphql.String `graphql:"login"`
-					} `graphql:"author"`
-
-					// MergedBy information
-					MergedBy *struct {
-						Login graphql.String `graphql:"login"`
-					} `graphql:"mergedBy"`
-
-					// Base and head references
-					BaseRef *struct {
-						Name   graphql.String
-						Target struct {
-							OID graphql.String `graphql:"oid"`
-						}
-					} `graphql:"baseRef"`
-
-					HeadRef *struct {
-						Name   graphql.String
-						Target struct {
-							OID graphql.String `graphql:"oid"`
-						}
-					} `graphql:"headRef"`
-
-					// Merge commit SHA
-					MergeCommit *struct {
-						OID graphql.String `graphql:"oid"`
-					} `graphql:"mergeCommit"`
-
-					// Labels
-					Labels struct {
-						Nodes []struct {
-							Name        graphql.String
-							Color       graphql.String
-							Description graphql.String
-						}
-					} `graphql:"labels(first: 100)"`
-
-					// Assignees
-					Assignees struct {
-						Nodes []struct {
-							Login graphql.String
-						}
-					} `graphql:"assignees(first: 100)"`
-
-					// Requested reviewers
-					ReviewRequests struct {
-						Nodes []struct {
-							RequestedReviewer struct {
-								User struct {
-									Login graphql.String
-								} `graphql:"... on User"`
-							} `graphql:"requestedReviewer"`
-						}
-					} `graphql:"reviewRequests(first: 100)"`
-
-					// Files changed
-					Files struct {
-						TotalCount graphql.Int
-						Nodes      []struct {
-							Path       graphql.String
-							Additions  graphql.Int
-							Deletions  graphql.Int
-							ChangeType graphql.String
-						}
-					} `graphql:"files(first: 100)"`
-
-					// Reviews
-					Reviews struct {
-						Nodes []struct {
-							ID          graphql.String
-							State       graphql.String
-							Body        graphql.String
-							SubmittedAt *time.Time
-							Author      struct {
-								Login graphql.String
-							} `graphql:"author"`
-						}
-					} `graphql:"reviews(first: 50)"`
-
-					// Commits
-					Commits struct {
-						TotalCount graphql.Int
-						Nodes      []struct {
-							Commit struct {
-								OID           graphql.String `graphql:"oid"`
-								Message       graphql.String
-								AuthoredDate  time.Time
-								CommittedDate time.Time
-								Additions     graphql.Int
-								Deletions     graphql.Int
-								Author        struct {
-									User *struct {
-										Login graphql.String
-									} `graphql:"user"`
-									Name  graphql.String
-									Email graphql.String
-								} `graphql:"author"`
-								Committer struct {
-									User *struct {
-										Login graphql.String
-									} `graphql:"user"`
-									Name  graphql.String
-									Email graphql.String
-								} `graphql:"committer"`
-								Parents struct {
-									Nodes []struct {
-										OID graphql.String `graphql:"oid"`
-									}
-								} `graphql:"parents(first: 2)"`
-							} `graphql:"commit"`
-						}
-					} `graphql:"commits(first: 100)"`
-				} `graphql:"... on PullRequest"`
+				PullRequest pullRequestNode `graphql:"... on PullRequest"`
 			}
 		} `graphql:"search(query: $query, type: ISSUE, first: $first, after: $after)"`
 	}
 
 	// Set up variables
-	variables := map[string]interface{}{
+	variables := withTimelineItemTypes(map[string]interface{}{
 		"query": graphql.String(searchQuery),
 		"first": graphql.Int(int32(pageSize)), // #nosec G115 - pageSize is capped at 100
 		"after": (*graphql.String)(nil),       // Initialize as nil, will be set if provided
-	}
+	})
 
 	// Add after cursor if provided
 	if opts.After != "" {
@@ -235,7 +152,7 @@ func (c *GraphQLClient) FetchPullRequestsSearch(ctx context.Context, owner, repo
 	}
 
 	// Execute the query
-	err := c.client.Query(ctx, &query, variables)
+	err := c.query(ctx, &query, variables)
 	if err != nil {
 		return nil, c.mapError(err, owner, repo)
 	}
@@ -244,14 +161,63 @@ func (c *GraphQLClient) FetchPullRequestsSearch(ctx context.Context, owner, repo
 	page := &PullRequestPage{
 		HasNextPage:  bool(query.Search.PageInfo.HasNextPage),
 		EndCursor:    string(query.Search.PageInfo.EndCursor),
+		TotalCount:   int(query.Search.IssueCount),
 		PullRequests: make([]PullRequest, 0, len(query.Search.Nodes)),
+		APICalls:     1,
 	}
 
 	// Convert each PR using the same converter method
-	for _, node := range query.Search.Nodes {
-		pr := c.convertGraphQLPR(&node.PullRequest)
-		page.PullRequests = append(page.PullRequests, pr)
+	for i := range query.Search.Nodes {
+		node := &query.Search.Nodes[i].PullRequest
+
+		// Drop bot PRs before their nested connections cost extra queries
+		if opts.ExcludeBots && isBotLogin(string(node.Author.Login)) {
+			page.Excluded++
+			continue
+		}
+
+		// Qualifiers round bounds to whole seconds, which can let in PRs
+		// just outside the window; drop them before their nested
+		// connections cost extra queries too
+		dates := PullRequest{CreatedAt: node.CreatedAt, UpdatedAt: node.UpdatedAt, ClosedAt: node.ClosedAt, MergedAt: node.MergedAt}
+		if !opts.inWindow(&dates) {
+			page.Excluded++
+			continue
+		}
+
+		// Fetch the rest of any nested connection truncated by the page size
+		followUps, err := c.completeNestedConnections(ctx, owner, repo, node)
+		page.APICalls += followUps
+		if err != nil {
+			return nil, err
+		}
+		page.PullRequests = append(page.PullRequests, c.convertGraphQLPR(node))
 	}
 
+	stats := c.RateLimitStats()
+	page.RateLimit = &stats
+	page.Retries = c.Retries()
+
 	return page, nil
 }
+
+// CountPullRequests returns the number of pull requests matching the search
+// query built from opts. Only issueCount is requested, so the query is cheap.
+func (c *GraphQLClient) CountPullRequests(ctx context.Context, owner, repo string, opts FetchOptions) (int, error) {
+	var query struct {
+		rateLimited
+		Search struct {
+			IssueCount graphql.Int
+		} `graphql:"search(query: $query, type: ISSUE, first: 1)"`
+	}
+
+	variables := map[string]interface{}{
+		"query": graphql.String(buildSearchQuery(owner, repo, opts)),
+	}
+
+	if err := c.query(ctx, &query, variables); err != nil {
+		return 0, c.mapError(err, owner, repo)
+	}
+
+	return int(query.Search.IssueCount), nil
+}
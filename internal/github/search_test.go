@@ -15,6 +15,12 @@
 package github
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -41,7 +47,7 @@ func TestBuildSearchQuery(t *testing.T) {
 			opts: FetchOptions{
 				Since: timePtr(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)),
 			},
-			expected: "repo:kubernetes/kubernetes is:pr created:>2024-01-15 sort:created-asc",
+			expected: "repo:kubernetes/kubernetes is:pr created:>=2024-01-15T00:00:00Z sort:created-asc",
 		},
 		{
 			name:  "query with until date",
@@ -50,7 +56,7 @@ func TestBuildSearchQuery(t *testing.T) {
 			opts: FetchOptions{
 				Until: timePtr(time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)),
 			},
-			expected: "repo:kubernetes/kubernetes is:pr created:<2024-06-30 sort:created-asc",
+			expected: "repo:kubernetes/kubernetes is:pr created:<2024-06-30T00:00:00Z sort:created-asc",
 		},
 		{
 			name:  "query with date range",
@@ -58,9 +64,84 @@ func TestBuildSearchQuery(t *testing.T) {
 			repo:  "kubernetes",
 			opts: FetchOptions{
 				Since: timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
-				Until: timePtr(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)),
+				Until: timePtr(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
 			},
-			expected: "repo:kubernetes/kubernetes is:pr created:2024-01-01..2024-12-31 sort:created-asc",
+			expected: "repo:kubernetes/kubernetes is:pr created:2024-01-01T00:00:00Z..2024-12-31T23:59:59Z sort:created-asc",
+		},
+		{
+			name:  "query with sub-second bounds",
+			owner: "kubernetes",
+			repo:  "kubernetes",
+			opts: FetchOptions{
+				Since: timePtr(time.Date(2024, 1, 1, 9, 30, 0, 500, time.UTC)),
+				Until: timePtr(time.Date(2024, 1, 1, 17, 0, 0, 500, time.UTC)),
+			},
+			expected: "repo:kubernetes/kubernetes is:pr created:2024-01-01T09:30:00Z..2024-01-01T17:00:00Z sort:created-asc",
+		},
+		{
+			name:  "query with non-UTC bounds",
+			owner: "kubernetes",
+			repo:  "kubernetes",
+			opts: FetchOptions{
+				Until: timePtr(time.Date(2024, 6, 30, 0, 0, 0, 0, time.FixedZone("CEST", 2*60*60))),
+			},
+			expected: "repo:kubernetes/kubernetes is:pr created:<2024-06-29T22:00:00Z sort:created-asc",
+		},
+		{
+			name:  "query with updated watermark",
+			owner: "kubernetes",
+			repo:  "kubernetes",
+			opts: FetchOptions{
+				UpdatedSince: timePtr(time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)),
+			},
+			expected: "repo:kubernetes/kubernetes is:pr updated:>=2024-03-01T08:30:00Z sort:updated-asc",
+		},
+		{
+			name:  "query with created range",
+			owner: "kubernetes",
+			repo:  "kubernetes",
+			opts: FetchOptions{
+				Since: timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+				DateRange: &TimeRange{
+					Start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC),
+				},
+			},
+			expected: "repo:kubernetes/kubernetes is:pr created:2024-03-01T00:00:00Z..2024-03-08T11:59:59Z sort:created-asc",
+		},
+		{
+			name:  "query with merged date window",
+			owner: "kubernetes",
+			repo:  "kubernetes",
+			opts: FetchOptions{
+				Since:     timePtr(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+				Until:     timePtr(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)),
+				DateField: DateFieldMerged,
+			},
+			expected: "repo:kubernetes/kubernetes is:pr merged:2024-01-01T00:00:00Z..2024-03-31T23:59:59Z sort:created-asc",
+		},
+		{
+			name:  "query with updated date slice",
+			owner: "kubernetes",
+			repo:  "kubernetes",
+			opts: FetchOptions{
+				DateField: DateFieldUpdated,
+				DateRange: &TimeRange{
+					Start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			expected: "repo:kubernetes/kubernetes is:pr updated:2024-03-01T00:00:00Z..2024-03-01T23:59:59Z sort:updated-asc",
+		},
+		{
+			name:  "query with extra qualifiers",
+			owner: "kubernetes",
+			repo:  "kubernetes",
+			opts: FetchOptions{
+				Since:      timePtr(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)),
+				Qualifiers: `is:merged base:main label:"kind/bug"`,
+			},
+			expected: `repo:kubernetes/kubernetes is:pr created:>=2024-01-15T00:00:00Z is:merged base:main label:"kind/bug" sort:created-asc`,
 		},
 		{
 			name:  "custom query overrides everything",
@@ -91,7 +172,143 @@ func TestBuildSearchQuery(t *testing.T) {
 	}
 }
 
+func TestFetchPullRequestsSearch_ExcludeBots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"search":{"issueCount":3,"pageInfo":{"hasNextPage":false,"endCursor":"c1"},"nodes":[`+
+			`{"number":1,"author":{"login":"alice"},"createdAt":"2024-01-01T00:00:00Z","updatedAt":"2024-01-01T00:00:00Z"},`+
+			`{"number":2,"author":{"login":"dependabot[bot]"},"createdAt":"2024-01-02T00:00:00Z","updatedAt":"2024-01-02T00:00:00Z"},`+
+			`{"number":3,"author":{"login":"bob"},"createdAt":"2024-01-03T00:00:00Z","updatedAt":"2024-01-03T00:00:00Z"}]}}}`)
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	page, err := client.FetchPullRequestsSearch(context.Background(), "org", "repo", FetchOptions{ExcludeBots: true})
+	if err != nil {
+		t.Fatalf("FetchPullRequestsSearch failed: %v", err)
+	}
+
+	var numbers []int
+	for _, pr := range page.PullRequests {
+		numbers = append(numbers, pr.Number)
+	}
+	if len(numbers) != 2 || numbers[0] != 1 || numbers[1] != 3 {
+		t.Errorf("PR numbers = %v, want [1 3]", numbers)
+	}
+	if page.Excluded != 1 || page.TotalCount != 3 {
+		t.Errorf("Excluded = %d, TotalCount = %d, want 1 and 3", page.Excluded, page.TotalCount)
+	}
+}
+
+func TestFetchPullRequestsSearch_DropsPRsOutsideWindow(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"search":{"issueCount":3,"pageInfo":{"hasNextPage":false,"endCursor":"c1"},"nodes":[`+
+			`{"number":1,"author":{"login":"alice"},"createdAt":"2024-01-01T09:30:00.2Z","updatedAt":"2024-01-01T09:30:00.2Z",`+
+			`"files":{"totalCount":150,"pageInfo":{"hasNextPage":true,"endCursor":"f1"},"nodes":[{"path":"a.go"}]}},`+
+			`{"number":2,"author":{"login":"bob"},"createdAt":"2024-01-01T12:00:00Z","updatedAt":"2024-01-01T12:00:00Z"},`+
+			`{"number":3,"author":{"login":"carol"},"createdAt":"2024-01-01T17:00:00.7Z","updatedAt":"2024-01-01T17:00:00.7Z"}]}}}`)
+	}))
+	defer server.Close()
+
+	// The qualifier covers whole seconds, so PRs 1 and 3 match the query
+	// but fall just outside the window
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	opts := FetchOptions{
+		Since: timePtr(time.Date(2024, 1, 1, 9, 30, 0, 500*int(time.Millisecond), time.UTC)),
+		Until: timePtr(time.Date(2024, 1, 1, 17, 0, 0, 500*int(time.Millisecond), time.UTC)),
+	}
+	page, err := client.FetchPullRequestsSearch(context.Background(), "org", "repo", opts)
+	if err != nil {
+		t.Fatalf("FetchPullRequestsSearch failed: %v", err)
+	}
+
+	if len(page.PullRequests) != 1 || page.PullRequests[0].Number != 2 {
+		t.Errorf("PullRequests = %v, want only PR #2", page.PullRequests)
+	}
+	if page.Excluded != 2 {
+		t.Errorf("Excluded = %d, want 2", page.Excluded)
+	}
+	if requests != 1 || page.APICalls != 1 {
+		t.Errorf("made %d requests for %d API calls, want only the search query", requests, page.APICalls)
+	}
+}
+
+func TestFetchOptions_InWindow(t *testing.T) {
+	merged := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	pr := &PullRequest{CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), MergedAt: &merged}
+	open := &PullRequest{CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name string
+		pr   *PullRequest
+		opts FetchOptions
+		want bool
+	}{
+		{name: "no window", pr: open, want: true},
+		{name: "since is inclusive", pr: pr, opts: FetchOptions{Since: timePtr(pr.CreatedAt)}, want: true},
+		{name: "until is exclusive", pr: pr, opts: FetchOptions{Until: timePtr(pr.CreatedAt)}, want: false},
+		{name: "window on merged date", pr: pr, opts: FetchOptions{DateField: DateFieldMerged, Since: timePtr(merged)}, want: true},
+		{name: "unmerged PR outside merged window", pr: open, opts: FetchOptions{DateField: DateFieldMerged, Until: timePtr(merged)}, want: false},
+		{
+			name: "range and bounds both apply",
+			pr:   pr,
+			opts: FetchOptions{
+				Since:     timePtr(pr.CreatedAt.Add(time.Hour)),
+				DateRange: &TimeRange{Start: pr.CreatedAt, End: pr.CreatedAt.Add(24 * time.Hour)},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.inWindow(tt.pr); got != tt.want {
+				t.Errorf("inWindow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // timePtr is a helper function to create a pointer to a time.Time
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
+
+func TestCountPullRequests(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		gotQuery, _ = req.Variables["query"].(string)
+		if !strings.Contains(req.Query, "issueCount") {
+			t.Errorf("expected an issueCount query, got %q", req.Query)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"search":{"issueCount":1234}}}`)
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	opts := FetchOptions{DateRange: &TimeRange{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}}
+	count, err := client.CountPullRequests(context.Background(), "org", "repo", opts)
+	if err != nil {
+		t.Fatalf("CountPullRequests failed: %v", err)
+	}
+	if count != 1234 {
+		t.Errorf("expected count 1234, got %d", count)
+	}
+	if want := "repo:org/repo is:pr created:2024-01-01T00:00:00Z..2024-01-01T23:59:59Z sort:created-asc"; gotQuery != want {
+		t.Errorf("search query = %q, want %q", gotQuery, want)
+	}
+}
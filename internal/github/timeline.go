@@ -0,0 +1,139 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"time"
+
+	"github.com/shurcooL/graphql"
+)
+
+// timelineEvent holds the fields shared by the timeline events we record.
+type timelineEvent struct {
+	Actor     *actorNode `graphql:"actor"`
+	CreatedAt time.Time
+}
+
+// labelEvent is a LabeledEvent or UnlabeledEvent.
+type labelEvent struct {
+	Actor     *actorNode `graphql:"actor"`
+	CreatedAt time.Time
+	Label     struct {
+		Name graphql.String
+	}
+}
+
+// timelineItemNode is a single entry of a pull request's timelineItems
+// connection. Only the fragment matching Typename is populated.
+type timelineItemNode struct {
+	Typename graphql.String `graphql:"__typename"`
+
+	IssueComment struct {
+		Author    *actorNode `graphql:"author"`
+		CreatedAt time.Time
+		Body      graphql.String
+	} `graphql:"... on IssueComment"`
+
+	ReviewRequestedEvent struct {
+		Actor             *actorNode `graphql:"actor"`
+		CreatedAt         time.Time
+		RequestedReviewer struct {
+			User struct {
+				Login graphql.String
+			} `graphql:"... on User"`
+			Team struct {
+				Name graphql.String
+			} `graphql:"... on Team"`
+		} `graphql:"requestedReviewer"`
+	} `graphql:"... on ReviewRequestedEvent"`
+
+	LabeledEvent            labelEvent    `graphql:"... on LabeledEvent"`
+	UnlabeledEvent          labelEvent    `graphql:"... on UnlabeledEvent"`
+	ReadyForReviewEvent     timelineEvent `graphql:"... on ReadyForReviewEvent"`
+	HeadRefForcePushedEvent timelineEvent `graphql:"... on HeadRefForcePushedEvent"`
+	ClosedEvent             timelineEvent `graphql:"... on ClosedEvent"`
+	ReopenedEvent           timelineEvent `graphql:"... on ReopenedEvent"`
+	MergedEvent             timelineEvent `graphql:"... on MergedEvent"`
+}
+
+// convertTimelineItem maps a timeline item to a Conversation record.
+// It returns false for item types that are not recorded.
+func convertTimelineItem(item *timelineItemNode) (Conversation, bool) {
+	switch item.Typename {
+	case "IssueComment":
+		return Conversation{
+			Type:      ConversationComment,
+			Username:  actorLogin(item.IssueComment.Author),
+			Timestamp: item.IssueComment.CreatedAt,
+			Body:      string(item.IssueComment.Body),
+		}, true
+	case "ReviewRequestedEvent":
+		event := item.ReviewRequestedEvent
+		reviewer := string(event.RequestedReviewer.User.Login)
+		if reviewer == "" {
+			reviewer = string(event.RequestedReviewer.Team.Name)
+		}
+		return Conversation{
+			Type:      ConversationReviewRequested,
+			Username:  actorLogin(event.Actor),
+			Timestamp: event.CreatedAt,
+			Body:      reviewer,
+		}, true
+	case "LabeledEvent":
+		return labelConversation(ConversationLabeled, &item.LabeledEvent), true
+	case "UnlabeledEvent":
+		return labelConversation(ConversationUnlabeled, &item.UnlabeledEvent), true
+	case "ReadyForReviewEvent":
+		return eventConversation(ConversationReadyForReview, &item.ReadyForReviewEvent), true
+	case "HeadRefForcePushedEvent":
+		return eventConversation(ConversationForcePushed, &item.HeadRefForcePushedEvent), true
+	case "ClosedEvent":
+		return eventConversation(ConversationClosed, &item.ClosedEvent), true
+	case "ReopenedEvent":
+		return eventConversation(ConversationReopened, &item.ReopenedEvent), true
+	case "MergedEvent":
+		return eventConversation(ConversationMerged, &item.MergedEvent), true
+	}
+	return Conversation{}, false
+}
+
+// labelConversation builds a Conversation for a label event. The label name
+// is recorded as the body.
+func labelConversation(eventType string, event *labelEvent) Conversation {
+	return Conversation{
+		Type:      eventType,
+		Username:  actorLogin(event.Actor),
+		Timestamp: event.CreatedAt,
+		Body:      string(event.Label.Name),
+	}
+}
+
+// eventConversation builds a Conversation for an event without a body.
+func eventConversation(eventType string, event *timelineEvent) Conversation {
+	return Conversation{
+		Type:      eventType,
+		Username:  actorLogin(event.Actor),
+		Timestamp: event.CreatedAt,
+	}
+}
+
+// actorLogin returns the login of an actor, or "ghost" for deleted accounts
+// which GitHub reports as a null actor.
+func actorLogin(actor *actorNode) string {
+	if actor == nil {
+		return "ghost"
+	}
+	return string(actor.Login)
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchPullRequestsSearch_Conversations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"search":{
+			"pageInfo":{"hasNextPage":false,"endCursor":""},
+			"nodes":[{"number":1,"timelineItems":{"totalCount":10,"pageInfo":{"hasNextPage":false},"nodes":[
+				{"__typename":"ReviewRequestedEvent","actor":{"login":"alice"},"createdAt":"2024-01-01T10:00:00Z","requestedReviewer":{"login":"bob"}},
+				{"__typename":"ReviewRequestedEvent","actor":{"login":"alice"},"createdAt":"2024-01-01T10:01:00Z","requestedReviewer":{"name":"core-team"}},
+				{"__typename":"IssueComment","author":{"login":"bob"},"createdAt":"2024-01-01T11:00:00Z","body":"Looks good"},
+				{"__typename":"LabeledEvent","actor":{"login":"alice"},"createdAt":"2024-01-01T12:00:00Z","label":{"name":"bug"}},
+				{"__typename":"UnlabeledEvent","actor":null,"createdAt":"2024-01-01T12:30:00Z","label":{"name":"wip"}},
+				{"__typename":"ReadyForReviewEvent","actor":{"login":"alice"},"createdAt":"2024-01-01T13:00:00Z"},
+				{"__typename":"HeadRefForcePushedEvent","actor":{"login":"alice"},"createdAt":"2024-01-01T14:00:00Z"},
+				{"__typename":"ClosedEvent","actor":{"login":"bob"},"createdAt":"2024-01-01T15:00:00Z"},
+				{"__typename":"ReopenedEvent","actor":{"login":"bob"},"createdAt":"2024-01-01T16:00:00Z"},
+				{"__typename":"MergedEvent","actor":{"login":"bob"},"createdAt":"2024-01-01T17:00:00Z"}
+			]}}]}}}`)
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	page, err := client.FetchPullRequestsSearch(context.Background(), "org", "repo", FetchOptions{})
+	if err != nil {
+		t.Fatalf("FetchPullRequestsSearch failed: %v", err)
+	}
+
+	want := []Conversation{
+		{Type: ConversationReviewRequested, Username: "alice", Body: "bob"},
+		{Type: ConversationReviewRequested, Username: "alice", Body: "core-team"},
+		{Type: ConversationComment, Username: "bob", Body: "Looks good"},
+		{Type: ConversationLabeled, Username: "alice", Body: "bug"},
+		{Type: ConversationUnlabeled, Username: "ghost", Body: "wip"},
+		{Type: ConversationReadyForReview, Username: "alice"},
+		{Type: ConversationForcePushed, Username: "alice"},
+		{Type: ConversationClosed, Username: "bob"},
+		{Type: ConversationReopened, Username: "bob"},
+		{Type: ConversationMerged, Username: "bob"},
+	}
+
+	got := page.PullRequests[0].Conversations
+	if len(got) != len(want) {
+		t.Fatalf("expected %d conversations, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i].Type != want[i].Type || got[i].Username != want[i].Username || got[i].Body != want[i].Body {
+			t.Errorf("conversation %d = %+v, want %+v", i, got[i], want[i])
+		}
+		if got[i].Timestamp.IsZero() {
+			t.Errorf("conversation %d has no timestamp", i)
+		}
+	}
+
+	if !got[2].Timestamp.Equal(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected comment timestamp: %v", got[2].Timestamp)
+	}
+}
+
+func TestTimelineItemTypes_PassedAsVariable(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		requests = append(requests, req.Query)
+		if !strings.Contains(req.Query, "$itemTypes:[PullRequestTimelineItemsItemType!]!") || !strings.Contains(req.Query, "itemTypes: $itemTypes") {
+			t.Errorf("expected the query to take itemTypes as a variable, got %q", req.Query)
+		}
+		if got := fmt.Sprint(req.Variables["itemTypes"]); got != fmt.Sprint(timelineItemTypes) {
+			t.Errorf("itemTypes = %s, want %s", got, fmt.Sprint(timelineItemTypes))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			fmt.Fprint(w, `{"data":{"search":{"pageInfo":{"hasNextPage":false,"endCursor":""},"nodes":[{"number":1,"timelineItems":{
+				"totalCount":2,"pageInfo":{"hasNextPage":true,"endCursor":"t1"},"nodes":[
+				{"__typename":"ClosedEvent","actor":{"login":"bob"},"createdAt":"2024-01-01T15:00:00Z"}]}}]}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"repository":{"pullRequest":{"timelineItems":{
+			"totalCount":2,"pageInfo":{"hasNextPage":false,"endCursor":"t2"},"nodes":[
+			{"__typename":"ReopenedEvent","actor":{"login":"bob"},"createdAt":"2024-01-01T16:00:00Z"}]}}}}}`)
+	}))
+	defer server.Close()
+
+	client := NewGraphQLClient("test-token", WithEndpoint(server.URL))
+	page, err := client.FetchPullRequestsSearch(context.Background(), "org", "repo", FetchOptions{})
+	if err != nil {
+		t.Fatalf("FetchPullRequestsSearch failed: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Errorf("expected the search and one timeline follow-up query, got %d requests", len(requests))
+	}
+	if got := len(page.PullRequests[0].Conversations); got != 2 {
+		t.Errorf("expected 2 conversations, got %d", got)
+	}
+}
+
+func TestConvertTimelineItem_UnknownType(t *testing.T) {
+	if _, ok := convertTimelineItem(&timelineItemNode{Typename: "SubscribedEvent"}); ok {
+		t.Error("expected unknown timeline item to be skipped")
+	}
+}
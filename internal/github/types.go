@@ -15,7 +15,10 @@
 // Package github provides types and interfaces for interacting with the GitHub API.
 package github
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // PullRequest represents a GitHub pull request with comprehensive metadata.
 // This is the core data structure that gets serialized to NDJSON output.
@@ -65,8 +68,22 @@ type PullRequest struct {
 	Reviews       []Review       `json:"reviews,omitempty"`
 	CommitList    []Commit       `json:"commit_list,omitempty"`
 	Conversations []Conversation `json:"conversations,omitempty"`
+	ReviewThreads []ReviewThread `json:"review_threads,omitempty"`
+
+	// ChangeType marks records emitted by incremental fetches as ChangeNew
+	// or ChangeUpdated. It is empty for full fetches.
+	ChangeType string `json:"change_type,omitempty"`
 }
 
+// Change types recorded on pull requests emitted by incremental fetches.
+const (
+	// ChangeNew marks a PR that was not part of any previous fetch.
+	ChangeNew = "new"
+
+	// ChangeUpdated marks a previously fetched PR that changed since.
+	ChangeUpdated = "updated"
+)
+
 // User represents a GitHub user account.
 // This can be a regular user, bot, or organization.
 type User struct {
@@ -106,6 +123,25 @@ type Review struct {
 	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
 }
 
+// ReviewThread represents a thread of inline review comments on a line of
+// a changed file. Line is nil when the thread no longer maps onto the
+// current diff, which GitHub also reports through IsOutdated.
+type ReviewThread struct {
+	Path       string          `json:"path"`
+	Line       *int            `json:"line,omitempty"`
+	IsResolved bool            `json:"is_resolved"`
+	IsOutdated bool            `json:"is_outdated"`
+	ResolvedBy *User           `json:"resolved_by,omitempty"`
+	Comments   []ReviewComment `json:"comments,omitempty"`
+}
+
+// ReviewComment represents a single inline comment within a review thread.
+type ReviewComment struct {
+	Author    User      `json:"author"`
+	Body      string    `json:"body,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // Commit represents a git commit in a pull request.
 type Commit struct {
 	SHA          string    `json:"sha"`
@@ -121,14 +157,31 @@ type Commit struct {
 }
 
 // Conversation represents a timeline event on a pull request.
-// This includes comments, reviews, status updates, and other events.
+// This includes comments, review requests, label changes, force-pushes and
+// state transitions, in the order they happened. Username is the actor that
+// caused the event ("ghost" for deleted accounts). Body holds the comment
+// text for comments, the label name for label events and the requested
+// reviewer (user login or team name) for review requests.
 type Conversation struct {
-	Type      string    `json:"type"` // comment, review, event
+	Type      string    `json:"type"` // One of the Conversation* constants
 	Username  string    `json:"username"`
 	Timestamp time.Time `json:"timestamp"`
 	Body      string    `json:"body,omitempty"`
 }
 
+// Conversation types recorded from the pull request timeline.
+const (
+	ConversationComment         = "comment"
+	ConversationReviewRequested = "review_requested"
+	ConversationLabeled         = "labeled"
+	ConversationUnlabeled       = "unlabeled"
+	ConversationReadyForReview  = "ready_for_review"
+	ConversationForcePushed     = "force_pushed"
+	ConversationClosed          = "closed"
+	ConversationReopened        = "reopened"
+	ConversationMerged          = "merged"
+)
+
 // PullRequestPage represents a page of pull requests from a GraphQL query.
 // It includes the pull requests for the current page and pagination information
 // to support fetching subsequent pages. This enables efficient streaming
@@ -137,6 +190,35 @@ type PullRequestPage struct {
 	PullRequests []PullRequest
 	HasNextPage  bool
 	EndCursor    string
+
+	// TotalCount is the number of PRs matching the search query, as reported
+	// by GitHub's issueCount. Search returns at most SearchResultCap of them
+	// across all pages. Zero means it was not reported.
+	TotalCount int
+
+	// APICalls is the number of API requests made to build this page: the
+	// page query itself plus any follow-up queries that drained nested
+	// connections (files, commits, reviews, labels, assignees, timeline, review
+	// threads and their comments) whose
+	// totalCount exceeded the first page. Zero means it was not tracked.
+	APICalls int
+
+	// RateLimit is the client's rate limit usage after the page was fetched,
+	// or nil when the client does not track it.
+	RateLimit *RateLimitStats
+
+	// Retries is the number of transient failures the client has retried so
+	// far, including those before this page.
+	Retries int
+
+	// Excluded is the number of PRs matching the search that were left out
+	// of PullRequests because FetchOptions.ExcludeBots was set or their date
+	// fell just outside the window. They are still part of TotalCount.
+	Excluded int
+
+	// Missing lists the requested numbers the repository has no pull request
+	// for, when PRs are fetched by number.
+	Missing []int
 }
 
 // FetchOptions configures how pull requests are fetched.
@@ -153,20 +235,108 @@ type FetchOptions struct {
 	// Use PullRequestPage.EndCursor from previous response for next page.
 	After string
 
-	// Since filters PRs created after this date (inclusive).
-	// When nil, no lower bound is applied.
+	// Since selects PRs created, or with the date named by DateField, at or
+	// after this instant (inclusive). When nil, no lower bound is applied.
+	// Search qualifiers have second precision, so PRs in the second before a
+	// fractional Since are dropped from the page and counted in its Excluded.
 	Since *time.Time
 
-	// Until filters PRs created before this date (inclusive).
-	// When nil, no upper bound is applied.
+	// Until selects PRs created, or with the date named by DateField, before
+	// this instant (exclusive). When nil, no upper bound is applied. To cover
+	// a whole day, pass the start of the following day.
 	Until *time.Time
 
+	// DateRange restricts results to PRs whose DateField falls within the
+	// range, with second precision. It takes precedence over Since and Until
+	// and is used to slice large windows below the search result cap.
+	DateRange *TimeRange
+
+	// DateField is the date Since, Until and DateRange apply to: created,
+	// updated, merged or closed. Empty means created.
+	DateField string
+
+	// Qualifiers are extra search qualifiers, such as "is:merged base:main",
+	// added to the query built from the other options.
+	Qualifiers string
+
+	// ExcludeBots leaves out PRs opened by bots. Search has no qualifier for
+	// this, so they are dropped from each page and counted in its Excluded.
+	ExcludeBots bool
+
+	// UpdatedSince filters PRs updated at or after this time (inclusive) and
+	// orders results by update time instead of creation time. Incremental
+	// fetches use it to pick up changes to existing PRs.
+	// When nil, no update filter is applied.
+	UpdatedSince *time.Time
+
 	// Query is the raw GitHub search query to use.
 	// If provided, it overrides the default query construction.
 	// This allows for advanced filtering beyond date ranges.
 	Query string
 }
 
+// Date fields a search window can be defined on.
+const (
+	DateFieldCreated = "created"
+	DateFieldUpdated = "updated"
+	DateFieldMerged  = "merged"
+	DateFieldClosed  = "closed"
+)
+
+// DateOf returns the date named by field for pr, or nil when pr has none,
+// such as the merge date of an unmerged PR. An empty field means created.
+func DateOf(pr *PullRequest, field string) *time.Time {
+	switch field {
+	case DateFieldUpdated:
+		return &pr.UpdatedAt
+	case DateFieldMerged:
+		return pr.MergedAt
+	case DateFieldClosed:
+		return pr.ClosedAt
+	default:
+		return &pr.CreatedAt
+	}
+}
+
+// inWindow reports whether pr lies within the window selected by opts: its
+// DateField date within DateRange and within [Since, Until). A PR without
+// the date, such as an open PR in a window on the merged date, lies outside
+// any window on that date. UpdatedSince is not checked; the incremental
+// watermark already leans early, so PRs just before it are harmless.
+func (o FetchOptions) inWindow(pr *PullRequest) bool {
+	if o.DateRange == nil && o.Since == nil && o.Until == nil {
+		return true
+	}
+
+	date := DateOf(pr, o.DateField)
+	switch {
+	case date == nil:
+		return false
+	case o.DateRange != nil && !o.DateRange.Contains(*date):
+		return false
+	case o.Since != nil && date.Before(*o.Since):
+		return false
+	case o.Until != nil && !date.Before(*o.Until):
+		return false
+	}
+	return true
+}
+
+// TimeRange is a half-open time range: Start is included, End is not.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t lies within the range.
+func (r TimeRange) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// SearchResultCap is the maximum number of results GitHub's search API
+// returns for a single query, however many pages are requested.
+const SearchResultCap = 1000
+
 // Default values for fetch operations
 const (
 	defaultPageSize    = 50
@@ -181,3 +351,63 @@ const (
 type RepositoryInfo struct {
 	TotalPullRequests int
 }
+
+// Repository identifies a repository returned by GetOrganizationRepositories,
+// together with the attributes a RepositoryFilter selects on.
+type Repository struct {
+	Owner      string
+	Name       string
+	IsArchived bool
+	IsFork     bool
+	Visibility string // PUBLIC, PRIVATE or INTERNAL
+	Topics     []string
+	PushedAt   *time.Time
+}
+
+// FullName returns the repository in owner/name form.
+func (r Repository) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// RepositoryFilter selects the repositories returned by
+// GetOrganizationRepositories. The zero value selects every repository.
+type RepositoryFilter struct {
+	// ExcludeArchived and ExcludeForks leave out archived and forked
+	// repositories.
+	ExcludeArchived bool
+	ExcludeForks    bool
+
+	// Visibility keeps only repositories with this visibility: public,
+	// private or internal. Empty keeps all.
+	Visibility string
+
+	// Topics keeps only repositories tagged with at least one of them.
+	Topics []string
+
+	// PushedSince keeps only repositories pushed to at or after this time.
+	PushedSince *time.Time
+}
+
+// Matches reports whether r is selected by the filter.
+func (f RepositoryFilter) Matches(r Repository) bool {
+	if (f.ExcludeArchived && r.IsArchived) || (f.ExcludeForks && r.IsFork) {
+		return false
+	}
+	if f.Visibility != "" && !strings.EqualFold(f.Visibility, r.Visibility) {
+		return false
+	}
+	if f.PushedSince != nil && (r.PushedAt == nil || r.PushedAt.Before(*f.PushedSince)) {
+		return false
+	}
+	if len(f.Topics) == 0 {
+		return true
+	}
+	for _, want := range f.Topics {
+		for _, topic := range r.Topics {
+			if strings.EqualFold(want, topic) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -113,3 +113,38 @@ func TestFetchOptionsDefaults(t *testing.T) {
 		t.Errorf("defaultPageSize = %d, want 50", defaultPageSize)
 	}
 }
+
+func TestRepositoryFilter_Matches(t *testing.T) {
+	pushedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := pushedAt.Add(-time.Hour)
+	after := pushedAt.Add(time.Hour)
+	repo := Repository{Owner: "acme", Name: "api", Visibility: "PUBLIC", Topics: []string{"backend", "go"}, PushedAt: &pushedAt}
+	archivedFork := Repository{Owner: "acme", Name: "old", IsArchived: true, IsFork: true, Visibility: "PRIVATE"}
+
+	tests := []struct {
+		name   string
+		filter RepositoryFilter
+		repo   Repository
+		want   bool
+	}{
+		{"zero filter keeps archived forks", RepositoryFilter{}, archivedFork, true},
+		{"exclude archived", RepositoryFilter{ExcludeArchived: true}, archivedFork, false},
+		{"exclude forks", RepositoryFilter{ExcludeForks: true}, archivedFork, false},
+		{"exclusions keep other repositories", RepositoryFilter{ExcludeArchived: true, ExcludeForks: true}, repo, true},
+		{"visibility matches case-insensitively", RepositoryFilter{Visibility: "public"}, repo, true},
+		{"visibility differs", RepositoryFilter{Visibility: "private"}, repo, false},
+		{"any topic matches", RepositoryFilter{Topics: []string{"frontend", "Go"}}, repo, true},
+		{"no topic matches", RepositoryFilter{Topics: []string{"frontend"}}, repo, false},
+		{"pushed since earlier time", RepositoryFilter{PushedSince: &before}, repo, true},
+		{"pushed since later time", RepositoryFilter{PushedSince: &after}, repo, false},
+		{"pushed since without push", RepositoryFilter{PushedSince: &before}, archivedFork, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.repo); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -49,6 +50,10 @@ type Tracker struct {
 	startTime    time.Time
 	apiCallCount int
 	prStats      PRStats
+	newPRs       int
+	updatedPRs   int
+	rateLimit    *RateLimitUsage
+	retries      int
 }
 
 // PRStats holds statistical information about pull requests processed during
@@ -76,6 +81,15 @@ func (t *Tracker) IncrementAPICall() {
 	t.apiCallCount++
 }
 
+// AddAPICalls records n API calls at once. Use this when a single logical
+// operation, such as fetching a page that needed follow-up queries for
+// nested data, issued several requests. Non-positive values are ignored.
+func (t *Tracker) AddAPICalls(n int) {
+	if n > 0 {
+		t.apiCallCount += n
+	}
+}
+
 // UpdatePRStats updates the running statistics with data from a single pull request.
 // It adjusts the first/last PR numbers and oldest/newest dates as needed.
 // This method is safe to call concurrently from multiple goroutines.
@@ -99,6 +113,30 @@ func (t *Tracker) UpdatePRStats(prNumber int, createdAt, updatedAt time.Time) {
 	}
 }
 
+// RecordChange records whether a PR emitted by an incremental fetch was new
+// or an update to a previously fetched PR. Call it alongside UpdatePRStats.
+func (t *Tracker) RecordChange(isNew bool) {
+	if isNew {
+		t.newPRs++
+	} else {
+		t.updatedPRs++
+	}
+}
+
+// RecordRateLimit records the rate limit usage reported by the client. Usage
+// is cumulative for the fetch, so each call replaces the previous one.
+func (t *Tracker) RecordRateLimit(usage RateLimitUsage) {
+	t.rateLimit = &usage
+}
+
+// RecordRetries adds n requests retried after a transient failure to the
+// fetch's count.
+func (t *Tracker) RecordRetries(n int) {
+	if n > 0 {
+		t.retries += n
+	}
+}
+
 // GenerateMetadata creates a FetchMetadata instance capturing the complete
 // fetch operation statistics. Call this at the end of a successful fetch
 // to create the metadata record.
@@ -132,6 +170,10 @@ func (t *Tracker) GenerateMetadata(relayVersion string, params FetchParams, incr
 			APICallCount: t.apiCallCount,
 			StartedAt:    t.startTime,
 			CompletedAt:  completedAt,
+			NewPRs:       t.newPRs,
+			UpdatedPRs:   t.updatedPRs,
+			RateLimit:    t.rateLimit,
+			Retries:      t.retries,
 		},
 		Incremental:   incremental,
 		PreviousFetch: previousFetch,
@@ -140,9 +182,10 @@ func (t *Tracker) GenerateMetadata(relayVersion string, params FetchParams, incr
 
 // SaveMetadata persists a FetchMetadata record to a JSON file in the specified
 // directory. The file is written atomically using a temporary file and rename
-// to prevent corruption. The filename includes a timestamp for easy sorting.
+// to prevent corruption. The filename includes the repository, so fetches of
+// different repositories never share a file, and a timestamp for easy sorting.
 //
-// The metadata file will be named: fetch-metadata-{timestamp}.json
+// The metadata file will be named: fetch-metadata-{org}-{repo}-{timestamp}.json
 //
 // Returns an error if the save operation fails.
 func SaveMetadata(metadata *FetchMetadata, stateDir string) error {
@@ -151,16 +194,17 @@ func SaveMetadata(metadata *FetchMetadata, stateDir string) error {
 		return fmt.Errorf("failed to create state directory: %w", err)
 	}
 
-	// Generate filename with timestamp
-	filename := fmt.Sprintf("fetch-metadata-%d.json", metadata.Results.StartedAt.Unix())
+	// Generate filename with repository and timestamp
+	filename := fmt.Sprintf("%s%d.json", metadataFilePrefix(metadata.Parameters), metadata.Results.StartedAt.Unix())
 	filepath := filepath.Join(stateDir, filename)
 
-	// Write to temporary file first for atomicity
-	tmpFile := filepath + ".tmp"
-	file, err := os.Create(tmpFile)
+	// Write to a uniquely named temporary file first for atomicity, so
+	// concurrent saves never share one
+	file, err := os.CreateTemp(stateDir, filename+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create metadata file: %w", err)
 	}
+	tmpFile := file.Name()
 
 	// Write JSON with proper formatting
 	encoder := json.NewEncoder(file)
@@ -184,43 +228,81 @@ func SaveMetadata(metadata *FetchMetadata, stateDir string) error {
 	return nil
 }
 
+// metadataFilePrefix returns the start of the metadata file names of the
+// repository in params, up to the timestamp. Metadata without a repository
+// keeps the plain fetch-metadata- prefix.
+func metadataFilePrefix(params FetchParams) string {
+	if params.Organization == "" || params.Repository == "" {
+		return "fetch-metadata-"
+	}
+	return fmt.Sprintf("fetch-metadata-%s-%s-", params.Organization, params.Repository)
+}
+
 // LoadLatestMetadata finds and loads the most recent metadata file for the
-// specified repository from the state directory. It identifies the latest
-// file by modification time and verifies it matches the requested repository.
+// specified repository from the state directory. Only files named for the
+// repository, and those of older releases that carry no repository in their
+// name, are considered; each is verified to belong to the repository before
+// the latest by modification time is chosen.
 //
 // Returns nil if no metadata exists for the repository, or an error if
 // loading fails.
 func LoadLatestMetadata(stateDir, repo string) (*FetchMetadata, error) {
+	org, name, _ := strings.Cut(repo, "/")
+	prefix := metadataFilePrefix(FetchParams{Organization: org, Repository: name})
+
 	pattern := filepath.Join(stateDir, "fetch-metadata-*.json")
 	files, err := filepath.Glob(pattern)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list metadata files: %w", err)
 	}
 
-	if len(files) == 0 {
-		return nil, nil // No previous metadata
-	}
-
-	// Find the most recent file
-	var latestFile string
+	// Find the most recent file of the repository
+	var latest *FetchMetadata
 	var latestTime time.Time
 	for _, file := range files {
+		base := filepath.Base(file)
+		if !isTimestamp(strings.TrimSuffix(strings.TrimPrefix(base, prefix), ".json")) &&
+			!isTimestamp(strings.TrimSuffix(strings.TrimPrefix(base, "fetch-metadata-"), ".json")) {
+			continue
+		}
 		info, statErr := os.Stat(file)
-		if statErr != nil {
+		if statErr != nil || !info.ModTime().After(latestTime) {
 			continue
 		}
-		if info.ModTime().After(latestTime) {
-			latestTime = info.ModTime()
-			latestFile = file
+
+		metadata, err := readMetadataFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		// Verify it's for the same repository; names of other
+		// repositories can share the prefix
+		fullRepo := fmt.Sprintf("%s/%s", metadata.Parameters.Organization, metadata.Parameters.Repository)
+		if fullRepo != repo {
+			continue
 		}
+		latest, latestTime = metadata, info.ModTime()
 	}
 
-	if latestFile == "" {
-		return nil, nil
+	return latest, nil
+}
+
+// isTimestamp reports whether s is a non-empty run of digits.
+func isTimestamp(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
 	}
+	return true
+}
 
-	// Read and parse the metadata
-	file, err := os.Open(latestFile)
+// readMetadataFile reads and parses one metadata file.
+func readMetadataFile(path string) (*FetchMetadata, error) {
+	file, err := os.Open(path) // #nosec G304 -- path is listed from the state directory
 	if err != nil {
 		return nil, fmt.Errorf("failed to open metadata file: %w", err)
 	}
@@ -230,13 +312,6 @@ func LoadLatestMetadata(stateDir, repo string) (*FetchMetadata, error) {
 	if err := json.NewDecoder(file).Decode(&metadata); err != nil {
 		return nil, fmt.Errorf("failed to parse metadata: %w", err)
 	}
-
-	// Verify it's for the same repository
-	fullRepo := fmt.Sprintf("%s/%s", metadata.Parameters.Organization, metadata.Parameters.Repository)
-	if fullRepo != repo {
-		return nil, nil // Metadata is for different repo
-	}
-
 	return &metadata, nil
 }
 
@@ -159,6 +159,58 @@ func TestTracker_GenerateMetadata(t *testing.T) {
 	}
 }
 
+func TestTracker_AddAPICalls(t *testing.T) {
+	tracker := New()
+	tracker.IncrementAPICall()
+	tracker.AddAPICalls(3)
+	tracker.AddAPICalls(0)
+	tracker.AddAPICalls(-2)
+
+	metadata := tracker.GenerateMetadata("v1.0.0", FetchParams{}, false, nil)
+	if metadata.Results.APICallCount != 4 {
+		t.Errorf("APICallCount = %d, want 4", metadata.Results.APICallCount)
+	}
+}
+
+func TestTracker_RecordChange(t *testing.T) {
+	tracker := New()
+	tracker.RecordChange(true)
+	tracker.RecordChange(false)
+	tracker.RecordChange(false)
+
+	metadata := tracker.GenerateMetadata("v1.0.0", FetchParams{}, true, nil)
+	if metadata.Results.NewPRs != 1 || metadata.Results.UpdatedPRs != 2 {
+		t.Errorf("NewPRs = %d, UpdatedPRs = %d, want 1 and 2", metadata.Results.NewPRs, metadata.Results.UpdatedPRs)
+	}
+}
+
+func TestTracker_RecordRateLimit(t *testing.T) {
+	tracker := New()
+	if metadata := tracker.GenerateMetadata("v1.0.0", FetchParams{}, false, nil); metadata.Results.RateLimit != nil {
+		t.Errorf("expected no rate limit usage before any was recorded, got %+v", metadata.Results.RateLimit)
+	}
+
+	tracker.RecordRateLimit(RateLimitUsage{PointsUsed: 10, Limit: 5000, Remaining: 4990})
+	tracker.RecordRateLimit(RateLimitUsage{PointsUsed: 25, Limit: 5000, Remaining: 4975})
+
+	metadata := tracker.GenerateMetadata("v1.0.0", FetchParams{}, false, nil)
+	if got := metadata.Results.RateLimit; got == nil || got.PointsUsed != 25 || got.Remaining != 4975 {
+		t.Errorf("expected the latest usage to be recorded, got %+v", got)
+	}
+}
+
+func TestTracker_RecordRetries(t *testing.T) {
+	tracker := New()
+	tracker.RecordRetries(2)
+	tracker.RecordRetries(3)
+	tracker.RecordRetries(0) // a page fetched without retries
+
+	metadata := tracker.GenerateMetadata("v1.0.0", FetchParams{}, false, nil)
+	if metadata.Results.Retries != 5 {
+		t.Errorf("Retries = %d, want 5", metadata.Results.Retries)
+	}
+}
+
 func TestTracker_GenerateMetadata_Incremental(t *testing.T) {
 	tracker := New()
 	tracker.apiCallCount = 2
@@ -220,7 +272,7 @@ func TestSaveMetadata(t *testing.T) {
 	}
 
 	// Verify file was created
-	expectedFile := filepath.Join(tmpDir, "fetch-metadata-1672574400.json")
+	expectedFile := filepath.Join(tmpDir, "fetch-metadata-kubernetes-kubernetes-1672574400.json")
 	if _, err := os.Stat(expectedFile); err != nil {
 		t.Fatalf("metadata file not created: %v", err)
 	}
@@ -331,6 +383,50 @@ func TestLoadLatestMetadata_DifferentRepo(t *testing.T) {
 	}
 }
 
+func TestLoadLatestMetadata_SeveralRepositories(t *testing.T) {
+	tmpDir := t.TempDir()
+	started := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Fetches of several repositories starting in the same second, where
+	// one repository's name extends another's
+	repos := []string{"org/repo", "org/repo-x", "other/repo"}
+	for _, repo := range repos {
+		org, name, _ := strings.Cut(repo, "/")
+		metadata := &FetchMetadata{
+			FetchID:    "full-" + repo,
+			Parameters: FetchParams{Organization: org, Repository: name},
+			Results:    FetchResults{StartedAt: started},
+		}
+		if err := SaveMetadata(metadata, tmpDir); err != nil {
+			t.Fatalf("SaveMetadata failed: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A file of an older release without the repository in its name
+	legacy, err := json.Marshal(&FetchMetadata{FetchID: "legacy", Parameters: FetchParams{Organization: "old", Repository: "repo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "fetch-metadata-1600000000.json"), legacy, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, repo := range append(repos, "old/repo") {
+		loaded, err := LoadLatestMetadata(tmpDir, repo)
+		if err != nil || loaded == nil {
+			t.Fatalf("LoadLatestMetadata(%s) = %v, %v", repo, loaded, err)
+		}
+		want := "full-" + repo
+		if repo == "old/repo" {
+			want = "legacy"
+		}
+		if loaded.FetchID != want {
+			t.Errorf("LoadLatestMetadata(%s).FetchID = %s, want %s", repo, loaded.FetchID, want)
+		}
+	}
+}
+
 func TestWriteMetadataToWriter(t *testing.T) {
 	metadata := &FetchMetadata{
 		RelayVersion:  "v1.2.3",
@@ -33,6 +33,10 @@ type FetchMetadata struct {
 	Results       FetchResults `json:"results"`
 	Incremental   bool         `json:"incremental"`
 	PreviousFetch *FetchRef    `json:"previous_fetch,omitempty"`
+
+	// Interrupted marks a fetch that was stopped before it finished, for
+	// example by SIGTERM, and whose results cover only the PRs written so far.
+	Interrupted bool `json:"interrupted,omitempty"`
 }
 
 // FetchParams captures the input parameters used for a fetch operation.
@@ -46,6 +50,10 @@ type FetchParams struct {
 	Until        *time.Time `json:"until,omitempty"`
 	FetchAll     bool       `json:"fetch_all"`
 	BatchSize    int        `json:"batch_size"`
+	DateField    string     `json:"date_field,omitempty"`
+	Qualifiers   string     `json:"qualifiers,omitempty"`
+	ExcludeBots  bool       `json:"exclude_bots,omitempty"`
+	PRNumbers    []int      `json:"pr_numbers,omitempty"`
 }
 
 // FetchResults contains comprehensive statistics about a completed fetch
@@ -62,6 +70,43 @@ type FetchResults struct {
 	APICallCount int       `json:"api_calls_made"`
 	StartedAt    time.Time `json:"started_at"`
 	CompletedAt  time.Time `json:"completed_at"`
+
+	// NewPRs and UpdatedPRs split TotalPRs for incremental fetches into
+	// PRs not seen before and previously fetched PRs that changed.
+	NewPRs     int `json:"new_prs,omitempty"`
+	UpdatedPRs int `json:"updated_prs,omitempty"`
+
+	// RateLimit records the GitHub API rate limit budget the fetch used.
+	RateLimit *RateLimitUsage `json:"rate_limit,omitempty"`
+
+	// Retries is the number of API requests retried after a transient failure.
+	Retries int `json:"retries,omitempty"`
+}
+
+// RateLimitUsage captures how many GraphQL rate limit points a fetch used
+// and the headroom left when it finished, along with any time spent
+// waiting for the limit to reset.
+type RateLimitUsage struct {
+	PointsUsed int       `json:"points_used"`
+	Limit      int       `json:"limit"`
+	Remaining  int       `json:"remaining"`
+	ResetAt    time.Time `json:"reset_at"`
+	Waits      int       `json:"waits,omitempty"`
+	WaitTime   string    `json:"wait_time,omitempty"`
+
+	// Tokens breaks the usage down by token when requests rotated across a
+	// token pool.
+	Tokens []TokenUsage `json:"tokens,omitempty"`
+}
+
+// TokenUsage records the rate limit points one token of a token pool used.
+// Tokens are identified by their configured name, never by their value.
+type TokenUsage struct {
+	Name       string    `json:"name"`
+	PointsUsed int       `json:"points_used"`
+	Limit      int       `json:"limit"`
+	Remaining  int       `json:"remaining"`
+	ResetAt    time.Time `json:"reset_at"`
 }
 
 // FetchRef provides a lightweight reference to a previous fetch operation,
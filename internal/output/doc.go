@@ -21,6 +21,10 @@
 // to an io.Writer or file. The package is designed to handle large volumes of data
 // efficiently without accumulating records in memory.
 //
+// File writers support three modes (see Mode): overwrite, append, and upsert.
+// UpsertWriter merges records into an existing dataset keyed by PR number,
+// so a single file can stay the canonical dataset across incremental runs.
+//
 // Example usage:
 //
 //	// Write to a file
@@ -0,0 +1,55 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import "fmt"
+
+// Mode selects how a file writer treats records already in the output file.
+type Mode string
+
+const (
+	// ModeOverwrite truncates the file and writes only the new records.
+	ModeOverwrite Mode = "overwrite"
+
+	// ModeAppend keeps existing records and writes new records after them.
+	ModeAppend Mode = "append"
+
+	// ModeUpsert merges new records into the file keyed by PR number,
+	// keeping the version with the newest updated_at.
+	ModeUpsert Mode = "upsert"
+)
+
+// ParseMode converts a mode name to a Mode. An empty name selects ModeOverwrite.
+func ParseMode(name string) (Mode, error) {
+	switch Mode(name) {
+	case "", ModeOverwrite:
+		return ModeOverwrite, nil
+	case ModeAppend, ModeUpsert:
+		return Mode(name), nil
+	}
+	return "", fmt.Errorf("invalid output mode %q: must be one of overwrite, append, upsert", name)
+}
+
+// NewFileWriterWithMode creates a file writer for filename using mode.
+func NewFileWriterWithMode(filename string, mode Mode) (OutputWriter, error) {
+	switch mode {
+	case ModeAppend:
+		return NewAppendFileWriter(filename)
+	case ModeUpsert:
+		return NewUpsertFileWriter(filename)
+	default:
+		return NewFileWriter(filename)
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UpsertWriter writes NDJSON pull request records into an existing dataset
+// file, replacing earlier versions of the same PR. Records are staged in a
+// temporary file next to the target; Close merges them into the target
+// keyed by the "number" field, keeping the record with the newest
+// "updated_at". Existing records keep their position, updated records take
+// the place of the version they replace and new records are appended.
+//
+// The merge streams both files line by line. Memory use is proportional to
+// the number of distinct PRs, not to the size of their records.
+type UpsertWriter struct {
+	*Writer
+	target  string
+	staging string
+	closed  bool
+}
+
+// NewUpsertFileWriter creates a writer that upserts records into filename
+// when closed. The file does not need to exist yet.
+//
+// The caller must call Close() to merge the staged records; until then the
+// target file is left untouched.
+func NewUpsertFileWriter(filename string) (*UpsertWriter, error) {
+	staging, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".upsert-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging file: %w", err)
+	}
+
+	return &UpsertWriter{
+		Writer:  newBufferedFileWriter(staging),
+		target:  filename,
+		staging: staging.Name(),
+	}, nil
+}
+
+// Close flushes the staged records and merges them into the target file.
+// The target is replaced atomically, so it is never left half written.
+// After Close, the UpsertWriter should not be used.
+func (u *UpsertWriter) Close() error {
+	if u.closed {
+		return nil
+	}
+	u.closed = true
+	defer os.Remove(u.staging)
+
+	if err := u.Writer.Close(); err != nil {
+		return err
+	}
+
+	if err := CompactFiles(u.target, u.target, u.staging); err != nil {
+		return fmt.Errorf("failed to upsert records into %s: %w", u.target, err)
+	}
+	return nil
+}
+
+// recordKey holds the fields used to identify and order PR records.
+type recordKey struct {
+	Number    *int      `json:"number"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// recordRef locates the winning version of a PR record.
+type recordRef struct {
+	updatedAt time.Time
+	source    int
+	offset    int64
+	placed    bool
+}
+
+// CompactFiles merges the NDJSON records of sources into target, keeping one
+// record per PR number: the one with the newest updated_at, preferring the
+// later source (and the later line) on ties. Records keep the position of
+// the first version of their PR; lines without a number are copied as is.
+// Sources that do not exist are skipped, so target may be listed as a
+// source before it has been created.
+//
+// The result is written to a temporary file and renamed over target.
+func CompactFiles(target string, sources ...string) error {
+	files := make([]*os.File, len(sources))
+	defer func() {
+		for _, f := range files {
+			if f != nil {
+				_ = f.Close()
+			}
+		}
+	}()
+
+	// First pass: find the winning version of every PR
+	index := make(map[int]*recordRef)
+	for i, path := range sources {
+		f, err := os.Open(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		files[i] = f
+
+		err = scanRecords(f, path, func(offset int64, _ []byte, key recordKey) error {
+			if key.Number == nil {
+				return nil
+			}
+			ref := index[*key.Number]
+			if ref == nil || !key.UpdatedAt.Before(ref.updatedAt) {
+				index[*key.Number] = &recordRef{updatedAt: key.UpdatedAt, source: i, offset: offset}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".compact-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// Second pass: write each PR once, at the position of its first version
+	out := bufio.NewWriterSize(tmp, 64*1024)
+	for i, f := range files {
+		if f == nil {
+			continue
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("failed to rewind %s: %w", sources[i], err)
+		}
+
+		err := scanRecords(f, sources[i], func(offset int64, line []byte, key recordKey) error {
+			if key.Number == nil {
+				return writeLine(out, line)
+			}
+			ref := index[*key.Number]
+			if ref.placed {
+				return nil
+			}
+			ref.placed = true
+			if ref.source == i && ref.offset == offset {
+				return writeLine(out, line)
+			}
+			winner, err := readLineAt(files[ref.source], ref.offset)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", sources[ref.source], err)
+			}
+			return writeLine(out, winner)
+		})
+		if err != nil {
+			_ = tmp.Close()
+			return err
+		}
+	}
+
+	if err := out.Flush(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write compacted records: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync compacted records: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close compacted records: %w", err)
+	}
+
+	// Keep the permissions of the file being replaced
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(target); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", target, err)
+	}
+	return nil
+}
+
+// scanRecords calls fn for every non-empty line of r with the line's offset,
+// its content without the trailing newline, and its parsed key fields.
+func scanRecords(r io.Reader, path string, fn func(offset int64, line []byte, key recordKey) error) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var offset int64
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		start := offset
+		offset += int64(len(line))
+
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			var key recordKey
+			if jsonErr := json.Unmarshal(trimmed, &key); jsonErr != nil {
+				return fmt.Errorf("line %d of %s is not a valid JSON record: %w", lineNum, path, jsonErr)
+			}
+			if fnErr := fn(start, trimmed, key); fnErr != nil {
+				return fnErr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// readLineAt reads the line starting at offset in f.
+func readLineAt(f *os.File, offset int64) ([]byte, error) {
+	reader := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return bytes.TrimSpace(line), nil
+}
+
+// writeLine writes line followed by a newline.
+func writeLine(w *bufio.Writer, line []byte) error {
+	if _, err := w.Write(line); err != nil {
+		return fmt.Errorf("failed to write compacted records: %w", err)
+	}
+	if err := w.WriteByte('\n'); err != nil {
+		return fmt.Errorf("failed to write compacted records: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpsertFileWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // empty means the file does not exist
+		records  []map[string]interface{}
+		want     []string
+	}{
+		{
+			name: "creates missing file",
+			records: []map[string]interface{}{
+				{"number": 1, "updated_at": "2024-01-01T00:00:00Z"},
+			},
+			want: []string{`{"number":1,"updated_at":"2024-01-01T00:00:00Z"}`},
+		},
+		{
+			name: "replaces in place, keeps newer existing and appends new",
+			existing: `{"number":1,"updated_at":"2024-01-05T00:00:00Z","title":"kept"}
+{"number":2,"updated_at":"2024-01-01T00:00:00Z","title":"old"}
+{"note":"not a PR"}
+`,
+			records: []map[string]interface{}{
+				{"number": 2, "updated_at": "2024-01-02T00:00:00Z", "title": "new"},
+				{"number": 3, "updated_at": "2024-01-01T00:00:00Z", "title": "first"},
+				{"number": 1, "updated_at": "2024-01-03T00:00:00Z", "title": "stale"},
+				{"number": 3, "updated_at": "2024-01-01T00:00:00Z", "title": "second"},
+			},
+			want: []string{
+				`{"number":1,"updated_at":"2024-01-05T00:00:00Z","title":"kept"}`,
+				`{"number":2,"title":"new","updated_at":"2024-01-02T00:00:00Z"}`,
+				`{"note":"not a PR"}`,
+				`{"number":3,"title":"second","updated_at":"2024-01-01T00:00:00Z"}`,
+			},
+		},
+		{
+			name: "compacts duplicates already in the file",
+			existing: `{"number":1,"updated_at":"2024-01-01T00:00:00Z"}
+{"number":1,"updated_at":"2024-01-02T00:00:00Z"}
+
+`,
+			want: []string{`{"number":1,"updated_at":"2024-01-02T00:00:00Z"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "prs.ndjson")
+			if tt.existing != "" {
+				if err := os.WriteFile(filename, []byte(tt.existing), 0o640); err != nil {
+					t.Fatalf("failed to seed file: %v", err)
+				}
+			}
+
+			writer, err := NewUpsertFileWriter(filename)
+			if err != nil {
+				t.Fatalf("NewUpsertFileWriter failed: %v", err)
+			}
+			for _, record := range tt.records {
+				if wErr := writer.Write(record); wErr != nil {
+					t.Fatalf("Write failed: %v", wErr)
+				}
+			}
+			if cErr := writer.Close(); cErr != nil {
+				t.Fatalf("Close failed: %v", cErr)
+			}
+
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("failed to read result: %v", err)
+			}
+			got := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("unexpected result:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+
+			if tt.existing != "" {
+				info, err := os.Stat(filename)
+				if err != nil {
+					t.Fatalf("failed to stat result: %v", err)
+				}
+				if info.Mode().Perm() != 0o640 {
+					t.Errorf("permissions = %o, want 640", info.Mode().Perm())
+				}
+			}
+
+			// Only the target remains; staging and temporary files are removed
+			entries, _ := os.ReadDir(filepath.Dir(filename))
+			if len(entries) != 1 {
+				t.Errorf("expected only the output file to remain, found %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestUpsertFileWriter_InvalidExistingRecord(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "prs.ndjson")
+	original := "{\"number\":1,\"upd\n"
+	if err := os.WriteFile(filename, []byte(original), 0o644); err != nil {
+		t.Fatalf("failed to seed file: %v", err)
+	}
+
+	writer, err := NewUpsertFileWriter(filename)
+	if err != nil {
+		t.Fatalf("NewUpsertFileWriter failed: %v", err)
+	}
+	_ = writer.Write(map[string]interface{}{"number": 2})
+	if err := writer.Close(); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected invalid line error, got %v", err)
+	}
+
+	data, _ := os.ReadFile(filename)
+	if string(data) != original {
+		t.Errorf("target modified after failed upsert: %q", data)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Mode
+		wantErr bool
+	}{
+		{"", ModeOverwrite, false},
+		{"overwrite", ModeOverwrite, false},
+		{"append", ModeAppend, false},
+		{"upsert", ModeUpsert, false},
+		{"merge", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMode(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseMode(%q) = %q, %v; want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -77,6 +77,27 @@ func NewFileWriter(filename string) (*Writer, error) {
 		return nil, fmt.Errorf("failed to create output file: %w", err)
 	}
 
+	return newBufferedFileWriter(file), nil
+}
+
+// NewAppendFileWriter creates a new NDJSON writer that appends to a file.
+// The file is created if it does not exist; existing records are preserved
+// and new records are written after them.
+//
+// The caller must call Close() when done to ensure the file is properly closed
+// and any buffered data is flushed to disk.
+func NewAppendFileWriter(filename string) (*Writer, error) {
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o666)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file for append: %w", err)
+	}
+
+	return newBufferedFileWriter(file), nil
+}
+
+// newBufferedFileWriter wraps an open file in a buffered NDJSON writer that
+// flushes the buffer and closes the file on Close.
+func newBufferedFileWriter(file *os.File) *Writer {
 	// Create a buffered writer with 64KB buffer for efficient disk writes
 	bufWriter := bufio.NewWriterSize(file, 64*1024)
 
@@ -92,7 +113,7 @@ func NewFileWriter(filename string) (*Writer, error) {
 			}
 			return file.Close()
 		},
-	}, nil
+	}
 }
 
 // Write encodes a single record as JSON and writes it as a line to the output.
@@ -118,6 +139,23 @@ func (w *Writer) Write(record interface{}) error {
 	return nil
 }
 
+// Flush writes any buffered records to the underlying file. Once Flush
+// returns, every record passed to Write is visible to readers of the file.
+// For writers created with NewWriter, this is a no-op that always returns nil.
+//
+// This method is safe for concurrent use.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.bufWriter != nil {
+		if err := w.bufWriter.Flush(); err != nil {
+			return fmt.Errorf("failed to flush buffer: %w", err)
+		}
+	}
+	return nil
+}
+
 // Count returns the total number of records successfully written.
 // This method is safe for concurrent use.
 func (w *Writer) Count() int {
@@ -237,6 +237,48 @@ func TestNewFileWriter(t *testing.T) {
 	}
 }
 
+func TestNewAppendFileWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.ndjson")
+
+	if err := os.WriteFile(filename, []byte(`{"id":1,"name":"Existing","active":true}`+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to seed output file: %v", err)
+	}
+
+	writer, err := NewAppendFileWriter(filename)
+	if err != nil {
+		t.Fatalf("NewAppendFileWriter failed: %v", err)
+	}
+
+	if wErr := writer.Write(TestRecord{ID: 2, Name: "Appended"}); wErr != nil {
+		t.Fatalf("Write failed: %v", wErr)
+	}
+
+	// Flush makes the record visible before Close
+	if fErr := writer.Flush(); fErr != nil {
+		t.Fatalf("Flush failed: %v", fErr)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if got := strings.Count(string(data), "\n"); got != 2 {
+		t.Errorf("expected 2 lines after flush, got %d", got)
+	}
+
+	if cErr := writer.Close(); cErr != nil {
+		t.Fatalf("Close failed: %v", cErr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var first, second TestRecord
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first.ID != 1 {
+		t.Errorf("existing record not preserved: %q", lines[0])
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil || second.ID != 2 {
+		t.Errorf("appended record not written: %q", lines[1])
+	}
+}
+
 func TestNewFileWriter_Error(t *testing.T) {
 	// Try to create file in non-existent directory
 	_, err := NewFileWriter("/non/existent/path/test.ndjson")
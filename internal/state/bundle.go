@@ -0,0 +1,82 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// BundleFormat identifies the JSON documents written by WriteBundle.
+const BundleFormat = "sirseer-relay-state-bundle"
+
+// bundle is a set of fetch states exported together, used to move state
+// between machines. Each state keeps its own version and checksum, so it is
+// validated and migrated like a state file when the bundle is read.
+type bundle struct {
+	Format     string            `json:"format"`
+	ExportedAt time.Time         `json:"exported_at"`
+	States     []json.RawMessage `json:"states"`
+}
+
+// WriteBundle writes states to w as a bundle that ReadBundle accepts.
+func WriteBundle(w io.Writer, states []*FetchState) error {
+	b := bundle{
+		Format:     BundleFormat,
+		ExportedAt: time.Now().UTC(),
+		States:     make([]json.RawMessage, 0, len(states)),
+	}
+	for _, state := range states {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("failed to marshal state of %s: %w", state.Repository, err)
+		}
+		b.States = append(b.States, data)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(b); err != nil {
+		return fmt.Errorf("failed to write state bundle: %w", err)
+	}
+	return nil
+}
+
+// ReadBundle reads a bundle written by WriteBundle. Every state's checksum
+// is verified and states of older versions are migrated to CurrentVersion.
+func ReadBundle(r io.Reader) ([]*FetchState, error) {
+	var b bundle
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid state bundle: %w", err)
+	}
+	if b.Format != BundleFormat {
+		return nil, fmt.Errorf("invalid state bundle: format is %q, want %q", b.Format, BundleFormat)
+	}
+
+	states := make([]*FetchState, 0, len(b.States))
+	for i, data := range b.States {
+		state, _, err := decodeState(data)
+		if err != nil {
+			return nil, fmt.Errorf("state %d of the bundle: %w", i+1, err)
+		}
+		if state.Repository == "" {
+			return nil, fmt.Errorf("state %d of the bundle has no repository", i+1)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
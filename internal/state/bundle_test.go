@@ -0,0 +1,119 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBundle_RoundTrip(t *testing.T) {
+	watermark := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	states := []*FetchState{
+		{Repository: "golang/go", LastPRNumber: 100, UpdatedWatermark: &watermark, LastFetchTime: watermark},
+		{Repository: "facebook/react", Checkpoint: &Checkpoint{Cursor: "cursor_5", PageNum: 1}},
+	}
+	// States are exported as saved, with version and checksum
+	dir := t.TempDir()
+	for _, s := range states {
+		if err := SaveState(s, StateFilePath(dir, s.Repository)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, states); err != nil {
+		t.Fatalf("WriteBundle() error = %v", err)
+	}
+	read, err := ReadBundle(&buf)
+	if err != nil {
+		t.Fatalf("ReadBundle() error = %v", err)
+	}
+	if len(read) != len(states) {
+		t.Fatalf("read %d states, want %d", len(read), len(states))
+	}
+	for i := range states {
+		if read[i].Repository != states[i].Repository || read[i].Checksum != states[i].Checksum {
+			t.Errorf("state %d = %+v, want %+v", i, read[i], states[i])
+		}
+	}
+	if read[1].Checkpoint == nil || read[1].Checkpoint.Cursor != "cursor_5" {
+		t.Errorf("checkpoint not preserved: %+v", read[1].Checkpoint)
+	}
+}
+
+func TestReadBundle_Invalid(t *testing.T) {
+	v1, err := os.ReadFile(filepath.Join("testdata", "v1.state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 = bytes.TrimSpace(v1)
+	tampered := bytes.Replace(v1, []byte(`"total_fetched":4200`), []byte(`"total_fetched":1`), 1)
+
+	tests := []struct {
+		name    string
+		bundle  string
+		wantErr string
+	}{
+		{name: "not JSON", bundle: "state", wantErr: "invalid state bundle"},
+		{name: "other format", bundle: `{"format":"other","states":[]}`, wantErr: "format is"},
+		{name: "tampered state", bundle: `{"format":"` + BundleFormat + `","states":[` + string(tampered) + `]}`, wantErr: "checksum mismatch"},
+		{name: "state without repository", bundle: `{"format":"` + BundleFormat + `","states":[` + checksummed(t, &FetchState{}) + `]}`, wantErr: "no repository"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadBundle(strings.NewReader(tt.bundle))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadBundle() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadBundle_MigratesOlderVersions(t *testing.T) {
+	v1, err := os.ReadFile(filepath.Join("testdata", "v1.state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	states, err := ReadBundle(strings.NewReader(`{"format":"` + BundleFormat + `","states":[` + string(bytes.TrimSpace(v1)) + `]}`))
+	if err != nil {
+		t.Fatalf("ReadBundle() error = %v", err)
+	}
+	if states[0].Version != CurrentVersion || states[0].UpdatedWatermark == nil {
+		t.Errorf("expected the state to be migrated, got %+v", states[0])
+	}
+	if err := verifyChecksum(states[0]); err != nil {
+		t.Errorf("migrated state has an invalid checksum: %v", err)
+	}
+}
+
+// checksummed returns state as JSON with a current version and checksum.
+func checksummed(t *testing.T, state *FetchState) string {
+	t.Helper()
+	state.Version = CurrentVersion
+	state.Checksum, _ = calculateChecksum(state)
+	data, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -21,7 +21,17 @@
 // State files are stored in a standard location (~/.sirseer/state/) and use
 // a JSON format for human readability and debugging. Every state write is
 // atomic, using a write-to-temp-and-rename pattern to prevent corruption
-// during crashes or power loss.
+// during crashes or power loss. Runs that load, fetch and save the state of
+// a repository hold an advisory lock from AcquireLock, so overlapping runs do
+// not overwrite each other's progress. Files written by older schema versions
+// are migrated to the current version in memory when they are loaded, and
+// saved in the new version, keeping a backup of the original, the next time
+// the state is saved.
+//
+// Callers that fetch reach state through a StateStore opened by OpenStore.
+// FileStore, the default, keeps the state files described above; SQLiteStore
+// keeps the state, run history and fetch metadata of every repository in one
+// transactional SQLite database instead.
 //
 // Example usage:
 //
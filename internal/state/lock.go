@@ -0,0 +1,138 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockPollInterval is how often AcquireLock retries a lock held by another
+// process while waiting for it.
+const lockPollInterval = 250 * time.Millisecond
+
+// ErrLocked is matched by the error AcquireLock returns when another process
+// holds the lock.
+var ErrLocked = errors.New("state file is locked")
+
+// LockedError reports that another process holds the lock on a state file.
+type LockedError struct {
+	// LockFile is the path of the held lock file.
+	LockFile string
+
+	// PID is the process holding the lock, or 0 if it is unknown.
+	PID int
+}
+
+// Error describes the holder of the lock and how to proceed.
+func (e *LockedError) Error() string {
+	holder := "another fetch is running"
+	if e.PID > 0 {
+		holder = fmt.Sprintf("another fetch is running (pid %d)", e.PID)
+	}
+	return fmt.Sprintf("%s for this repository (lock: %s). Wait for it to finish or use --wait-for-lock", holder, e.LockFile)
+}
+
+// Is makes errors.Is(err, ErrLocked) report true for a LockedError.
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+// Lock is an advisory lock on a repository's state file, held from
+// AcquireLock until Unlock. It only excludes other callers of AcquireLock;
+// LoadState and SaveState do not check it.
+type Lock struct {
+	file *os.File
+	path string
+}
+
+// LockFilePath returns the path of the lock file guarding stateFile.
+func LockFilePath(stateFile string) string {
+	return stateFile + ".lock"
+}
+
+// AcquireLock takes the lock on stateFile for this process, so that loading
+// the state, fetching and saving the new state are not interleaved with
+// another run on the same repository. While another process holds the lock,
+// AcquireLock retries until wait has elapsed and then returns a *LockedError;
+// a wait of zero fails at once. A lock left behind by a process that no
+// longer exists is taken over.
+func AcquireLock(ctx context.Context, stateFile string, wait time.Duration) (*Lock, error) {
+	path := LockFilePath(stateFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		lock, err := tryLock(path)
+		if err == nil {
+			return lock, nil
+		}
+		remaining := time.Until(deadline)
+		if !errors.Is(err, ErrLocked) || remaining <= 0 {
+			return nil, err
+		}
+
+		timer := time.NewTimer(min(lockPollInterval, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Path returns the path of the lock file.
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// readLockPID returns the process ID recorded in a lock file, or 0 if the
+// file holds none.
+func readLockPID(file *os.File) int {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0
+	}
+	data, err := io.ReadAll(io.LimitReader(file, 32))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0
+	}
+	return pid
+}
+
+// writeLockPID records the current process as the holder of a lock file,
+// replacing the ID of any previous holder.
+func writeLockPID(file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		return err
+	}
+	return file.Sync()
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !unix
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// tryLock creates the lock file at path exclusively without blocking. A lock
+// file whose recorded process no longer exists is stale; it is removed and
+// the lock taken once more.
+func tryLock(path string) (*Lock, error) {
+	for attempt := 0; ; attempt++ {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600) // #nosec G304 -- path is derived from the state file path
+		if err == nil {
+			if err := writeLockPID(file); err != nil {
+				_ = file.Close()
+				_ = os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file: %w", err)
+			}
+			return &Lock{file: file, path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		pid := lockFileHolder(path)
+		if attempt > 0 || pid == 0 || processAlive(pid) {
+			return nil, &LockedError{LockFile: path, PID: pid}
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale lock file: %w", err)
+		}
+	}
+}
+
+// lockFileHolder returns the process ID recorded in the lock file at path,
+// or 0 if it cannot be read.
+func lockFileHolder(path string) int {
+	file, err := os.Open(path) // #nosec G304 -- path is derived from the state file path
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	return readLockPID(file)
+}
+
+// processAlive reports whether a process with the given ID exists.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = process.Release()
+	return true
+}
+
+// Unlock releases the lock by removing the lock file.
+func (l *Lock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	file := l.file
+	l.file = nil
+
+	_ = file.Close()
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove lock file %s: %w", l.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock_Exclusive(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state", "test-repo.state")
+
+	lock, err := AcquireLock(context.Background(), stateFile, 0)
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	if lock.Path() != LockFilePath(stateFile) {
+		t.Errorf("Path() = %s, want %s", lock.Path(), LockFilePath(stateFile))
+	}
+
+	_, err = AcquireLock(context.Background(), stateFile, 0)
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked while the lock is held, got %v", err)
+	}
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.PID != os.Getpid() {
+		t.Errorf("expected the lock holder to be pid %d, got %v", os.Getpid(), err)
+	}
+	if want := "another fetch is running (pid " + strconv.Itoa(os.Getpid()) + ")"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q does not contain %q", err, want)
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Errorf("second Unlock() error = %v", err)
+	}
+
+	relock, err := AcquireLock(context.Background(), stateFile, 0)
+	if err != nil {
+		t.Fatalf("AcquireLock() after Unlock error = %v", err)
+	}
+	_ = relock.Unlock()
+}
+
+func TestAcquireLock_Wait(t *testing.T) {
+	tests := []struct {
+		name    string
+		wait    time.Duration
+		release time.Duration
+		wantErr error
+	}{
+		{name: "released while waiting", wait: 5 * time.Second, release: 100 * time.Millisecond},
+		{name: "wait times out", wait: 300 * time.Millisecond, release: 5 * time.Second, wantErr: ErrLocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateFile := filepath.Join(t.TempDir(), "test-repo.state")
+			held, err := AcquireLock(context.Background(), stateFile, 0)
+			if err != nil {
+				t.Fatalf("AcquireLock() error = %v", err)
+			}
+			// The holder releases the lock after tt.release or when the test ends
+			stop := make(chan struct{})
+			released := make(chan struct{})
+			go func() {
+				defer close(released)
+				select {
+				case <-time.After(tt.release):
+				case <-stop:
+				}
+				_ = held.Unlock()
+			}()
+			defer func() {
+				close(stop)
+				<-released
+			}()
+
+			lock, err := AcquireLock(context.Background(), stateFile, tt.wait)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AcquireLock() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				_ = lock.Unlock()
+			}
+		})
+	}
+}
+
+func TestAcquireLock_ContextCancelled(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "test-repo.state")
+	held, err := AcquireLock(context.Background(), stateFile, 0)
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	defer held.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := AcquireLock(ctx, stateFile, time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the wait to end with the context, got %v", err)
+	}
+}
+
+func TestAcquireLock_StaleLockFile(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "test-repo.state")
+
+	// A lock file left behind by a process that no longer exists
+	if err := os.WriteFile(LockFilePath(stateFile), []byte("999999999\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := AcquireLock(context.Background(), stateFile, 0)
+	if err != nil {
+		t.Fatalf("expected the stale lock to be taken over, got %v", err)
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(LockFilePath(stateFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != strconv.Itoa(os.Getpid())+"\n" {
+		t.Errorf("lock file = %q, want the current pid", got)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// migration upgrades the fields of a state file by one schema version, in
+// place. Fields are kept as raw JSON so a migration can rename, convert or
+// drop fields that FetchState no longer has.
+type migration func(fields map[string]json.RawMessage) error
+
+// migrations upgrade state files written by older versions of the tool:
+// migrations[v] turns a version v file into version v+1. When CurrentVersion
+// is incremented, the migration from the previous version is added here and
+// a fixture of that version to testdata/.
+var migrations = map[int]migration{
+	1: migrateV1ToV2,
+}
+
+// BackupFilePath returns the path the original of a state file of the given
+// version is copied to before it is migrated.
+func BackupFilePath(stateFile string, version int) string {
+	return fmt.Sprintf("%s.v%d.bak", stateFile, version)
+}
+
+// migrateState upgrades data, the content of a state file of an older
+// version decoded as original, to CurrentVersion. The checksum of the
+// original is verified first and the migrated state gets a new one.
+func migrateState(data []byte, original *FetchState) (*FetchState, error) {
+	// Content of older versions decodes into FetchState as long as versions
+	// only add fields, which holds for every version so far
+	if err := verifyChecksum(original); err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("state file is corrupted (invalid JSON): %w", err)
+	}
+	for v := original.Version; v < CurrentVersion; v++ {
+		if err := migrations[v](fields); err != nil {
+			return nil, fmt.Errorf("failed to migrate state file from version %d to %d: %w", v, v+1, err)
+		}
+	}
+	delete(fields, "checksum")
+
+	migratedData, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal migrated state: %w", err)
+	}
+	var migrated FetchState
+	if err := json.Unmarshal(migratedData, &migrated); err != nil {
+		return nil, fmt.Errorf("failed to decode migrated state: %w", err)
+	}
+	migrated.Version = CurrentVersion
+	if migrated.Checksum, err = calculateChecksum(&migrated); err != nil {
+		return nil, fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+	return &migrated, nil
+}
+
+// migrationSupported reports whether a state file of the given version can
+// be migrated to CurrentVersion.
+func migrationSupported(version int) bool {
+	if version < 1 || version >= CurrentVersion {
+		return false
+	}
+	for v := version; v < CurrentVersion; v++ {
+		if migrations[v] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// migrateV1ToV2 records the updated-at watermark of completed fetches made
+// before update tracking existed. Those fetches saw every PR created up to
+// last_pr_date, which is where incremental fetches have been falling back to.
+func migrateV1ToV2(fields map[string]json.RawMessage) error {
+	if watermark, ok := fields["updated_watermark"]; !ok || string(watermark) == "null" {
+		var lastFetchTime time.Time
+		if raw, ok := fields["last_fetch_time"]; ok {
+			if err := json.Unmarshal(raw, &lastFetchTime); err != nil {
+				return fmt.Errorf("invalid last_fetch_time: %w", err)
+			}
+		}
+		if lastPRDate, ok := fields["last_pr_date"]; ok && !lastFetchTime.IsZero() {
+			fields["updated_watermark"] = lastPRDate
+		}
+	}
+
+	fields["version"] = json.RawMessage("2")
+	return nil
+}
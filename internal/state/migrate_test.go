@@ -0,0 +1,185 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// copyFixture copies a state file from testdata into a temporary directory
+// and returns its path and original content.
+func copyFixture(t *testing.T, name string) (string, []byte) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	stateFile := filepath.Join(t.TempDir(), "repo.state")
+	if err := os.WriteFile(stateFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return stateFile, data
+}
+
+func TestLoadState_MigratesOlderVersions(t *testing.T) {
+	tests := []struct {
+		fixture       string
+		version       int
+		repository    string
+		lastPRNumber  int
+		wantWatermark *time.Time
+		checkpoint    bool
+	}{
+		{
+			fixture:       "v1.state",
+			version:       1,
+			repository:    "kubernetes/kubernetes",
+			lastPRNumber:  12345,
+			wantWatermark: timeAt("2024-03-01T09:30:00Z"),
+		},
+		{
+			fixture:       "v1-watermark.state",
+			version:       1,
+			repository:    "golang/go",
+			lastPRNumber:  67890,
+			wantWatermark: timeAt("2024-06-01T07:59:00Z"),
+		},
+		{
+			fixture:    "v1-checkpoint.state",
+			version:    1,
+			repository: "facebook/react",
+			checkpoint: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			stateFile, original := copyFixture(t, tt.fixture)
+
+			state, err := LoadState(stateFile)
+			if err != nil {
+				t.Fatalf("LoadState() error = %v", err)
+			}
+			if state.Version != CurrentVersion {
+				t.Errorf("Version = %d, want %d", state.Version, CurrentVersion)
+			}
+			if state.Repository != tt.repository || state.LastPRNumber != tt.lastPRNumber {
+				t.Errorf("fields not preserved: %+v", state)
+			}
+			if !sameTime(state.UpdatedWatermark, tt.wantWatermark) {
+				t.Errorf("UpdatedWatermark = %v, want %v", state.UpdatedWatermark, tt.wantWatermark)
+			}
+			if (state.Checkpoint != nil) != tt.checkpoint {
+				t.Errorf("Checkpoint = %+v, want present: %v", state.Checkpoint, tt.checkpoint)
+			}
+
+			// Loading leaves the file alone
+			if data, err := os.ReadFile(stateFile); err != nil || !bytes.Equal(data, original) {
+				t.Errorf("LoadState() rewrote the file: %v", err)
+			}
+			if _, err := os.Stat(BackupFilePath(stateFile, tt.version)); !os.IsNotExist(err) {
+				t.Errorf("expected no backup before the state is saved, got %v", err)
+			}
+
+			// Saving persists the upgrade and backs up the original unchanged
+			if err := SaveState(state, stateFile); err != nil {
+				t.Fatalf("SaveState() error = %v", err)
+			}
+			backup, err := os.ReadFile(BackupFilePath(stateFile, tt.version))
+			if err != nil {
+				t.Fatalf("expected a backup of the original: %v", err)
+			}
+			if !bytes.Equal(backup, original) {
+				t.Error("backup differs from the original file")
+			}
+
+			// The migrated file is saved with a valid checksum
+			reloaded, err := LoadState(stateFile)
+			if err != nil {
+				t.Fatalf("LoadState() of the migrated file error = %v", err)
+			}
+			if reloaded.Version != CurrentVersion || reloaded.Checksum != state.Checksum {
+				t.Errorf("migrated file not saved: version %d, checksum %s", reloaded.Version, reloaded.Checksum)
+			}
+		})
+	}
+}
+
+func TestLoadState_MigrationRejectsCorruptedFile(t *testing.T) {
+	stateFile, original := copyFixture(t, "v1.state")
+	tampered := bytes.Replace(original, []byte(`"total_fetched":4200`), []byte(`"total_fetched":4201`), 1)
+	if err := os.WriteFile(stateFile, tampered, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadState(stateFile)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if data, _ := os.ReadFile(stateFile); !bytes.Equal(data, tampered) {
+		t.Error("corrupted file should be left untouched")
+	}
+	if _, err := os.Stat(BackupFilePath(stateFile, 1)); !os.IsNotExist(err) {
+		t.Errorf("expected no backup for a rejected file, got %v", err)
+	}
+}
+
+func TestLoadState_NewerVersion(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "repo.state")
+	newer := &FetchState{Version: CurrentVersion + 1, Repository: "test/repo"}
+	newer.Checksum, _ = calculateChecksum(newer)
+	data := fmt.Sprintf(`{"version":%d,"checksum":%q,"repository":"test/repo"}`, newer.Version, newer.Checksum)
+	if err := os.WriteFile(stateFile, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadState(stateFile)
+	if err == nil || !strings.Contains(err.Error(), "incompatible with current version") {
+		t.Errorf("expected a version error, got %v", err)
+	}
+}
+
+func TestMigrations_CoverEveryVersion(t *testing.T) {
+	for v := 1; v < CurrentVersion; v++ {
+		if migrations[v] == nil {
+			t.Errorf("no migration from version %d", v)
+		}
+		fixtures, err := filepath.Glob(filepath.Join("testdata", fmt.Sprintf("v%d*.state", v)))
+		if err != nil || len(fixtures) == 0 {
+			t.Errorf("no fixture for version %d in testdata", v)
+		}
+	}
+}
+
+func timeAt(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return &t
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,341 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+
+	// Registers the pure-Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteFileName is the name of the database BackendSQLite keeps in the
+// state directory.
+const SQLiteFileName = "state.db"
+
+// sqliteSchemaVersion is the version of the database schema, kept in the
+// user_version pragma.
+const sqliteSchemaVersion = 1
+
+// sqliteTimeFormat stores timestamps in UTC with a fixed number of digits,
+// so they sort as text and SQLite's date functions accept them.
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// sqliteSchema creates the tables of a new database. The data columns hold
+// the same JSON as a state or metadata file and are authoritative; the
+// other columns copy fields of it so the database can be queried directly.
+var sqliteSchema = []string{
+	`CREATE TABLE states (
+		repository        TEXT PRIMARY KEY,
+		version           INTEGER NOT NULL,
+		last_fetch_id     TEXT NOT NULL,
+		last_pr_number    INTEGER NOT NULL,
+		last_fetch_time   TEXT,
+		updated_watermark TEXT,
+		total_fetched     INTEGER NOT NULL,
+		interrupted       INTEGER NOT NULL,
+		saved_at          TEXT NOT NULL,
+		data              TEXT NOT NULL
+	)`,
+	`CREATE TABLE runs (
+		repository        TEXT NOT NULL,
+		fetch_id          TEXT NOT NULL,
+		completed_at      TEXT NOT NULL,
+		last_pr_number    INTEGER NOT NULL,
+		updated_watermark TEXT,
+		total_fetched     INTEGER NOT NULL,
+		PRIMARY KEY (repository, fetch_id)
+	)`,
+	`CREATE TABLE metadata (
+		repository   TEXT NOT NULL,
+		fetch_id     TEXT NOT NULL,
+		started_at   TEXT NOT NULL,
+		completed_at TEXT NOT NULL,
+		incremental  INTEGER NOT NULL,
+		interrupted  INTEGER NOT NULL,
+		total_prs    INTEGER NOT NULL,
+		api_calls    INTEGER NOT NULL,
+		data         TEXT NOT NULL,
+		PRIMARY KEY (repository, fetch_id)
+	)`,
+	`CREATE INDEX metadata_completed ON metadata (repository, completed_at)`,
+	`CREATE TABLE state_backups (
+		repository TEXT NOT NULL,
+		version    INTEGER NOT NULL,
+		saved_at   TEXT NOT NULL,
+		data       TEXT NOT NULL,
+		PRIMARY KEY (repository, version)
+	)`,
+}
+
+// SQLiteStore is the StateStore that keeps the state of every repository,
+// the history of their completed fetches and the metadata of every fetch in
+// one SQLite database. Each save is a transaction, so the database is never
+// left half written. Locks are files next to the database, as with
+// FileStore.
+type SQLiteStore struct {
+	db   *sql.DB
+	path string
+}
+
+// OpenSQLiteStore opens the database at path, creating it and its directory
+// when they do not exist.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	// Create the database readable by its owner only, like state files
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600) // #nosec G304 -- path is the configured state database
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+	_ = file.Close()
+
+	// Concurrent fetches wait for each other's writes instead of failing,
+	// and transactions take the write lock up front
+	dsn := path + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+
+	s := &SQLiteStore{db: db, path: path}
+	if err := s.initSchema(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// initSchema creates the tables of a new database and refuses databases
+// written by a newer version.
+func (s *SQLiteStore) initSchema() error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to open state database %s: %w", s.path, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var version int
+	if err := tx.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read state database %s: %w", s.path, err)
+	}
+	switch {
+	case version == sqliteSchemaVersion:
+		return nil
+	case version > sqliteSchemaVersion:
+		return fmt.Errorf("state database version (%d) is incompatible with current version (%d)", version, sqliteSchemaVersion)
+	}
+
+	for _, statement := range sqliteSchema {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create state database: %w", err)
+		}
+	}
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion)); err != nil {
+		return fmt.Errorf("failed to create state database: %w", err)
+	}
+	return tx.Commit()
+}
+
+// LoadState loads and validates the state of repository. State of an older
+// version is migrated in memory only; SaveState keeps the original in
+// state_backups when it replaces it, as FileStore keeps it at BackupFilePath.
+func (s *SQLiteStore) LoadState(repository string) (*FetchState, error) {
+	var data string
+	err := s.db.QueryRow("SELECT data FROM states WHERE repository = ?", repository).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w for %s in %s. Use --all flag for initial fetch", ErrNoState, repository, s.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state of %s: %w", repository, err)
+	}
+
+	state, _, err := decodeState([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// SaveState saves state in one transaction. A state without a checkpoint
+// that records a new completed fetch is also added to the run history, and
+// a saved state of an older version is kept in state_backups.
+func (s *SQLiteStore) SaveState(state *FetchState) error {
+	data, err := encodeState(state)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(`INSERT OR REPLACE INTO state_backups (repository, version, saved_at, data)
+		SELECT repository, version, ?, data FROM states WHERE repository = ? AND version < ?`,
+		sqliteTime(time.Now()), state.Repository, CurrentVersion)
+	if err != nil {
+		return fmt.Errorf("failed to back up state before migration: %w", err)
+	}
+	if err := saveStateTx(tx, state, data); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// saveStateTx writes state, encoded as data, and its run history entry
+// within tx.
+func saveStateTx(tx *sql.Tx, state *FetchState, data []byte) error {
+	_, err := tx.Exec(`INSERT INTO states (repository, version, last_fetch_id, last_pr_number, last_fetch_time,
+			updated_watermark, total_fetched, interrupted, saved_at, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (repository) DO UPDATE SET version = excluded.version, last_fetch_id = excluded.last_fetch_id,
+			last_pr_number = excluded.last_pr_number, last_fetch_time = excluded.last_fetch_time,
+			updated_watermark = excluded.updated_watermark, total_fetched = excluded.total_fetched,
+			interrupted = excluded.interrupted, saved_at = excluded.saved_at, data = excluded.data`,
+		state.Repository, state.Version, state.LastFetchID, state.LastPRNumber, sqliteTimeOrNull(&state.LastFetchTime),
+		sqliteTimeOrNull(state.UpdatedWatermark), state.TotalFetched, state.Checkpoint != nil,
+		sqliteTime(time.Now()), string(data))
+	if err != nil {
+		return err
+	}
+
+	if state.Checkpoint == nil && !state.LastFetchTime.IsZero() {
+		_, err = tx.Exec(`INSERT OR IGNORE INTO runs (repository, fetch_id, completed_at, last_pr_number, updated_watermark, total_fetched)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			state.Repository, state.LastFetchID, sqliteTime(state.LastFetchTime), state.LastPRNumber,
+			sqliteTimeOrNull(state.UpdatedWatermark), state.TotalFetched)
+		if err != nil {
+			return fmt.Errorf("failed to record fetch in run history: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteState removes the state of repository. Its run history and
+// metadata are kept.
+func (s *SQLiteStore) DeleteState(repository string) error {
+	if _, err := s.db.Exec("DELETE FROM states WHERE repository = ?", repository); err != nil {
+		return fmt.Errorf("failed to delete state: %w", err)
+	}
+	return nil
+}
+
+// ListStates returns the repositories with saved state.
+func (s *SQLiteStore) ListStates() ([]string, error) {
+	rows, err := s.db.Query("SELECT repository FROM states ORDER BY repository")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list states: %w", err)
+	}
+	defer rows.Close()
+
+	var repos []string
+	for rows.Next() {
+		var repo string
+		if err := rows.Scan(&repo); err != nil {
+			return nil, fmt.Errorf("failed to list states: %w", err)
+		}
+		repos = append(repos, repo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list states: %w", err)
+	}
+	return repos, nil
+}
+
+// SaveMetadata records the metadata of a fetch. Saving the metadata of the
+// same fetch again replaces it.
+func (s *SQLiteStore) SaveMetadata(m *metadata.FetchMetadata) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	repository := fmt.Sprintf("%s/%s", m.Parameters.Organization, m.Parameters.Repository)
+	_, err = s.db.Exec(`INSERT OR REPLACE INTO metadata (repository, fetch_id, started_at, completed_at,
+			incremental, interrupted, total_prs, api_calls, data)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		repository, m.FetchID, sqliteTime(m.Results.StartedAt), sqliteTime(m.Results.CompletedAt),
+		m.Incremental, m.Interrupted, m.Results.TotalPRs, m.Results.APICallCount, string(data))
+	if err != nil {
+		return fmt.Errorf("failed to save metadata: %w", err)
+	}
+	return nil
+}
+
+// LoadLatestMetadata returns the metadata of the fetch of repository that
+// completed last, or nil when there is none.
+func (s *SQLiteStore) LoadLatestMetadata(repository string) (*metadata.FetchMetadata, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM metadata WHERE repository = ?
+		ORDER BY completed_at DESC LIMIT 1`, repository).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metadata: %w", err)
+	}
+
+	var m metadata.FetchMetadata
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	return &m, nil
+}
+
+// Lock locks the state of repository with a lock file next to the database.
+func (s *SQLiteStore) Lock(ctx context.Context, repository string, wait time.Duration) (*Lock, error) {
+	return AcquireLock(ctx, StateFilePath(filepath.Dir(s.path), repository), wait)
+}
+
+// Location returns the path of the database.
+func (s *SQLiteStore) Location() string {
+	return s.path
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// sqliteTime formats t for a timestamp column.
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+// sqliteTimeOrNull formats an optional timestamp, storing NULL when it is
+// unset.
+func sqliteTimeOrNull(t *time.Time) interface{} {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return sqliteTime(*t)
+}
@@ -0,0 +1,258 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+)
+
+// openTestSQLiteStore opens a new database in a temporary directory.
+func openTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := OpenSQLiteStore(filepath.Join(t.TempDir(), SQLiteFileName))
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestSQLiteStore_RunHistory(t *testing.T) {
+	store := openTestSQLiteStore(t)
+	fetched := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	saves := []*FetchState{
+		// An interrupted first fetch is not a run yet
+		{Repository: "org/repo", Checkpoint: &Checkpoint{FetchID: "full-1", PRsWritten: 50}},
+		{Repository: "org/repo", LastFetchID: "full-1", LastPRNumber: 100, LastFetchTime: fetched, TotalFetched: 100},
+		// Saving the same fetch again, as state set does, adds no run
+		{Repository: "org/repo", LastFetchID: "full-1", LastPRNumber: 90, LastFetchTime: fetched, TotalFetched: 100},
+		{Repository: "org/repo", LastFetchID: "inc-2", LastPRNumber: 105, LastFetchTime: fetched.Add(time.Hour), TotalFetched: 5},
+		{Repository: "org/other", LastFetchID: "full-3", LastPRNumber: 1, LastFetchTime: fetched, TotalFetched: 1},
+	}
+	for _, fetchState := range saves {
+		if err := store.SaveState(fetchState); err != nil {
+			t.Fatalf("SaveState() error = %v", err)
+		}
+	}
+	if err := store.DeleteState("org/repo"); err != nil {
+		t.Fatalf("DeleteState() error = %v", err)
+	}
+
+	rows, err := store.db.Query("SELECT fetch_id, last_pr_number FROM runs WHERE repository = ? ORDER BY completed_at", "org/repo")
+	if err != nil {
+		t.Fatalf("failed to query runs: %v", err)
+	}
+	defer rows.Close()
+	var runs []string
+	for rows.Next() {
+		var fetchID string
+		var lastPR int
+		if err := rows.Scan(&fetchID, &lastPR); err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, fmt.Sprintf("%s #%d", fetchID, lastPR))
+	}
+	if got := strings.Join(runs, ", "); got != "full-1 #100, inc-2 #105" {
+		t.Errorf("runs of org/repo = %q, want the two completed fetches kept after DeleteState", got)
+	}
+}
+
+func TestSQLiteStore_LatestMetadata(t *testing.T) {
+	store := openTestSQLiteStore(t)
+	started := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, fetch := range []struct {
+		repo, fetchID string
+		completed     time.Duration
+	}{
+		{"repo", "full-1", time.Minute},
+		{"repo", "inc-3", 3 * time.Hour},
+		{"repo", "inc-2", 2 * time.Hour},
+		{"other", "inc-4", 4 * time.Hour},
+	} {
+		fetchMetadata := &metadata.FetchMetadata{
+			FetchID:    fetch.fetchID,
+			Parameters: metadata.FetchParams{Organization: "org", Repository: fetch.repo},
+			Results:    metadata.FetchResults{TotalPRs: i, StartedAt: started, CompletedAt: started.Add(fetch.completed)},
+		}
+		if err := store.SaveMetadata(fetchMetadata); err != nil {
+			t.Fatalf("SaveMetadata() error = %v", err)
+		}
+	}
+
+	latest, err := store.LoadLatestMetadata("org/repo")
+	if err != nil || latest == nil {
+		t.Fatalf("LoadLatestMetadata() = %v, %v", latest, err)
+	}
+	if latest.FetchID != "inc-3" {
+		t.Errorf("LoadLatestMetadata() = %s, want inc-3, the fetch of org/repo that completed last", latest.FetchID)
+	}
+}
+
+func TestSQLiteStore_DetectsCorruption(t *testing.T) {
+	store := openTestSQLiteStore(t)
+	if err := store.SaveState(&FetchState{Repository: "org/repo", LastPRNumber: 5}); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+
+	if _, err := store.db.Exec(`UPDATE states SET data = replace(data, '"last_pr_number":5', '"last_pr_number":6')`); err != nil {
+		t.Fatal(err)
+	}
+	_, err := store.LoadState("org/repo")
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("LoadState() of edited state error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestSQLiteStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", SQLiteFileName)
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore() error = %v", err)
+	}
+	if err := store.SaveState(&FetchState{Repository: "org/repo", LastPRNumber: 5}); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("database permissions = %o, want 600", perm)
+		}
+	}
+
+	store, err = OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopening the database failed: %v", err)
+	}
+	defer store.Close()
+	loaded, err := store.LoadState("org/repo")
+	if err != nil || loaded.LastPRNumber != 5 {
+		t.Errorf("LoadState() after reopening = %v, %v", loaded, err)
+	}
+}
+
+func TestSQLiteStore_MigrationKeepsBackup(t *testing.T) {
+	store := openTestSQLiteStore(t)
+	original, err := os.ReadFile(filepath.Join("testdata", "v1.state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.db.Exec(`INSERT INTO states (repository, version, last_fetch_id, last_pr_number, total_fetched, interrupted, saved_at, data)
+		VALUES ('kubernetes/kubernetes', 1, '', 0, 0, 0, '', ?)`, string(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := store.LoadState("kubernetes/kubernetes")
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if migrated.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", migrated.Version, CurrentVersion)
+	}
+
+	// Loading leaves the stored state alone
+	var version, backups int
+	if err := store.db.QueryRow("SELECT version FROM states WHERE repository = 'kubernetes/kubernetes'").Scan(&version); err != nil || version != 1 {
+		t.Errorf("stored version after loading = %d, %v, want 1", version, err)
+	}
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM state_backups").Scan(&backups); err != nil || backups != 0 {
+		t.Errorf("backups after loading = %d, %v, want none", backups, err)
+	}
+
+	// Saving persists the upgrade and keeps the original
+	if err := store.SaveState(migrated); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+	if err := store.db.QueryRow("SELECT version FROM states WHERE repository = 'kubernetes/kubernetes'").Scan(&version); err != nil || version != CurrentVersion {
+		t.Errorf("saved version = %d, %v, want the migrated state saved back", version, err)
+	}
+	var backup string
+	if err := store.db.QueryRow("SELECT data FROM state_backups WHERE repository = 'kubernetes/kubernetes' AND version = 1").Scan(&backup); err != nil {
+		t.Fatalf("failed to read the backup: %v", err)
+	}
+	if backup != string(original) {
+		t.Errorf("backup = %s, want the original version 1 state", backup)
+	}
+}
+
+func TestSQLiteStore_NewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SQLiteFileName)
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore() error = %v", err)
+	}
+	if _, err := store.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion+1)); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	_, err = OpenSQLiteStore(path)
+	if err == nil || !strings.Contains(err.Error(), "incompatible") {
+		t.Errorf("OpenSQLiteStore() of a newer database error = %v, want incompatible", err)
+	}
+}
+
+func TestSQLiteStore_ConcurrentWriters(t *testing.T) {
+	store := openTestSQLiteStore(t)
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			repo := fmt.Sprintf("org/repo-%d", i)
+			for n := 1; n <= 10; n++ {
+				fetchState := &FetchState{Repository: repo, LastFetchID: fmt.Sprintf("inc-%d", n), LastPRNumber: n, LastFetchTime: time.Now()}
+				if err := store.SaveState(fetchState); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent SaveState() error = %v", err)
+	}
+
+	var runs int
+	if err := store.db.QueryRow("SELECT count(*) FROM runs").Scan(&runs); err != nil {
+		t.Fatal(err)
+	}
+	if runs != writers*10 {
+		t.Errorf("recorded %d runs, want %d", runs, writers*10)
+	}
+}
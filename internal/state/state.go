@@ -18,41 +18,85 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
-// GetStateFilePath returns the standard path for a repository's state file.
-// Repository should be in "org/repo" format.
-// Returns: ~/.sirseer/state/org-repo.state
-func GetStateFilePath(repository string) string {
+// ErrNoState is returned when a repository has no saved state.
+var ErrNoState = errors.New("no previous fetch state found")
+
+// DefaultStateDir returns the directory state files are kept in when no
+// other is configured: ~/.sirseer/state.
+func DefaultStateDir() string {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		// Fallback to current directory if home directory is not accessible
 		homeDir = "."
 	}
+	return filepath.Join(homeDir, ".sirseer", "state")
+}
+
+// GetStateFilePath returns the standard path for a repository's state file.
+// Repository should be in "org/repo" format.
+// Returns: ~/.sirseer/state/org-repo.state
+func GetStateFilePath(repository string) string {
+	return StateFilePath(DefaultStateDir(), repository)
+}
+
+// StateFilePath returns the path of a repository's state file in stateDir,
+// or in DefaultStateDir when stateDir is empty.
+// Repository should be in "org/repo" format.
+// Returns: <stateDir>/org-repo.state
+func StateFilePath(stateDir, repository string) string {
+	if stateDir == "" {
+		stateDir = DefaultStateDir()
+	}
 
 	// Replace slashes with dashes for filesystem compatibility
 	safeRepoName := strings.ReplaceAll(repository, "/", "-")
 
-	return filepath.Join(homeDir, ".sirseer", "state", safeRepoName+".state")
+	return filepath.Join(stateDir, safeRepoName+".state")
+}
+
+// ListStateFiles returns the paths of the state files in stateDir, or in
+// DefaultStateDir when stateDir is empty, sorted by name. A missing
+// directory holds no state files.
+func ListStateFiles(stateDir string) ([]string, error) {
+	if stateDir == "" {
+		stateDir = DefaultStateDir()
+	}
+	entries, err := os.ReadDir(stateDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read state directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".state") {
+			files = append(files, filepath.Join(stateDir, entry.Name()))
+		}
+	}
+	return files, nil
 }
 
 // SaveState atomically saves the fetch state to disk with integrity validation.
 // It uses a write-to-temp-and-rename pattern to ensure atomicity.
 // The checksum is calculated and stored to detect corruption.
+// Concurrent saves do not corrupt the file, but the last one wins; runs that
+// load, fetch and save should hold the repository's lock from AcquireLock.
+// Replacing a file written by an older version keeps its original at
+// BackupFilePath.
 func SaveState(state *FetchState, stateFile string) error {
-	// Set version to current
-	state.Version = CurrentVersion
-
-	// Calculate checksum before adding it to the struct
-	checksum, err := calculateChecksum(state)
+	data, err := encodeState(state)
 	if err != nil {
-		return fmt.Errorf("failed to calculate checksum: %w", err)
+		return err
 	}
-	state.Checksum = checksum
 
 	// Ensure the directory exists
 	stateDir := filepath.Dir(stateFile)
@@ -60,27 +104,25 @@ func SaveState(state *FetchState, stateFile string) error {
 		return fmt.Errorf("failed to create state directory: %w", mkdirErr)
 	}
 
-	// Create a temporary file in the same directory
-	tempFile := stateFile + ".tmp"
+	if err := backupOlderVersion(stateFile); err != nil {
+		return err
+	}
 
-	// Marshal state to compact JSON for efficiency
-	data, err := json.Marshal(state)
+	// Write to a uniquely named temporary file in the same directory, so
+	// concurrent writers never share a temp file. CreateTemp restricts its
+	// permissions to the owner.
+	file, err := os.CreateTemp(stateDir, filepath.Base(stateFile)+".*.tmp")
 	if err != nil {
-		return fmt.Errorf("failed to marshal state: %w", err)
+		return fmt.Errorf("failed to create temporary state file: %w", err)
 	}
-
-	// Write to temporary file with restricted permissions
-	if writeErr := os.WriteFile(tempFile, data, 0o600); writeErr != nil {
-		return fmt.Errorf("failed to write temporary state file: %w", writeErr)
+	tempFile := file.Name()
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tempFile)
+		return fmt.Errorf("failed to write temporary state file: %w", err)
 	}
 
 	// Sync to ensure data is flushed to disk
-	file, err := os.Open(tempFile)
-	if err != nil {
-		// Clean up temp file
-		_ = os.Remove(tempFile)
-		return fmt.Errorf("failed to open temp file for sync: %w", err)
-	}
 	if err := file.Sync(); err != nil {
 		_ = file.Close()
 		_ = os.Remove(tempFile)
@@ -101,47 +143,120 @@ func SaveState(state *FetchState, stateFile string) error {
 	return nil
 }
 
+// backupOlderVersion copies the state file at stateFile to BackupFilePath
+// when it was written by an older version, before SaveState replaces it.
+// Missing files and files that cannot be decoded have nothing to keep.
+func backupOlderVersion(stateFile string) error {
+	data, err := os.ReadFile(stateFile) // #nosec G304 -- stateFile is built from the state directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read state file %s: %w", stateFile, err)
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if json.Unmarshal(data, &header) != nil || !migrationSupported(header.Version) {
+		return nil
+	}
+	if err := os.WriteFile(BackupFilePath(stateFile, header.Version), data, 0o600); err != nil {
+		return fmt.Errorf("failed to back up state file before migration: %w", err)
+	}
+	return nil
+}
+
 // LoadState reads and validates the fetch state from disk.
-// It verifies the checksum and version compatibility.
+// It verifies the checksum and version compatibility. A file written by an
+// older version is migrated to CurrentVersion in memory only, so loading
+// never writes; the upgrade is persisted by the next SaveState, which keeps
+// the original at BackupFilePath.
 func LoadState(stateFile string) (*FetchState, error) {
 	// Read the state file
 	data, err := os.ReadFile(stateFile)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("no previous fetch state found at %s. Use --all flag for initial fetch", stateFile)
+			return nil, fmt.Errorf("%w at %s. Use --all flag for initial fetch", ErrNoState, stateFile)
 		}
 		return nil, fmt.Errorf("failed to read state file %s: %w", stateFile, err)
 	}
 
-	// Unmarshal the state
+	state, _, err := decodeState(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// encodeState sets the version and checksum of state and returns its
+// content as stored.
+func encodeState(state *FetchState) ([]byte, error) {
+	// Set version to current
+	state.Version = CurrentVersion
+
+	// Calculate checksum before adding it to the struct
+	checksum, err := calculateChecksum(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+	state.Checksum = checksum
+
+	// Marshal state to compact JSON for efficiency
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state: %w", err)
+	}
+	return data, nil
+}
+
+// decodeState parses and validates the content of a state file. Content of
+// an older version is migrated to CurrentVersion in memory. It also returns
+// the version the content was written with.
+func decodeState(data []byte) (*FetchState, int, error) {
 	var state FetchState
 	if unmarshalErr := json.Unmarshal(data, &state); unmarshalErr != nil {
-		return nil, fmt.Errorf("state file is corrupted (invalid JSON): %w", unmarshalErr)
+		return nil, 0, fmt.Errorf("state file is corrupted (invalid JSON): %w", unmarshalErr)
 	}
 
-	// Check version compatibility
+	// Check version compatibility, upgrading content of older versions
 	if state.Version != CurrentVersion {
-		return nil, fmt.Errorf("state file version (%d) is incompatible with current version (%d)",
-			state.Version, CurrentVersion)
+		if !migrationSupported(state.Version) {
+			return nil, 0, fmt.Errorf("state file version (%d) is incompatible with current version (%d)",
+				state.Version, CurrentVersion)
+		}
+		migrated, err := migrateState(data, &state)
+		if err != nil {
+			return nil, 0, err
+		}
+		return migrated, state.Version, nil
 	}
 
-	// Verify checksum
+	if err := verifyChecksum(&state); err != nil {
+		return nil, 0, err
+	}
+
+	return &state, CurrentVersion, nil
+}
+
+// verifyChecksum checks the stored checksum of state against its content.
+func verifyChecksum(state *FetchState) error {
 	savedChecksum := state.Checksum
 	state.Checksum = "" // Clear for recalculation
 
-	calculatedChecksum, err := calculateChecksum(&state)
-	if err != nil {
-		return nil, fmt.Errorf("failed to calculate checksum for validation: %w", err)
-	}
-
-	if savedChecksum != calculatedChecksum {
-		return nil, fmt.Errorf("state file is corrupted (checksum mismatch)")
-	}
+	calculatedChecksum, err := calculateChecksum(state)
 
 	// Restore the checksum field
 	state.Checksum = savedChecksum
 
-	return &state, nil
+	if err != nil {
+		return fmt.Errorf("failed to calculate checksum for validation: %w", err)
+	}
+	if savedChecksum != calculatedChecksum {
+		return fmt.Errorf("state file is corrupted (checksum mismatch)")
+	}
+	return nil
 }
 
 // DeleteState removes the state file for a repository.
@@ -60,6 +60,48 @@ func TestGetStateFilePath(t *testing.T) {
 	}
 }
 
+func TestStateFilePath(t *testing.T) {
+	stateDir := filepath.Join(t.TempDir(), "state")
+	if got, want := StateFilePath(stateDir, "kubernetes/kubernetes"), filepath.Join(stateDir, "kubernetes-kubernetes.state"); got != want {
+		t.Errorf("StateFilePath() = %q, want %q", got, want)
+	}
+	if got, want := StateFilePath("", "kubernetes/kubernetes"), GetStateFilePath("kubernetes/kubernetes"); got != want {
+		t.Errorf("StateFilePath() with no directory = %q, want the default %q", got, want)
+	}
+}
+
+func TestListStateFiles(t *testing.T) {
+	stateDir := t.TempDir()
+	for _, repo := range []string{"org/b", "org/a"} {
+		if err := SaveState(&FetchState{Repository: repo}, StateFilePath(stateDir, repo)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Lock files, backups and directories are not state files
+	for _, name := range []string{"org-a.state.lock", "org-a.state.v1.bak"} {
+		if err := os.WriteFile(filepath.Join(stateDir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(stateDir, "dir.state"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ListStateFiles(stateDir)
+	if err != nil {
+		t.Fatalf("ListStateFiles() error = %v", err)
+	}
+	want := []string{StateFilePath(stateDir, "org/a"), StateFilePath(stateDir, "org/b")}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("ListStateFiles() = %v, want %v", files, want)
+	}
+
+	files, err = ListStateFiles(filepath.Join(stateDir, "missing"))
+	if err != nil || len(files) != 0 {
+		t.Errorf("ListStateFiles() of a missing directory = %v, %v", files, err)
+	}
+}
+
 func TestSaveAndLoadState(t *testing.T) {
 	// Create a temporary directory for test files
 	tempDir := t.TempDir()
@@ -315,3 +357,53 @@ func TestConcurrentAccess(t *testing.T) {
 		t.Error("Final state has incorrect version")
 	}
 }
+
+func TestSaveState_ConcurrentWritersLeaveNoTempFiles(t *testing.T) {
+	tempDir := testutil.CreateTempDir(t, "state-test")
+	stateFile := filepath.Join(tempDir, "concurrent.state")
+
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		go func(id int) {
+			errs <- SaveState(&FetchState{Repository: "test/repo", LastPRNumber: id}, stateFile)
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("SaveState() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "concurrent.state" {
+			t.Errorf("unexpected file left in the state directory: %s", entry.Name())
+		}
+	}
+}
+
+func TestSearchFilters_Equal(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *SearchFilters
+		want bool
+	}{
+		{"both nil", nil, nil, true},
+		{"nil equals empty", nil, &SearchFilters{}, true},
+		{"empty date field equals created", &SearchFilters{}, &SearchFilters{DateField: "created"}, true},
+		{"different qualifiers", &SearchFilters{Qualifiers: "is:merged"}, nil, false},
+		{"different date field", &SearchFilters{DateField: "merged"}, &SearchFilters{}, false},
+		{"different bot exclusion", &SearchFilters{ExcludeBots: true}, &SearchFilters{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Equal(tt.b); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
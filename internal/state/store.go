@@ -0,0 +1,178 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+)
+
+// Backends that OpenStore can open.
+const (
+	// BackendFile keeps one state file per repository and loose metadata
+	// files in the state directory.
+	BackendFile = "file"
+
+	// BackendSQLite keeps state, run history and metadata in a single
+	// SQLite database in the state directory.
+	BackendSQLite = "sqlite"
+)
+
+// StateStore persists the fetch state of repositories and the metadata of
+// their fetches. Repositories are named in "org/repo" format. Runs that
+// load, fetch and save the state of a repository hold its lock from Lock.
+type StateStore interface {
+	// LoadState returns the state of repository. The error wraps
+	// ErrNoState when there is none.
+	LoadState(repository string) (*FetchState, error)
+
+	// SaveState saves state as the state of state.Repository, setting its
+	// version and checksum.
+	SaveState(state *FetchState) error
+
+	// DeleteState removes the state of repository. Deleting state that
+	// does not exist is not an error.
+	DeleteState(repository string) error
+
+	// ListStates returns the repositories with saved state, sorted by name.
+	ListStates() ([]string, error)
+
+	// SaveMetadata records the metadata of a fetch under the repository
+	// named in its parameters. Metadata of one repository never replaces
+	// that of another, even when their fetches start in the same second.
+	SaveMetadata(metadata *metadata.FetchMetadata) error
+
+	// LoadLatestMetadata returns the metadata of the most recent fetch of
+	// repository, regardless of fetches of other repositories saved since,
+	// or nil when there is none.
+	LoadLatestMetadata(repository string) (*metadata.FetchMetadata, error)
+
+	// Lock takes the advisory lock on the state of repository, waiting up
+	// to wait for another run to release it, as AcquireLock does.
+	Lock(ctx context.Context, repository string, wait time.Duration) (*Lock, error)
+
+	// Location describes where the store keeps its data, for messages.
+	Location() string
+
+	// Close releases the resources held by the store.
+	Close() error
+}
+
+// OpenStore opens the store of the given backend in stateDir, or in
+// DefaultStateDir when stateDir is empty. An empty backend selects
+// BackendFile.
+func OpenStore(backend, stateDir string) (StateStore, error) {
+	if stateDir == "" {
+		stateDir = DefaultStateDir()
+	}
+	switch backend {
+	case "", BackendFile:
+		return NewFileStore(stateDir), nil
+	case BackendSQLite:
+		return OpenSQLiteStore(filepath.Join(stateDir, SQLiteFileName))
+	default:
+		return nil, fmt.Errorf("unknown state store %q: must be %s or %s", backend, BackendFile, BackendSQLite)
+	}
+}
+
+// FileStore is the StateStore that keeps one state file per repository,
+// named by StateFilePath, and the metadata of fetches as loose
+// fetch-metadata-{org}-{repo}-{timestamp}.json files in the same directory.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore for stateDir, or for DefaultStateDir
+// when stateDir is empty. The directory is created when state is saved.
+func NewFileStore(stateDir string) *FileStore {
+	if stateDir == "" {
+		stateDir = DefaultStateDir()
+	}
+	return &FileStore{dir: stateDir}
+}
+
+// LoadState loads the state file of repository.
+func (s *FileStore) LoadState(repository string) (*FetchState, error) {
+	return LoadState(StateFilePath(s.dir, repository))
+}
+
+// SaveState writes the state file of state.Repository.
+func (s *FileStore) SaveState(state *FetchState) error {
+	return SaveState(state, StateFilePath(s.dir, state.Repository))
+}
+
+// DeleteState removes the state file of repository.
+func (s *FileStore) DeleteState(repository string) error {
+	return DeleteState(StateFilePath(s.dir, repository))
+}
+
+// ListStates returns the repositories named in the state files of the
+// directory. A file whose repository cannot be read is listed by its name
+// without the .state suffix, which LoadState accepts as well.
+func (s *FileStore) ListStates() ([]string, error) {
+	files, err := ListStateFiles(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	repos := make([]string, 0, len(files))
+	for _, file := range files {
+		var header struct {
+			Repository string `json:"repository"`
+		}
+		data, err := os.ReadFile(file) // #nosec G304 -- file is listed from the state directory
+		if err != nil || json.Unmarshal(data, &header) != nil || StateFilePath(s.dir, header.Repository) != file {
+			header.Repository = strings.TrimSuffix(filepath.Base(file), ".state")
+		}
+		repos = append(repos, header.Repository)
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
+
+// SaveMetadata writes metadata to a fetch-metadata-*.json file named for its
+// repository.
+func (s *FileStore) SaveMetadata(m *metadata.FetchMetadata) error {
+	return metadata.SaveMetadata(m, s.dir)
+}
+
+// LoadLatestMetadata loads the newest metadata file of the directory that
+// belongs to repository.
+func (s *FileStore) LoadLatestMetadata(repository string) (*metadata.FetchMetadata, error) {
+	return metadata.LoadLatestMetadata(s.dir, repository)
+}
+
+// Lock locks the state file of repository.
+func (s *FileStore) Lock(ctx context.Context, repository string, wait time.Duration) (*Lock, error) {
+	return AcquireLock(ctx, StateFilePath(s.dir, repository), wait)
+}
+
+// Location returns the state directory.
+func (s *FileStore) Location() string {
+	return s.dir
+}
+
+// Close does nothing; files are closed after every operation.
+func (s *FileStore) Close() error {
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package state
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+)
+
+// openTestStores opens a store of every backend in its own directory.
+func openTestStores(t *testing.T) map[string]StateStore {
+	t.Helper()
+	stores := make(map[string]StateStore)
+	for _, backend := range []string{BackendFile, BackendSQLite} {
+		store, err := OpenStore(backend, t.TempDir())
+		if err != nil {
+			t.Fatalf("OpenStore(%s) error = %v", backend, err)
+		}
+		t.Cleanup(func() { _ = store.Close() })
+		stores[backend] = store
+	}
+	return stores
+}
+
+func TestStateStore_SaveLoadDelete(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		t.Run(backend, func(t *testing.T) {
+			if _, err := store.LoadState("golang/go"); !errors.Is(err, ErrNoState) {
+				t.Fatalf("LoadState() of missing state error = %v, want ErrNoState", err)
+			}
+
+			watermark := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			for _, repo := range []string{"golang/go", "facebook/react"} {
+				fetchState := &FetchState{
+					Repository:       repo,
+					LastFetchID:      "full-1",
+					LastPRNumber:     42,
+					UpdatedWatermark: &watermark,
+					LastFetchTime:    watermark.Add(time.Minute),
+					TotalFetched:     7,
+				}
+				if err := store.SaveState(fetchState); err != nil {
+					t.Fatalf("SaveState(%s) error = %v", repo, err)
+				}
+			}
+
+			loaded, err := store.LoadState("golang/go")
+			if err != nil {
+				t.Fatalf("LoadState() error = %v", err)
+			}
+			if loaded.Repository != "golang/go" || loaded.LastPRNumber != 42 || !loaded.UpdatedWatermark.Equal(watermark) {
+				t.Errorf("LoadState() = %+v, want the saved state of golang/go", loaded)
+			}
+			if loaded.Version != CurrentVersion || loaded.Checksum == "" {
+				t.Errorf("saved state has version %d and checksum %q", loaded.Version, loaded.Checksum)
+			}
+
+			repos, err := store.ListStates()
+			if err != nil {
+				t.Fatalf("ListStates() error = %v", err)
+			}
+			if len(repos) != 2 || repos[0] != "facebook/react" || repos[1] != "golang/go" {
+				t.Errorf("ListStates() = %v, want [facebook/react golang/go]", repos)
+			}
+
+			if err := store.DeleteState("golang/go"); err != nil {
+				t.Fatalf("DeleteState() error = %v", err)
+			}
+			if _, err := store.LoadState("golang/go"); !errors.Is(err, ErrNoState) {
+				t.Errorf("LoadState() after DeleteState error = %v, want ErrNoState", err)
+			}
+			if err := store.DeleteState("golang/go"); err != nil {
+				t.Errorf("DeleteState() of missing state error = %v", err)
+			}
+		})
+	}
+}
+
+func TestStateStore_Metadata(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		t.Run(backend, func(t *testing.T) {
+			if latest, err := store.LoadLatestMetadata("org/repo"); err != nil || latest != nil {
+				t.Fatalf("LoadLatestMetadata() without metadata = %v, %v", latest, err)
+			}
+
+			started := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			fetchMetadata := &metadata.FetchMetadata{
+				FetchID:    "full-1717243200",
+				Parameters: metadata.FetchParams{Organization: "org", Repository: "repo", FetchAll: true},
+				Results:    metadata.FetchResults{TotalPRs: 10, StartedAt: started, CompletedAt: started.Add(time.Minute)},
+			}
+			if err := store.SaveMetadata(fetchMetadata); err != nil {
+				t.Fatalf("SaveMetadata() error = %v", err)
+			}
+
+			latest, err := store.LoadLatestMetadata("org/repo")
+			if err != nil || latest == nil {
+				t.Fatalf("LoadLatestMetadata() = %v, %v", latest, err)
+			}
+			if latest.FetchID != fetchMetadata.FetchID || latest.Results.TotalPRs != 10 {
+				t.Errorf("LoadLatestMetadata() = %+v, want the saved metadata", latest)
+			}
+			if other, err := store.LoadLatestMetadata("org/other"); err != nil || other != nil {
+				t.Errorf("LoadLatestMetadata() of another repository = %v, %v", other, err)
+			}
+		})
+	}
+}
+
+func TestStateStore_MetadataPerRepository(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		t.Run(backend, func(t *testing.T) {
+			// Fetches of a sync starting in the same second
+			started := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			for i, repo := range []string{"api", "web"} {
+				fetchMetadata := &metadata.FetchMetadata{
+					FetchID:    "full-1717243200",
+					Parameters: metadata.FetchParams{Organization: "org", Repository: repo, FetchAll: true},
+					Results:    metadata.FetchResults{TotalPRs: i + 1, StartedAt: started, CompletedAt: started.Add(time.Duration(i+1) * time.Minute)},
+				}
+				if err := store.SaveMetadata(fetchMetadata); err != nil {
+					t.Fatalf("SaveMetadata(%s) error = %v", repo, err)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			for i, repo := range []string{"api", "web"} {
+				latest, err := store.LoadLatestMetadata("org/" + repo)
+				if err != nil || latest == nil {
+					t.Fatalf("LoadLatestMetadata(org/%s) = %v, %v", repo, latest, err)
+				}
+				if latest.Parameters.Repository != repo || latest.Results.TotalPRs != i+1 {
+					t.Errorf("LoadLatestMetadata(org/%s) = %+v, want its own metadata", repo, latest)
+				}
+			}
+		})
+	}
+}
+
+func TestStateStore_Lock(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		t.Run(backend, func(t *testing.T) {
+			lock, err := store.Lock(context.Background(), "org/repo", 0)
+			if err != nil {
+				t.Fatalf("Lock() error = %v", err)
+			}
+			if _, err := store.Lock(context.Background(), "org/repo", 0); !errors.Is(err, ErrLocked) {
+				t.Errorf("Lock() while held error = %v, want ErrLocked", err)
+			}
+			other, err := store.Lock(context.Background(), "org/other", 0)
+			if err != nil {
+				t.Errorf("Lock() of another repository error = %v", err)
+			} else {
+				_ = other.Unlock()
+			}
+			_ = lock.Unlock()
+		})
+	}
+}
+
+func TestOpenStore_UnknownBackend(t *testing.T) {
+	if _, err := OpenStore("postgres", t.TempDir()); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
@@ -15,12 +15,20 @@
 package state
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
 // CurrentVersion is the current state schema version.
-// Increment this when making breaking changes to the FetchState structure.
-const CurrentVersion = 1
+// Increment this when making breaking changes to the FetchState structure,
+// and register a migration from the previous version in migrations.
+//
+// Versions:
+//   - 1: updated_watermark may be missing from a completed fetch, in which
+//     case incremental fetches start from last_pr_date
+//   - 2: every completed fetch records updated_watermark
+const CurrentVersion = 2
 
 // FetchState represents the persistent state of a repository fetch operation.
 // It tracks the progress of fetching pull requests to enable incremental updates.
@@ -51,6 +59,12 @@ type FetchState struct {
 	// Used as the starting point for incremental fetches.
 	LastPRDate time.Time `json:"last_pr_date"`
 
+	// UpdatedWatermark is the update time from which changes have not been
+	// captured yet. Incremental fetches re-emit every PR updated at or after
+	// it, then move it to the start of that fetch. Nil until the first fetch
+	// completes; version 1 files are migrated with last_pr_date.
+	UpdatedWatermark *time.Time `json:"updated_watermark,omitempty"`
+
 	// LastFetchTime records when the fetch operation completed successfully.
 	// Useful for debugging and monitoring.
 	LastFetchTime time.Time `json:"last_fetch_time"`
@@ -58,4 +72,120 @@ type FetchState struct {
 	// TotalFetched is the total number of PRs fetched in the last operation.
 	// Provides insight into fetch size and performance.
 	TotalFetched int `json:"total_fetched"`
+
+	// Filters are the search filters the fetch was run with. Incremental
+	// fetches must use the same ones. Nil when no filters were used.
+	Filters *SearchFilters `json:"filters,omitempty"`
+
+	// Checkpoint records the progress of a full fetch that has not finished
+	// yet. It is written after every page and cleared once the fetch
+	// completes, so a crashed or interrupted run can be continued with
+	// --resume. Nil when no fetch is in progress.
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty"`
+}
+
+// Checkpoint is the resumable position of an in-progress full fetch.
+// Everything needed to continue the run is captured here: the search cursor,
+// the time window and page size the run was started with, and the output
+// file position that corresponds to the cursor.
+type Checkpoint struct {
+	// FetchID identifies the run that wrote the checkpoint.
+	FetchID string `json:"fetch_id"`
+
+	// Cursor is the end cursor of the last page fully written to the output.
+	// An empty cursor means no page has completed yet.
+	Cursor string `json:"cursor"`
+
+	// Since and Until are the time window of the original run.
+	Since *time.Time `json:"since,omitempty"`
+	Until *time.Time `json:"until,omitempty"`
+
+	// Filters are the search filters of the original run, reused on resume.
+	Filters *SearchFilters `json:"filters,omitempty"`
+
+	// PageSize is the page size in use when the checkpoint was written,
+	// including any reduction made after query complexity errors.
+	PageSize int `json:"page_size"`
+
+	// SliceStart and SliceEnd are the created-date range being fetched when
+	// the window was split below the search result cap; Cursor is relative
+	// to that slice. SlicingEnd is the exclusive end of the range being
+	// sliced. All three are nil for runs that were not split.
+	SliceStart *time.Time `json:"slice_start,omitempty"`
+	SliceEnd   *time.Time `json:"slice_end,omitempty"`
+	SlicingEnd *time.Time `json:"slicing_end,omitempty"`
+
+	// ExpectedPRs is the number of PRs the run expects to fetch, used to
+	// verify the final count. Zero when unknown.
+	ExpectedPRs int `json:"expected_prs,omitempty"`
+
+	// PageNum is the number of pages completed so far.
+	PageNum int `json:"page_num"`
+
+	// OutputFile is the absolute path of the file the run writes to. Empty
+	// when writing to stdout.
+	OutputFile string `json:"output_file,omitempty"`
+
+	// OutputOffset is the size in bytes of OutputFile once every record up to
+	// Cursor was flushed. Anything past it was written after the checkpoint
+	// and is discarded on resume so no record is emitted twice.
+	OutputOffset int64 `json:"output_offset"`
+
+	// PRsWritten is the number of PRs written up to Cursor.
+	PRsWritten int `json:"prs_written"`
+
+	// LastPRNumber and LastPRDate track the newest PR written so far.
+	LastPRNumber int       `json:"last_pr_number"`
+	LastPRDate   time.Time `json:"last_pr_date"`
+
+	// StartedAt is when the original run started; UpdatedAt is when the
+	// checkpoint was last written.
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SearchFilters are the filters that narrow which pull requests a fetch
+// selects: extra search qualifiers, the date field its time window applies
+// to and whether bot PRs are left out.
+type SearchFilters struct {
+	Qualifiers  string `json:"qualifiers,omitempty"`
+	DateField   string `json:"date_field,omitempty"`
+	ExcludeBots bool   `json:"exclude_bots,omitempty"`
+}
+
+// Equal reports whether f and other select the same pull requests. Nil
+// filters equal empty ones, and an empty date field equals created.
+func (f *SearchFilters) Equal(other *SearchFilters) bool {
+	return f.normalized() == other.normalized()
+}
+
+// String describes the filters for error messages.
+func (f *SearchFilters) String() string {
+	n := f.normalized()
+	var parts []string
+	if n.Qualifiers != "" {
+		parts = append(parts, fmt.Sprintf("qualifiers %q", n.Qualifiers))
+	}
+	if n.DateField != "created" {
+		parts = append(parts, "date field "+n.DateField)
+	}
+	if n.ExcludeBots {
+		parts = append(parts, "bots excluded")
+	}
+	if len(parts) == 0 {
+		return "no filters"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// normalized returns a copy of f with defaults filled in.
+func (f *SearchFilters) normalized() SearchFilters {
+	var n SearchFilters
+	if f != nil {
+		n = *f
+	}
+	if n.DateField == "" {
+		n.DateField = "created"
+	}
+	return n
 }
@@ -399,3 +399,64 @@ defaults:
 		t.Errorf("State file not created: %s", stateFile)
 	}
 }
+
+func TestConfigFile_GraphQLEndpoint(t *testing.T) {
+	server := testutil.NewSearchServer(t, 25)
+	defer server.Close()
+
+	tmpDir := testutil.CreateTempDir(t, "config-test")
+	configFile := testutil.WriteEndpointConfig(t, tmpDir, server.URL+"/graphql")
+	env := map[string]string{
+		"GITHUB_TOKEN": "test-token",
+		"HOME":         tmpDir, // Keep state files inside the test directory
+	}
+
+	// Full fetch goes through repository info and paginated search
+	outputFile := filepath.Join(tmpDir, "all.ndjson")
+	result := testutil.RunCLI(t, []string{"fetch", "test/repo", "--all",
+		"--output", outputFile,
+		"--metadata-file", filepath.Join(tmpDir, "all-metadata.json"),
+		"--config", configFile}, env)
+	testutil.AssertCLISuccess(t, result)
+	verifyNDJSONOutput(t, outputFile, 25)
+
+	requestsAfterFull := server.Requests()
+	if requestsAfterFull < 2 {
+		t.Fatalf("Expected the configured endpoint to receive requests, got %d", requestsAfterFull)
+	}
+
+	// Incremental fetch must use the same configured endpoint
+	incrementalFile := filepath.Join(tmpDir, "incremental.ndjson")
+	result = testutil.RunCLI(t, []string{"fetch", "test/repo", "--incremental",
+		"--output", incrementalFile,
+		"--metadata-file", filepath.Join(tmpDir, "inc-metadata.json"),
+		"--config", configFile}, env)
+	testutil.AssertCLISuccess(t, result)
+
+	if server.Requests() <= requestsAfterFull {
+		t.Error("Expected incremental fetch to query the configured endpoint")
+	}
+}
+
+func TestEnv_GraphQLEndpoint(t *testing.T) {
+	server := testutil.NewSearchServer(t, 3)
+	defer server.Close()
+
+	tmpDir := testutil.CreateTempDir(t, "config-test")
+	outputFile := filepath.Join(tmpDir, "test.ndjson")
+
+	result := testutil.RunCLI(t, []string{"fetch", "test/repo",
+		"--output", outputFile,
+		"--metadata-file", filepath.Join(tmpDir, "metadata.json")},
+		map[string]string{
+			"GITHUB_TOKEN":            "test-token",
+			"GITHUB_GRAPHQL_ENDPOINT": server.URL + "/graphql",
+			"HOME":                    tmpDir,
+		})
+	testutil.AssertCLISuccess(t, result)
+	verifyNDJSONOutput(t, outputFile, 3)
+
+	if server.Requests() == 0 {
+		t.Error("Expected GITHUB_GRAPHQL_ENDPOINT to receive requests")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 SirSeer, LLC
+//
+// Licensed under the Business Source License 1.1 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://mariadb.com/bsl11
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirseerhq/sirseer-relay/internal/metadata"
+	"github.com/sirseerhq/sirseer-relay/test/testutil"
+)
+
+// writeRetryConfig writes a config file pointing at endpoint with short
+// retry delays so tests don't wait on the default backoff.
+func writeRetryConfig(t *testing.T, dir, endpoint string, maxAttempts int) string {
+	t.Helper()
+	path := filepath.Join(dir, "sirseer-relay.yaml")
+	content := fmt.Sprintf("github:\n  graphql_endpoint: %s\nretry:\n  max_attempts: %d\n  base_delay: 10ms\n  max_delay: 50ms\n",
+		endpoint, maxAttempts)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestTransientErrors_Retried(t *testing.T) {
+	tests := []struct {
+		name      string
+		failCount int
+		errorCode int
+	}{
+		{name: "bad gateway", failCount: 2, errorCode: http.StatusBadGateway},
+		{name: "service unavailable", failCount: 3, errorCode: http.StatusServiceUnavailable},
+		{name: "gateway timeout", failCount: 1, errorCode: http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testutil.NewTransientErrorServer(t, tt.failCount, tt.errorCode)
+			defer server.Close()
+
+			tmpDir := testutil.CreateTempDir(t, "retry-test")
+			outputFile := filepath.Join(tmpDir, "prs.ndjson")
+			metadataFile := filepath.Join(tmpDir, "metadata.json")
+			configFile := writeRetryConfig(t, tmpDir, server.URL+"/graphql", 5)
+
+			result := testutil.RunCLI(t, []string{"fetch", "test/repo", "--all",
+				"--output", outputFile,
+				"--metadata-file", metadataFile,
+				"--config", configFile},
+				map[string]string{"GITHUB_TOKEN": "test-token", "HOME": tmpDir})
+			testutil.AssertCLISuccess(t, result)
+			verifyNDJSONOutput(t, outputFile, 10)
+
+			if got := strings.Count(result.Stderr, "Retrying"); got != tt.failCount {
+				t.Errorf("Expected %d retry messages, got %d\nStderr: %s", tt.failCount, got, result.Stderr)
+			}
+
+			data, err := os.ReadFile(metadataFile)
+			if err != nil {
+				t.Fatalf("Failed to read metadata file: %v", err)
+			}
+			var fetchMetadata metadata.FetchMetadata
+			if err := json.Unmarshal(data, &fetchMetadata); err != nil {
+				t.Fatalf("Failed to parse metadata: %v", err)
+			}
+			if fetchMetadata.Results.Retries != tt.failCount {
+				t.Errorf("Expected %d retries in metadata, got %d", tt.failCount, fetchMetadata.Results.Retries)
+			}
+		})
+	}
+}
+
+func TestTransientErrors_GiveUpAfterMaxAttempts(t *testing.T) {
+	server := testutil.NewTransientErrorServer(t, 100, http.StatusBadGateway)
+	defer server.Close()
+
+	tmpDir := testutil.CreateTempDir(t, "retry-test")
+	configFile := writeRetryConfig(t, tmpDir, server.URL+"/graphql", 3)
+
+	result := testutil.RunCLI(t, []string{"fetch", "test/repo", "--all",
+		"--output", filepath.Join(tmpDir, "prs.ndjson"),
+		"--metadata-file", filepath.Join(tmpDir, "metadata.json"),
+		"--config", configFile},
+		map[string]string{"GITHUB_TOKEN": "test-token", "HOME": tmpDir})
+
+	if result.ExitCode == 0 {
+		t.Fatal("Expected the fetch to fail once retries are exhausted")
+	}
+	if got := server.Requests(); got != 3 {
+		t.Errorf("Expected 3 attempts, server received %d requests", got)
+	}
+}
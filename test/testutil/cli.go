@@ -16,6 +16,7 @@ package testutil
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -145,15 +146,32 @@ func RunWithMockServer(t *testing.T, server *MockServer, repo string, args ...st
 	fullArgs := []string{"fetch", repo}
 	fullArgs = append(fullArgs, args...)
 
+	// Point the CLI at the mock server through a config file
+	configFile := WriteEndpointConfig(t, t.TempDir(), server.URL+"/graphql")
+	fullArgs = append(fullArgs, "--config", configFile)
+
 	// Set up environment with test token
 	env := map[string]string{
-		"GITHUB_TOKEN":    "test-token",
-		"SIRSEER_API_URL": server.URL + "/graphql",
+		"GITHUB_TOKEN": "test-token",
 	}
 
 	return RunCLI(t, fullArgs, env)
 }
 
+// WriteEndpointConfig writes a config file in dir that directs GitHub API
+// traffic to the given GraphQL endpoint and returns its path.
+func WriteEndpointConfig(t *testing.T, dir, graphqlEndpoint string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "sirseer-relay.yaml")
+	content := fmt.Sprintf("github:\n  graphql_endpoint: %s\n", graphqlEndpoint)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	return path
+}
+
 // findProjectRoot finds the project root by looking for go.mod
 func findProjectRoot() (string, error) {
 	dir, err := os.Getwd()
@@ -173,4 +191,3 @@ func findProjectRoot() (string, error) {
 		dir = parent
 	}
 }
-
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -73,13 +74,17 @@ func NewErrorServer(t *testing.T, statusCode int) *MockServer {
 	return &MockServer{Server: server}
 }
 
-// NewTransientErrorServer creates a mock server that fails N times then succeeds
+// NewTransientErrorServer creates a mock server that fails the first
+// failCount requests with errorCode, then serves 10 pull requests through
+// the search API like NewSearchServer. Every request is counted in
+// RequestCount.
 func NewTransientErrorServer(t *testing.T, failCount, errorCode int) *MockServer {
 	t.Helper()
-	var requestCount int32
+	mock := &MockServer{}
+	serve := searchHandler(10)
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		count := atomic.AddInt32(&requestCount, 1)
+	mock.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := atomic.AddInt32(&mock.RequestCount, 1)
 
 		if count <= int32(failCount) {
 			w.WriteHeader(errorCode)
@@ -88,12 +93,10 @@ func NewTransientErrorServer(t *testing.T, failCount, errorCode int) *MockServer
 		}
 
 		// Success after failures
-		response := GeneratePRResponse(1, 10, false)
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(response)
+		serve(w, r)
 	}))
 
-	return &MockServer{Server: server, RequestCount: requestCount}
+	return mock
 }
 
 // NewTimeoutServer creates a mock server that times out N times then succeeds
@@ -156,6 +159,124 @@ func GeneratePRResponse(startNum, endNum int, hasMore bool) map[string]interface
 	}
 }
 
+// GenerateSearchResponse generates a mock GraphQL search response with PRs
+// numbered startNum through endNum, in the shape FetchPullRequestsSearch expects.
+// PRs are created one hour apart starting at 2024-01-01 so output is deterministic.
+func GenerateSearchResponse(startNum, endNum int, hasMore bool) map[string]interface{} {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	nodes := make([]map[string]interface{}, 0)
+
+	for i := startNum; i <= endNum; i++ {
+		created := base.Add(time.Duration(i) * time.Hour)
+		nodes = append(nodes, map[string]interface{}{
+			"number":    i,
+			"title":     fmt.Sprintf("PR %d", i),
+			"state":     "OPEN",
+			"url":       fmt.Sprintf("https://github.example.com/test/repo/pull/%d", i),
+			"createdAt": created.Format(time.RFC3339),
+			"updatedAt": created.Format(time.RFC3339),
+			"author": map[string]interface{}{
+				"login": fmt.Sprintf("user%d", i),
+			},
+		})
+	}
+
+	cursor := ""
+	if hasMore {
+		cursor = fmt.Sprintf("cursor%d", endNum)
+	}
+
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"search": map[string]interface{}{
+				"nodes": nodes,
+				"pageInfo": map[string]interface{}{
+					"hasNextPage": hasMore,
+					"endCursor":   cursor,
+				},
+			},
+		},
+	}
+}
+
+// GraphQLRequest is the decoded body of a GraphQL request sent by the client.
+type GraphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// DecodeGraphQLRequest decodes the GraphQL query and variables from a request body.
+func DecodeGraphQLRequest(r *http.Request) (GraphQLRequest, error) {
+	var req GraphQLRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// NewSearchServer creates a mock GraphQL server that serves totalPRs pull
+// requests through the search API, honoring the page size and cursor sent by
+// the client. Repository info queries report totalPRs as the total count.
+// Every request is counted in RequestCount.
+func NewSearchServer(t *testing.T, totalPRs int) *MockServer {
+	t.Helper()
+	mock := &MockServer{}
+	serve := searchHandler(totalPRs)
+
+	mock.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&mock.RequestCount, 1)
+		serve(w, r)
+	}))
+
+	return mock
+}
+
+// searchHandler serves totalPRs pull requests through the search API and
+// answers repository info queries with totalPRs as the total count.
+func searchHandler(totalPRs int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := DecodeGraphQLRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var response map[string]interface{}
+		if !strings.Contains(req.Query, "search(") {
+			response = map[string]interface{}{
+				"data": map[string]interface{}{
+					"repository": map[string]interface{}{
+						"pullRequests": map[string]interface{}{"totalCount": totalPRs},
+					},
+				},
+			}
+		} else {
+			start := 1
+			if after, ok := req.Variables["after"].(string); ok && after != "" {
+				var last int
+				if _, scanErr := fmt.Sscanf(after, "cursor%d", &last); scanErr == nil {
+					start = last + 1
+				}
+			}
+			pageSize := 10
+			if first, ok := req.Variables["first"].(float64); ok && first > 0 {
+				pageSize = int(first)
+			}
+			end := start + pageSize - 1
+			if end > totalPRs {
+				end = totalPRs
+			}
+			response = GenerateSearchResponse(start, end, end < totalPRs)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}
+}
+
+// Requests returns the number of requests the server has received so far.
+func (m *MockServer) Requests() int {
+	return int(atomic.LoadInt32(&m.RequestCount))
+}
+
 // AssertGraphQLRequest validates a GraphQL request structure
 func AssertGraphQLRequest(t *testing.T, r *http.Request) {
 	t.Helper()
@@ -169,4 +290,3 @@ func AssertGraphQLRequest(t *testing.T, r *http.Request) {
 		t.Errorf("Expected Content-Type: application/json, got: %s", ct)
 	}
 }
-